@@ -1,62 +1,235 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"image"
 	_ "image/jpeg"
 	_ "image/png"
+	"io"
 	"os"
 
 	"github.com/mac/go-pixo/src/png"
 )
 
 func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the CLI with args (excluding the program name) and returns the
+// process exit code. An input or output of "-" means stdin or stdout; when the
+// PNG goes to stdout, informational messages are written to stderr instead.
+// With -json, the text messages are replaced by one JSON object (a cliResult or
+// cliError) on stdout, following the PNG when that is written there too.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("go-pixo", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	if jsonRequested(args) {
+		flags.SetOutput(io.Discard)
+	}
 	var (
-		inputFile  = flag.String("input", "", "Input image file (PNG or JPEG)")
-		outputFile = flag.String("output", "", "Output PNG file (default: input with .png extension)")
+		inputFile  = flags.String("input", "", "Input image file (PNG or JPEG), or - for stdin")
+		outputFile = flags.String("output", "", "Output PNG file, or - for stdout (default: input with .png extension, stdout for stdin input)")
+		optimize   = flags.Bool("optimize", false, "Re-encode PNG input and keep whichever of original and re-encoded is smaller")
+		preset     = flags.String("preset", "fast", "Encoding preset: fast, balanced, or max")
+		jsonOut    = flags.Bool("json", false, "Print a single JSON result (or {\"error\": ...}) instead of text")
 	)
-	flag.Parse()
+	if err := flags.Parse(args); err != nil {
+		if jsonRequested(args) {
+			return emitJSON(stdout, stderr, cliError{Error: err.Error()}, 2)
+		}
+		return 2
+	}
 
 	if *inputFile == "" {
-		fmt.Fprintf(os.Stderr, "Error: -input is required\n")
-		flag.Usage()
-		os.Exit(1)
+		if *jsonOut {
+			return emitJSON(stdout, stderr, cliError{Error: "-input is required"}, 1)
+		}
+		fmt.Fprintf(stderr, "Error: -input is required\n")
+		flags.Usage()
+		return 1
 	}
 
 	if *outputFile == "" {
-		*outputFile = (*inputFile)[:len(*inputFile)-len(getExt(*inputFile))] + ".png"
+		if *inputFile == "-" {
+			*outputFile = "-"
+		} else {
+			*outputFile = (*inputFile)[:len(*inputFile)-len(getExt(*inputFile))] + ".png"
+		}
 	}
 
-	file, err := os.Open(*inputFile)
+	info := stdout
+	if *outputFile == "-" {
+		info = stderr
+	}
+	logf := func(format string, a ...any) {
+		if !*jsonOut {
+			fmt.Fprintf(info, format, a...)
+		}
+	}
+	fail := func(action string, err error) int {
+		if *jsonOut {
+			return emitJSON(stdout, stderr, cliError{Error: fmt.Sprintf("%s: %v", action, err)}, 1)
+		}
+		fmt.Fprintf(stderr, "Error %s: %v\n", action, err)
+		return 1
+	}
+
+	var input []byte
+	var err error
+	if *inputFile == "-" {
+		input, err = io.ReadAll(stdin)
+	} else {
+		input, err = os.ReadFile(*inputFile)
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error opening input file: %v\n", err)
-		os.Exit(1)
+		return fail("opening input file", err)
 	}
-	defer file.Close()
 
-	img, format, err := image.Decode(file)
+	img, format, err := image.Decode(bytes.NewReader(input))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error decoding image: %v\n", err)
-		os.Exit(1)
+		return fail("decoding image", err)
 	}
 
-	fmt.Printf("Decoded %s image: %dx%d\n", format, img.Bounds().Dx(), img.Bounds().Dy())
+	logf("Decoded %s image: %dx%d\n", format, img.Bounds().Dx(), img.Bounds().Dy())
+
+	pngData, err := encodeImage(img, *preset)
+	if err != nil {
+		return fail("encoding PNG", err)
+	}
+
+	if *optimize && format == "png" {
+		var keptOriginal bool
+		reencodedSize := len(pngData)
+		pngData, keptOriginal = smallerPNG(input, pngData)
+		if keptOriginal {
+			logf("Kept original: re-encoded was %d bytes (+%d)\n", reencodedSize, reencodedSize-len(input))
+		} else {
+			logf("Re-encoded: %d -> %d bytes (-%d)\n", len(input), len(pngData), len(input)-len(pngData))
+		}
+	}
+
+	if *outputFile == "-" {
+		if _, err := stdout.Write(pngData); err != nil {
+			return fail("writing output", err)
+		}
+	} else if err := os.WriteFile(*outputFile, pngData, 0o644); err != nil {
+		return fail("writing output file", err)
+	}
+
+	logf("Successfully compressed to %s (%d bytes)\n", *outputFile, len(pngData))
+	if *jsonOut {
+		return emitJSON(stdout, stderr, cliResult{
+			Input:  *inputFile,
+			Output: *outputFile,
+			Width:  img.Bounds().Dx(),
+			Height: img.Bounds().Dy(),
+			Format: format,
+			Bytes:  len(pngData),
+			Ratio:  float64(len(pngData)) / float64(len(input)),
+		}, 0)
+	}
+	return 0
+}
+
+// cliResult is the -json output for a successful run. Ratio is output bytes
+// divided by input bytes.
+type cliResult struct {
+	Input  string  `json:"input"`
+	Output string  `json:"output"`
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	Format string  `json:"format"`
+	Bytes  int     `json:"bytes"`
+	Ratio  float64 `json:"ratio"`
+}
+
+// cliError is the -json output for a failed run.
+type cliError struct {
+	Error string `json:"error"`
+}
+
+// emitJSON writes v as a single line of JSON to w and returns code, or reports
+// the write failure on stderr and returns 1.
+func emitJSON(w, stderr io.Writer, v any, code int) int {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		fmt.Fprintf(stderr, "Error writing JSON: %v\n", err)
+		return 1
+	}
+	return code
+}
 
+// jsonRequested reports whether args turn on -json. It scans the raw arguments
+// so a flag parse failure, which stops before -json may be reached, can still
+// be reported as JSON.
+func jsonRequested(args []string) bool {
+	for _, arg := range args {
+		switch arg {
+		case "-json", "--json", "-json=true", "--json=true":
+			return true
+		case "--":
+			return false
+		}
+	}
+	return false
+}
+
+// encodeImage converts img to RGBA pixels and encodes them with the named preset.
+func encodeImage(img image.Image, preset string) ([]byte, error) {
+	pixels, colorType := imagePixels(img)
+	bounds := img.Bounds()
+
+	opts, err := presetOptions(preset, bounds.Dx(), bounds.Dy())
+	if err != nil {
+		return nil, err
+	}
+	opts.ColorType = colorType
+
+	encoder, err := png.NewEncoderWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return encoder.Encode(pixels)
+}
+
+// presetOptions returns the options for a preset name.
+func presetOptions(name string, width, height int) (png.Options, error) {
+	switch name {
+	case "fast":
+		return png.FastOptions(width, height), nil
+	case "balanced":
+		return png.BalancedOptions(width, height), nil
+	case "max":
+		return png.MaxOptions(width, height), nil
+	default:
+		return png.Options{}, fmt.Errorf("unknown preset %q (want fast, balanced, or max)", name)
+	}
+}
+
+// smallerPNG returns whichever of original and reencoded is smaller, preferring
+// original on a tie, and whether original was kept.
+func smallerPNG(original, reencoded []byte) ([]byte, bool) {
+	if len(reencoded) < len(original) {
+		return reencoded, false
+	}
+	return original, true
+}
+
+// imagePixels returns img's pixels as 8-bit RGBA.
+func imagePixels(img image.Image) ([]byte, png.ColorType) {
 	bounds := img.Bounds()
 	width := bounds.Dx()
 	height := bounds.Dy()
 
-	var colorType png.ColorType
 	var pixels []byte
 
 	switch img.(type) {
 	case *image.RGBA:
-		colorType = png.ColorRGBA
 		rgba := img.(*image.RGBA)
 		pixels = rgba.Pix
 	case *image.NRGBA:
-		colorType = png.ColorRGBA
 		nrgba := img.(*image.NRGBA)
 		pixels = make([]byte, width*height*4)
 		for i := 0; i < len(nrgba.Pix); i += 4 {
@@ -72,36 +245,10 @@ func main() {
 				rgba.Set(x, y, img.At(x, y))
 			}
 		}
-		colorType = png.ColorRGBA
 		pixels = rgba.Pix
 	}
 
-	encoder, err := png.NewEncoder(width, height, colorType)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating encoder: %v\n", err)
-		os.Exit(1)
-	}
-
-	pngData, err := encoder.Encode(pixels)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error encoding PNG: %v\n", err)
-		os.Exit(1)
-	}
-
-	outFile, err := os.Create(*outputFile)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating output file: %v\n", err)
-		os.Exit(1)
-	}
-	defer outFile.Close()
-
-	_, err = outFile.Write(pngData)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error writing output file: %v\n", err)
-		os.Exit(1)
-	}
-
-	fmt.Printf("Successfully compressed to %s (%d bytes)\n", *outputFile, len(pngData))
+	return pixels, png.ColorRGBA
 }
 
 func getExt(filename string) string {
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	stdpng "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSmallerPNGNeverGrowsTinyPNG(t *testing.T) {
+	tests := []struct {
+		name string
+		img  image.Image
+	}{
+		{"1x1 gray", image.NewGray(image.Rect(0, 0, 1, 1))},
+		{"8x8 paletted", image.NewPaletted(image.Rect(0, 0, 8, 8), color.Palette{color.Black, color.White})},
+		{"4x4 transparent", image.NewNRGBA(image.Rect(0, 0, 4, 4))},
+	}
+
+	for _, tt := range tests {
+		for _, preset := range []string{"fast", "balanced", "max"} {
+			t.Run(tt.name+"/"+preset, func(t *testing.T) {
+				var buf bytes.Buffer
+				enc := stdpng.Encoder{CompressionLevel: stdpng.BestCompression}
+				if err := enc.Encode(&buf, tt.img); err != nil {
+					t.Fatalf("image/png Encode() error = %v", err)
+				}
+				original := buf.Bytes()
+
+				img, err := stdpng.Decode(bytes.NewReader(original))
+				if err != nil {
+					t.Fatalf("image/png Decode() error = %v", err)
+				}
+				reencoded, err := encodeImage(img, preset)
+				if err != nil {
+					t.Fatalf("encodeImage() error = %v", err)
+				}
+
+				got, keptOriginal := smallerPNG(original, reencoded)
+				if len(got) > len(original) {
+					t.Errorf("optimized size = %d, want <= original %d", len(got), len(original))
+				}
+				if keptOriginal != (len(reencoded) >= len(original)) {
+					t.Errorf("keptOriginal = %v with original %d and re-encoded %d bytes", keptOriginal, len(original), len(reencoded))
+				}
+			})
+		}
+	}
+}
+
+func TestPresetOptionsUnknown(t *testing.T) {
+	if _, err := presetOptions("ultra", 1, 1); err == nil {
+		t.Error("presetOptions(\"ultra\") error = nil, want error")
+	}
+}
+
+func TestRunStdinToStdout(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	for i := range src.Pix {
+		src.Pix[i] = uint8(i * 11)
+	}
+	var input bytes.Buffer
+	if err := stdpng.Encode(&input, src); err != nil {
+		t.Fatalf("image/png Encode() error = %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-input", "-", "-output", "-"}, &input, &stdout, &stderr); code != 0 {
+		t.Fatalf("run() = %d, want 0; stderr: %s", code, stderr.String())
+	}
+
+	img, err := stdpng.Decode(bytes.NewReader(stdout.Bytes()))
+	if err != nil {
+		t.Fatalf("stdout is not a PNG: %v", err)
+	}
+	if got := img.Bounds(); got.Dx() != 3 || got.Dy() != 2 {
+		t.Errorf("decoded bounds = %v, want 3x2", got)
+	}
+	if !bytes.Contains(stderr.Bytes(), []byte("Decoded png image: 3x2")) {
+		t.Errorf("stderr = %q, want informational messages", stderr.String())
+	}
+}
+
+func TestRunStdinDefaultsToStdout(t *testing.T) {
+	var input bytes.Buffer
+	if err := stdpng.Encode(&input, image.NewGray(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatalf("image/png Encode() error = %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-input", "-"}, &input, &stdout, &stderr); code != 0 {
+		t.Fatalf("run() = %d, want 0; stderr: %s", code, stderr.String())
+	}
+	if _, err := stdpng.Decode(bytes.NewReader(stdout.Bytes())); err != nil {
+		t.Errorf("stdout is not a PNG: %v", err)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	var validPNG bytes.Buffer
+	if err := stdpng.Encode(&validPNG, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatalf("image/png Encode() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		args  []string
+		stdin string
+	}{
+		{"missing input", nil, ""},
+		{"undecodable stdin", []string{"-input", "-", "-output", "-"}, "not an image"},
+		{"unknown preset", []string{"-input", "-", "-preset", "ultra"}, validPNG.String()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, bytes.NewReader([]byte(tt.stdin)), &stdout, &stderr); code == 0 {
+				t.Error("run() = 0, want non-zero exit code")
+			}
+			if stdout.Len() != 0 {
+				t.Errorf("stdout = %q, want nothing on error", stdout.String())
+			}
+		})
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "in.png")
+	outputPath := filepath.Join(dir, "out.png")
+
+	var input bytes.Buffer
+	if err := stdpng.Encode(&input, image.NewNRGBA(image.Rect(0, 0, 5, 4))); err != nil {
+		t.Fatalf("image/png Encode() error = %v", err)
+	}
+	if err := os.WriteFile(inputPath, input.Bytes(), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-json", "-input", inputPath, "-output", outputPath}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("run() = %d, want 0; stdout: %s", code, stdout.String())
+	}
+
+	var got cliResult
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("stdout is not a single JSON object: %v\n%s", err, stdout.String())
+	}
+	output, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	want := cliResult{
+		Input:  inputPath,
+		Output: outputPath,
+		Width:  5,
+		Height: 4,
+		Format: "png",
+		Bytes:  len(output),
+		Ratio:  float64(len(output)) / float64(input.Len()),
+	}
+	if got != want {
+		t.Errorf("JSON result = %+v, want %+v", got, want)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("stderr = %q, want nothing", stderr.String())
+	}
+}
+
+func TestRunJSONError(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		stdin    string
+		wantCode int
+	}{
+		{"missing input file", []string{"-json", "-input", filepath.Join(t.TempDir(), "missing.png")}, "", 1},
+		{"missing -input", []string{"-json"}, "", 1},
+		{"unknown flag after -json", []string{"-json", "-bogus"}, "", 2},
+		{"unknown flag before -json", []string{"-bogus", "-json"}, "", 2},
+		{"undecodable stdin to stdout", []string{"-json", "-input", "-", "-output", "-"}, "not an image", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr); code != tt.wantCode {
+				t.Errorf("run() = %d, want %d", code, tt.wantCode)
+			}
+
+			var got cliError
+			if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+				t.Fatalf("stdout is not a JSON error: %v\n%s", err, stdout.String())
+			}
+			if got.Error == "" {
+				t.Error("JSON error message is empty")
+			}
+			if stderr.Len() != 0 {
+				t.Errorf("stderr = %q, want nothing", stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunJSONToStdout(t *testing.T) {
+	var input bytes.Buffer
+	if err := stdpng.Encode(&input, image.NewNRGBA(image.Rect(0, 0, 3, 2))); err != nil {
+		t.Fatalf("image/png Encode() error = %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-json", "-input", "-", "-output", "-"}, &input, &stdout, &stderr); code != 0 {
+		t.Fatalf("run() = %d, want 0; stderr: %s", code, stderr.String())
+	}
+
+	// The PNG comes first, followed by the JSON result on its own line.
+	at := bytes.LastIndex(stdout.Bytes(), []byte(`{"input":`))
+	if at < 0 {
+		t.Fatalf("stdout has no JSON result: %q", stdout.String())
+	}
+	var got cliResult
+	if err := json.Unmarshal(stdout.Bytes()[at:], &got); err != nil {
+		t.Fatalf("JSON result: %v\n%s", err, stdout.Bytes()[at:])
+	}
+	if got.Bytes != at {
+		t.Errorf("JSON bytes = %d, want the %d PNG bytes before it", got.Bytes, at)
+	}
+	if _, err := stdpng.Decode(bytes.NewReader(stdout.Bytes()[:at])); err != nil {
+		t.Errorf("PNG before the JSON does not decode: %v", err)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("stderr = %q, want nothing", stderr.String())
+	}
+}
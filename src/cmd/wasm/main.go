@@ -10,11 +10,13 @@ import (
 
 func main() {
 	c := make(chan struct{}, 0)
-	
+
 	// Register functions
 	js.Global().Set("encodePng", js.FuncOf(wasm.HandleEncodePng))
+	js.Global().Set("encodePngWithOptions", js.FuncOf(wasm.HandleEncodePngWithOptions))
 	js.Global().Set("bytesPerPixel", js.FuncOf(wasm.HandleBytesPerPixel))
-	
+	js.Global().Set("estimatePngSize", js.FuncOf(wasm.HandleEstimateSize))
+
 	// Signal that the WASM is ready
 	if initFunc := js.Global().Get("goWasmInit"); initFunc.Truthy() {
 		initFunc.Invoke()
@@ -8,6 +8,7 @@ type BitWriter struct {
 	w     io.Writer
 	buf   byte
 	nbits int
+	total int64
 }
 
 // NewBitWriter creates a new BitWriter that writes to w.
@@ -15,6 +16,15 @@ func NewBitWriter(w io.Writer) *BitWriter {
 	return &BitWriter{w: w}
 }
 
+// Reset discards any buffered bits, clears the bit count, and retargets the writer to w,
+// so one BitWriter can be reused across independent streams.
+func (bw *BitWriter) Reset(w io.Writer) {
+	bw.w = w
+	bw.buf = 0
+	bw.nbits = 0
+	bw.total = 0
+}
+
 // Write writes the n least-significant bits from bits to the writer.
 // Bits are written LSB-first (least significant bit first).
 // For example, Write(0b101, 3) writes bits in order: 1, 0, 1.
@@ -27,6 +37,7 @@ func (bw *BitWriter) Write(bits uint16, n int) error {
 		bit := (bits >> uint(i)) & 1
 		bw.buf |= byte(bit) << uint(bw.nbits)
 		bw.nbits++
+		bw.total++
 
 		if bw.nbits == 8 {
 			if err := bw.flushByte(); err != nil {
@@ -38,14 +49,40 @@ func (bw *BitWriter) Write(bits uint16, n int) error {
 	return nil
 }
 
+// WriteUint32 writes the n (0-32) least-significant bits of value LSB-first.
+// It behaves like Write but accepts values wider than 16 bits.
+func (bw *BitWriter) WriteUint32(value uint32, n int) error {
+	if n < 0 || n > 32 {
+		return ErrInvalidBitCount
+	}
+
+	low := minInt(n, 16)
+	if err := bw.Write(uint16(value), low); err != nil {
+		return err
+	}
+	return bw.Write(uint16(value>>16), n-low)
+}
+
 // Flush writes any remaining bits in the buffer, padding with zeros to the next byte boundary.
 func (bw *BitWriter) Flush() error {
+	return bw.Align()
+}
+
+// Align pads with zero bits up to the next byte boundary and writes the padded byte,
+// as required before the LEN field of a stored block. It does nothing if already aligned.
+func (bw *BitWriter) Align() error {
 	if bw.nbits > 0 {
+		bw.total += int64(8 - bw.nbits)
 		return bw.flushByte()
 	}
 	return nil
 }
 
+// BitsWritten returns the total number of bits written so far, including alignment padding.
+func (bw *BitWriter) BitsWritten() int64 {
+	return bw.total
+}
+
 // flushByte writes the current byte buffer and resets it.
 func (bw *BitWriter) flushByte() error {
 	if bw.nbits == 0 {
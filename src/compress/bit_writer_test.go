@@ -331,3 +331,114 @@ func TestBitWriter_PartialWrite(t *testing.T) {
 		t.Errorf("Expected 1 byte, got %d", buf.Len())
 	}
 }
+
+func TestBitWriter_WriteUint32(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   int // bits written before the value to misalign it
+		value    uint32
+		n        int
+		expected []byte
+	}{
+		{"20 bits aligned", 0, 0xABCDE, 20, []byte{0xDE, 0xBC, 0x0A}},
+		{"20 bits after 3", 3, 0xABCDE, 20, []byte{0xF0, 0xE6, 0x55}},
+		{"32 bits aligned", 0, 0x12345678, 32, []byte{0x78, 0x56, 0x34, 0x12}},
+		{"32 bits after 4", 4, 0x12345678, 32, []byte{0x80, 0x67, 0x45, 0x23, 0x01}},
+		{"zero bits", 0, 0xFFFFFFFF, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			bw := NewBitWriter(&buf)
+
+			if err := bw.Write(0, tt.prefix); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+			if err := bw.WriteUint32(tt.value, tt.n); err != nil {
+				t.Fatalf("WriteUint32 failed: %v", err)
+			}
+			if err := bw.Flush(); err != nil {
+				t.Fatalf("Flush failed: %v", err)
+			}
+
+			if !bytes.Equal(buf.Bytes(), tt.expected) {
+				t.Errorf("got %x, want %x", buf.Bytes(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestBitWriter_WriteUint32InvalidCount(t *testing.T) {
+	bw := NewBitWriter(&bytes.Buffer{})
+	for _, n := range []int{-1, 33} {
+		if err := bw.WriteUint32(0, n); err != ErrInvalidBitCount {
+			t.Errorf("WriteUint32(0, %d) error = %v, want %v", n, err, ErrInvalidBitCount)
+		}
+	}
+}
+
+func TestBitWriter_AlignAndBitsWritten(t *testing.T) {
+	var buf bytes.Buffer
+	bw := NewBitWriter(&buf)
+
+	if err := bw.Write(0b101, 3); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if got := bw.BitsWritten(); got != 3 {
+		t.Errorf("BitsWritten() = %d, want 3", got)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("buffered bytes before Align = %d, want 0", buf.Len())
+	}
+
+	if err := bw.Align(); err != nil {
+		t.Fatalf("Align failed: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), []byte{0b00000101}) {
+		t.Errorf("got %08b, want [00000101]", buf.Bytes())
+	}
+	if got := bw.BitsWritten(); got != 8 {
+		t.Errorf("BitsWritten() after Align = %d, want 8", got)
+	}
+
+	// Aligning on a byte boundary emits nothing.
+	if err := bw.Align(); err != nil {
+		t.Fatalf("Align failed: %v", err)
+	}
+	if buf.Len() != 1 || bw.BitsWritten() != 8 {
+		t.Errorf("second Align wrote %d bytes, BitsWritten() = %d, want 1 and 8", buf.Len(), bw.BitsWritten())
+	}
+}
+
+func TestBitWriter_Reset(t *testing.T) {
+	var first, second bytes.Buffer
+	bw := NewBitWriter(&first)
+
+	if err := bw.Write(0xAB, 8); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	// A partial byte pending at Reset must not leak into the next stream.
+	if err := bw.Write(0b111, 3); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	bw.Reset(&second)
+	if got := bw.BitsWritten(); got != 0 {
+		t.Errorf("BitsWritten() after Reset = %d, want 0", got)
+	}
+
+	if err := bw.Write(0b01, 2); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := bw.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	if !bytes.Equal(first.Bytes(), []byte{0xAB}) {
+		t.Errorf("first output = %x, want ab", first.Bytes())
+	}
+	if !bytes.Equal(second.Bytes(), []byte{0x01}) {
+		t.Errorf("second output = %x, want 01", second.Bytes())
+	}
+}
@@ -0,0 +1,35 @@
+package compress
+
+// Checksums returns both checksums PNG uses: the CRC-32 stored after each chunk
+// and the Adler-32 stored at the end of the zlib stream.
+func Checksums(data []byte) (crc, adler uint32) {
+	return CRC32(data), Adler32(data)
+}
+
+// ChecksumVector is a known-answer test vector for CRC32 and Adler32.
+type ChecksumVector struct {
+	Input   string
+	CRC32   uint32
+	Adler32 uint32
+}
+
+// checksumVectors holds the standard check strings plus the PNG chunk types.
+var checksumVectors = []ChecksumVector{
+	{Input: "", CRC32: 0x00000000, Adler32: 0x00000001},
+	{Input: "a", CRC32: 0xE8B7BE43, Adler32: 0x00620062},
+	{Input: "abc", CRC32: 0x352441C2, Adler32: 0x024D0127},
+	{Input: "message digest", CRC32: 0x20159D7F, Adler32: 0x29750586},
+	{Input: "abcdefghijklmnopqrstuvwxyz", CRC32: 0x4C2750BD, Adler32: 0x90860B20},
+	{Input: "123456789", CRC32: 0xCBF43926, Adler32: 0x091E01DE},
+	{Input: "Wikipedia", CRC32: 0xADAAC02E, Adler32: 0x11E60398},
+	{Input: "IHDR", CRC32: 0xA8A1AE0A, Adler32: 0x02DA0128},
+	{Input: "IEND", CRC32: 0xAE426082, Adler32: 0x02D70121},
+}
+
+// ChecksumVectors returns a copy of the known-answer vectors for CRC32 and Adler32,
+// for conformance testing of ports. "IEND" gives the CRC that ends every PNG file.
+func ChecksumVectors() []ChecksumVector {
+	vectors := make([]ChecksumVector, len(checksumVectors))
+	copy(vectors, checksumVectors)
+	return vectors
+}
@@ -0,0 +1,55 @@
+package compress
+
+import (
+	"fmt"
+	"hash/adler32"
+	"hash/crc32"
+	"testing"
+)
+
+func TestChecksumVectors(t *testing.T) {
+	for _, v := range ChecksumVectors() {
+		t.Run(fmt.Sprintf("%q", v.Input), func(t *testing.T) {
+			data := []byte(v.Input)
+
+			if want := crc32.ChecksumIEEE(data); v.CRC32 != want {
+				t.Errorf("vector CRC32 = 0x%08X, hash/crc32 = 0x%08X", v.CRC32, want)
+			}
+			if want := adler32.Checksum(data); v.Adler32 != want {
+				t.Errorf("vector Adler32 = 0x%08X, hash/adler32 = 0x%08X", v.Adler32, want)
+			}
+
+			crc, adler := Checksums(data)
+			if crc != v.CRC32 {
+				t.Errorf("Checksums() crc = 0x%08X, want 0x%08X", crc, v.CRC32)
+			}
+			if adler != v.Adler32 {
+				t.Errorf("Checksums() adler = 0x%08X, want 0x%08X", adler, v.Adler32)
+			}
+		})
+	}
+}
+
+func TestChecksumVectorsReturnsCopy(t *testing.T) {
+	vectors := ChecksumVectors()
+	vectors[0].CRC32 = 0xFFFFFFFF
+	if ChecksumVectors()[0].CRC32 == 0xFFFFFFFF {
+		t.Error("ChecksumVectors() exposed its internal table")
+	}
+}
+
+func ExampleCRC32() {
+	fmt.Printf("0x%08X\n", CRC32([]byte("IEND")))
+	// Output: 0xAE426082
+}
+
+func ExampleAdler32() {
+	fmt.Printf("0x%08X\n", Adler32([]byte("Wikipedia")))
+	// Output: 0x11E60398
+}
+
+func ExampleChecksums() {
+	crc, adler := Checksums([]byte("123456789"))
+	fmt.Printf("crc=0x%08X adler=0x%08X\n", crc, adler)
+	// Output: crc=0xCBF43926 adler=0x091E01DE
+}
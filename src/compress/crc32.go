@@ -5,10 +5,28 @@ import (
 	"hash/crc32"
 )
 
+// CRC32 computes the IEEE CRC-32 used for PNG chunk checksums.
 func CRC32(data []byte) uint32 {
 	return crc32.ChecksumIEEE(data)
 }
 
+// NewCRC32 returns a streaming IEEE CRC-32 hash.
 func NewCRC32() hash.Hash32 {
 	return crc32.NewIEEE()
 }
+
+// UpdateCRC32 returns crc extended with data, so a chunk's type and data can
+// be checksummed without joining them.
+func UpdateCRC32(crc uint32, data []byte) uint32 {
+	return crc32.Update(crc, crc32.IEEETable, data)
+}
+
+// UpdateCRC32String is UpdateCRC32 for a short string such as a chunk type. It
+// walks the table directly, so s never has to be copied to the heap.
+func UpdateCRC32String(crc uint32, s string) uint32 {
+	crc = ^crc
+	for i := 0; i < len(s); i++ {
+		crc = crc32.IEEETable[byte(crc)^s[i]] ^ (crc >> 8)
+	}
+	return ^crc
+}
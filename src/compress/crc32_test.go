@@ -78,3 +78,25 @@ func TestCRC32ChunkTypeAndData(t *testing.T) {
 		t.Errorf("CRC32(chunkType + chunkData) = 0x%08x, want 0x%08x", result, expected)
 	}
 }
+
+func TestUpdateCRC32(t *testing.T) {
+	chunkType := []byte("IDAT")
+	chunkData := []byte{0x78, 0x9C, 0x63, 0x60, 0x00, 0x00, 0x00, 0x02, 0x00, 0x01}
+
+	got := UpdateCRC32(CRC32(chunkType), chunkData)
+	want := CRC32(append(append([]byte{}, chunkType...), chunkData...))
+	if got != want {
+		t.Errorf("UpdateCRC32() = 0x%08x, want 0x%08x", got, want)
+	}
+}
+
+func TestUpdateCRC32String(t *testing.T) {
+	for _, s := range []string{"", "IHDR", "tEXt", "a longer string than a chunk type"} {
+		if got, want := UpdateCRC32String(0, s), CRC32([]byte(s)); got != want {
+			t.Errorf("UpdateCRC32String(0, %q) = 0x%08x, want 0x%08x", s, got, want)
+		}
+	}
+	if got, want := UpdateCRC32String(CRC32([]byte("IH")), "DR"), CRC32([]byte("IHDR")); got != want {
+		t.Errorf("UpdateCRC32String() continued = 0x%08x, want 0x%08x", got, want)
+	}
+}
@@ -11,7 +11,15 @@ func WriteStoredBlockDeflate(w io.Writer, final bool, data []byte) error {
 // Tokens are encoded using the RFC1951 fixed Huffman tables.
 func WriteFixedBlock(w io.Writer, final bool, tokens []Token) error {
 	bw := NewBitWriter(w)
+	if err := writeFixedBlock(bw, final, tokens); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
 
+// writeFixedBlock writes a fixed Huffman block to bw without byte-aligning afterwards,
+// so further blocks can follow in the same bit stream.
+func writeFixedBlock(bw *BitWriter, final bool, tokens []Token) error {
 	var blockHeader uint16
 	if final {
 		blockHeader |= 0x01
@@ -24,36 +32,30 @@ func WriteFixedBlock(w io.Writer, final bool, tokens []Token) error {
 	litTable := LiteralLengthTable()
 	distTable := DistanceTable()
 
-	for _, token := range tokens {
-		if token.IsLiteral {
-			if err := EncodeLiteral(bw, int(token.Literal), litTable); err != nil {
-				return err
-			}
-		} else {
-			length := int(token.Match.Length)
-			distance := int(token.Match.Distance)
-
-			if err := EncodeLength(bw, length, litTable); err != nil {
-				return err
-			}
-			if err := EncodeDistance(bw, distance, distTable); err != nil {
-				return err
-			}
-		}
-	}
-
-	if err := EncodeLiteral(bw, EndOfBlockSymbol, litTable); err != nil {
-		return err
-	}
-
-	return bw.Flush()
+	return encodeTokens(bw, tokens, litTable, distTable)
 }
 
 // WriteDynamicBlock writes a dynamic Huffman DEFLATE block.
 // Tokens are encoded using custom Huffman tables built from token frequencies.
 func WriteDynamicBlock(w io.Writer, final bool, tokens []Token) error {
 	bw := NewBitWriter(w)
+	if err := writeDynamicBlock(bw, final, tokens); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// writeDynamicBlock writes a dynamic Huffman block to bw without byte-aligning afterwards,
+// so further blocks can follow in the same bit stream.
+func writeDynamicBlock(bw *BitWriter, final bool, tokens []Token) error {
+	litFreq, distFreq := countTokenFrequencies(tokens)
+	litTable, distTable := BuildDynamicTables(litFreq, distFreq)
+	return writeDynamicBlockTables(bw, final, tokens, litTable, distTable)
+}
 
+// writeDynamicBlockTables is writeDynamicBlock with tables already built from
+// the tokens' frequencies.
+func writeDynamicBlockTables(bw *BitWriter, final bool, tokens []Token, litTable, distTable Table) error {
 	var blockHeader uint16
 	if final {
 		blockHeader |= 0x01
@@ -63,9 +65,6 @@ func WriteDynamicBlock(w io.Writer, final bool, tokens []Token) error {
 		return err
 	}
 
-	litFreq, distFreq := countTokenFrequencies(tokens)
-	litTable, distTable := BuildDynamicTables(litFreq, distFreq)
-
 	litLengths := extractCodeLengths(litTable)
 	distLengths := extractCodeLengths(distTable)
 
@@ -73,29 +72,29 @@ func WriteDynamicBlock(w io.Writer, final bool, tokens []Token) error {
 		return err
 	}
 
+	return encodeTokens(bw, tokens, litTable, distTable)
+}
+
+// encodeTokens writes tokens with the given tables followed by the end-of-block
+// symbol. It is the only place block writers emit EOB, so it always comes last.
+func encodeTokens(bw *BitWriter, tokens []Token, litTable, distTable Table) error {
 	for _, token := range tokens {
 		if token.IsLiteral {
 			if err := EncodeLiteral(bw, int(token.Literal), litTable); err != nil {
 				return err
 			}
-		} else {
-			length := int(token.Match.Length)
-			distance := int(token.Match.Distance)
-
-			if err := EncodeLength(bw, length, litTable); err != nil {
-				return err
-			}
-			if err := EncodeDistance(bw, distance, distTable); err != nil {
-				return err
-			}
+			continue
 		}
-	}
 
-	if err := EncodeLiteral(bw, EndOfBlockSymbol, litTable); err != nil {
-		return err
+		if err := EncodeLength(bw, int(token.Match.Length), litTable); err != nil {
+			return err
+		}
+		if err := EncodeDistance(bw, int(token.Match.Distance), distTable); err != nil {
+			return err
+		}
 	}
 
-	return bw.Flush()
+	return EncodeLiteral(bw, EndOfBlockSymbol, litTable)
 }
 
 // countTokenFrequencies counts frequencies of literal/length and distance symbols from tokens.
@@ -372,3 +372,74 @@ func TestWriteDynamicBlock_NoZeroLengthCodesForEmittedSymbols(t *testing.T) {
 		t.Errorf("got %q, want %q", decompressed[:n], expected)
 	}
 }
+
+func TestWriteDynamicBlock_SkewedFrequenciesRoundTrip(t *testing.T) {
+	// Fibonacci-weighted literals would need a 24-bit code without length limiting.
+	var data []byte
+	for symbol, count := range fibonacciFrequencies(25) {
+		for i := 0; i < count; i++ {
+			data = append(data, byte(symbol))
+		}
+	}
+	tokens := make([]Token, len(data))
+	for i, b := range data {
+		tokens[i] = TokenLiteral(b)
+	}
+
+	litFreq, distFreq := countTokenFrequencies(tokens)
+	litTable, _ := BuildDynamicTables(litFreq, distFreq)
+	if litTable.MaxLength > MaxCodeLength {
+		t.Fatalf("litTable.MaxLength = %d, want <= %d", litTable.MaxLength, MaxCodeLength)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDynamicBlock(&buf, true, tokens); err != nil {
+		t.Fatalf("WriteDynamicBlock failed: %v", err)
+	}
+
+	decoded, err := io.ReadAll(flate.NewReader(&buf))
+	if err != nil {
+		t.Fatalf("decompress failed: %v", err)
+	}
+	if !bytes.Equal(decoded, data) {
+		t.Errorf("decoded %d bytes, want %d bytes matching input", len(decoded), len(data))
+	}
+}
+
+func TestFixedAndDynamicBlocksDecodeIdentically(t *testing.T) {
+	data := []byte("fixed and dynamic blocks, fixed and dynamic blocks, same tokens")
+	tests := []struct {
+		name   string
+		tokens []Token
+		want   []byte
+	}{
+		{"empty", nil, []byte{}},
+		{"literals only", []Token{TokenLiteral('x'), TokenLiteral('y')}, []byte("xy")},
+		{"lz77 tokens", NewLZ77Encoder().Encode(data), data},
+		{"long run", []Token{TokenLiteral(7), TokenMatch(1, 258), TokenMatch(1, 3)}, bytes.Repeat([]byte{7}, 262)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fixed, dynamic bytes.Buffer
+			if err := WriteFixedBlock(&fixed, true, tt.tokens); err != nil {
+				t.Fatalf("WriteFixedBlock() error = %v", err)
+			}
+			if err := WriteDynamicBlock(&dynamic, true, tt.tokens); err != nil {
+				t.Fatalf("WriteDynamicBlock() error = %v", err)
+			}
+
+			gotFixed, err := io.ReadAll(flate.NewReader(&fixed))
+			if err != nil {
+				t.Fatalf("fixed block decompression error = %v", err)
+			}
+			gotDynamic, err := io.ReadAll(flate.NewReader(&dynamic))
+			if err != nil {
+				t.Fatalf("dynamic block decompression error = %v", err)
+			}
+			if !bytes.Equal(gotFixed, tt.want) || !bytes.Equal(gotDynamic, tt.want) {
+				t.Errorf("fixed = %q, dynamic = %q, want %q", gotFixed, gotDynamic, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package compress
+
+import "bytes"
+
+// DefaultCompression selects level 6 in Deflate and Zlib.
+const DefaultCompression = -1
+
+// Deflate compresses data into a raw DEFLATE stream. Level 0 stores the data
+// uncompressed, 1-9 trade speed for size, and DefaultCompression means 6. The
+// smaller of the fixed and dynamic Huffman encodings is used, falling back to
+// stored blocks when compression would expand the data. A level outside -1 to 9
+// returns ErrInvalidLevel.
+func Deflate(data []byte, level int) ([]byte, error) {
+	if level == DefaultCompression {
+		level = 6
+	}
+	if level < 0 || level > 9 {
+		return nil, ErrInvalidLevel
+	}
+
+	storedSize := StoredBlocksSize(len(data))
+	if level > 0 {
+		enc := NewDeflateEncoder()
+		enc.SetCompressionLevel(level)
+		compressed, err := enc.EncodeAuto(data)
+		if err != nil {
+			return nil, err
+		}
+		if len(compressed) <= storedSize {
+			return compressed, nil
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(storedSize)
+	if err := WriteStoredBlocks(&buf, data, true); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Zlib compresses data like Deflate and wraps it in zlib framing: a CMF/FLG
+// header for a 32K window and the level, and an Adler-32 trailer.
+func Zlib(data []byte, level int) ([]byte, error) {
+	if level == DefaultCompression {
+		level = 6
+	}
+	deflated, err := Deflate(data, level)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := ZlibHeaderBytes(32768, ZlibLevel(level))
+	if err != nil {
+		return nil, err
+	}
+	footer := ZlibFooterBytes(Adler32(data))
+
+	result := make([]byte, 0, len(header)+len(deflated)+len(footer))
+	result = append(result, header...)
+	result = append(result, deflated...)
+	result = append(result, footer[:]...)
+	return result, nil
+}
@@ -0,0 +1,69 @@
+package compress
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+func TestDeflateAndZlib(t *testing.T) {
+	random := make([]byte, 2000)
+	rand.New(rand.NewSource(3)).Read(random)
+	inputs := map[string][]byte{
+		"empty":      nil,
+		"repetitive": bytes.Repeat([]byte("deflate "), 500),
+		"random":     random,
+	}
+
+	for name, data := range inputs {
+		for _, level := range []int{DefaultCompression, 0, 1, 6, 9} {
+			t.Run(fmt.Sprintf("%s/level=%d", name, level), func(t *testing.T) {
+				deflated, err := Deflate(data, level)
+				if err != nil {
+					t.Fatalf("Deflate() error = %v", err)
+				}
+				got, err := io.ReadAll(flate.NewReader(bytes.NewReader(deflated)))
+				if err != nil {
+					t.Fatalf("flate decompression error = %v", err)
+				}
+				if !bytes.Equal(got, data) {
+					t.Error("Deflate() output does not decompress to the input")
+				}
+				if len(deflated) > StoredBlocksSize(len(data)) {
+					t.Errorf("Deflate() size = %d, want <= stored size %d", len(deflated), StoredBlocksSize(len(data)))
+				}
+
+				wrapped, err := Zlib(data, level)
+				if err != nil {
+					t.Fatalf("Zlib() error = %v", err)
+				}
+				r, err := zlib.NewReader(bytes.NewReader(wrapped))
+				if err != nil {
+					t.Fatalf("zlib.NewReader() error = %v", err)
+				}
+				got, err = io.ReadAll(r)
+				if err != nil {
+					t.Fatalf("zlib decompression error = %v", err)
+				}
+				if !bytes.Equal(got, data) {
+					t.Error("Zlib() output does not decompress to the input")
+				}
+			})
+		}
+	}
+}
+
+func TestDeflateInvalidLevel(t *testing.T) {
+	for _, level := range []int{-2, 10} {
+		if _, err := Deflate([]byte("x"), level); err != ErrInvalidLevel {
+			t.Errorf("Deflate(level=%d) error = %v, want %v", level, err, ErrInvalidLevel)
+		}
+		if _, err := Zlib([]byte("x"), level); err != ErrInvalidLevel {
+			t.Errorf("Zlib(level=%d) error = %v, want %v", level, err, ErrInvalidLevel)
+		}
+	}
+}
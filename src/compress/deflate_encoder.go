@@ -9,6 +9,7 @@ import (
 type DeflateEncoder struct {
 	lz77             *LZ77Encoder
 	compressionLevel int
+	dictionary       []byte
 }
 
 // NewDeflateEncoder creates a new DEFLATE encoder.
@@ -31,6 +32,17 @@ func (enc *DeflateEncoder) SetCompressionLevel(level int) {
 	enc.lz77.SetCompressionLevel(level)
 }
 
+// SetDictionary sets a preset dictionary that matches may refer back to, as if
+// it preceded the data. Only its last 32K bytes are within reach. The output can
+// only be inflated with the same dictionary (see ZlibDictHeaderBytes). A nil or
+// empty dict clears it.
+func (enc *DeflateEncoder) SetDictionary(dict []byte) {
+	if len(dict) > MaxDistance {
+		dict = dict[len(dict)-MaxDistance:]
+	}
+	enc.dictionary = dict
+}
+
 // Encode compresses data using DEFLATE with the specified block type.
 // If useDynamic is true, uses dynamic Huffman tables; otherwise uses fixed tables.
 func (enc *DeflateEncoder) Encode(data []byte, useDynamic bool) ([]byte, error) {
@@ -42,7 +54,15 @@ func (enc *DeflateEncoder) Encode(data []byte, useDynamic bool) ([]byte, error)
 		return buf.Bytes(), nil
 	}
 
-	tokens := enc.lz77.Encode(data)
+	var tokens []Token
+	if len(enc.dictionary) > 0 {
+		history := make([]byte, 0, len(enc.dictionary)+len(data))
+		history = append(history, enc.dictionary...)
+		history = append(history, data...)
+		tokens = enc.lz77.EncodeFrom(history, len(enc.dictionary))
+	} else {
+		tokens = enc.lz77.Encode(data)
+	}
 
 	var buf bytes.Buffer
 	if useDynamic {
@@ -91,31 +111,31 @@ func (enc *DeflateEncoder) EncodeOptimal(data []byte) ([]byte, error) {
 
 	// For now, use multiple passes with increasing compression level
 	// A full Zopfli implementation would use optimal parsing with cost model
-	bestResult := data
-	bestSize := len(data)
+	originalLevel := enc.compressionLevel
+	defer enc.SetCompressionLevel(originalLevel)
+
+	var bestResult []byte
+	var lastErr error
 
 	// Try multiple iterations with increasing effort
 	for iteration := 0; iteration < 5; iteration++ {
-		// Increase compression level each iteration
-		enc.SetCompressionLevel(enc.compressionLevel + iteration)
-		if enc.compressionLevel > 9 {
-			enc.SetCompressionLevel(9)
-		}
+		// Increase compression level each iteration (SetCompressionLevel caps at 9)
+		enc.SetCompressionLevel(originalLevel + iteration)
 
 		result, err := enc.EncodeAuto(data)
 		if err != nil {
+			lastErr = err
 			continue
 		}
 
-		if len(result) < bestSize {
+		if bestResult == nil || len(result) < len(bestResult) {
 			bestResult = result
-			bestSize = len(result)
 		}
 	}
 
-	// Reset to original level
-	enc.SetCompressionLevel(enc.compressionLevel)
-
+	if bestResult == nil {
+		return nil, lastErr
+	}
 	return bestResult, nil
 }
 
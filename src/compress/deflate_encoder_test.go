@@ -269,3 +269,59 @@ func TestDeflateEncoder_EncodeAuto_ReturnsSmallerWhenBothSucceed(t *testing.T) {
 			len(auto), len(fixed), len(dynamic))
 	}
 }
+
+func TestDeflateEncoder_EncodeOptimalIncompressible(t *testing.T) {
+	// Distinct bytes with no repeats: no pass beats the input length, but the
+	// result must still be a valid DEFLATE stream rather than the raw input.
+	data := make([]byte, 136)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+
+	enc := NewDeflateEncoder()
+	enc.SetCompressionLevel(3)
+	compressed, err := enc.EncodeOptimal(data)
+	if err != nil {
+		t.Fatalf("EncodeOptimal() error = %v", err)
+	}
+
+	got, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	if err != nil {
+		t.Fatalf("flate decompression error = %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("decompressed data does not match input")
+	}
+	if enc.compressionLevel != 3 {
+		t.Errorf("compression level after EncodeOptimal = %d, want 3", enc.compressionLevel)
+	}
+}
+
+func TestDeflateEncoder_Dictionary(t *testing.T) {
+	dict := bytes.Repeat([]byte("sprite header 0123456789 "), 8)
+	data := []byte("sprite header 0123456789 with a small change")
+
+	plain := NewDeflateEncoder()
+	withoutDict, err := plain.EncodeAuto(data)
+	if err != nil {
+		t.Fatalf("EncodeAuto() error = %v", err)
+	}
+
+	enc := NewDeflateEncoder()
+	enc.SetDictionary(dict)
+	compressed, err := enc.EncodeAuto(data)
+	if err != nil {
+		t.Fatalf("EncodeAuto() with dictionary error = %v", err)
+	}
+	if len(compressed) >= len(withoutDict) {
+		t.Errorf("size with dictionary = %d, want < %d", len(compressed), len(withoutDict))
+	}
+
+	got, err := io.ReadAll(flate.NewReaderDict(bytes.NewReader(compressed), dict))
+	if err != nil {
+		t.Fatalf("flate decompression error = %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("decompressed = %q, want %q", got, data)
+	}
+}
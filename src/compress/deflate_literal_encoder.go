@@ -8,12 +8,19 @@ func (e DeflateError) Error() string {
 }
 
 const (
-	ErrInvalidSymbol   DeflateError = "invalid symbol"
-	ErrInvalidLength   DeflateError = "invalid length"
-	ErrInvalidDistance DeflateError = "invalid distance"
-	ErrInvalidHLIT     DeflateError = "invalid HLIT"
-	ErrInvalidHDIST    DeflateError = "invalid HDIST"
-	ErrInvalidHCLEN    DeflateError = "invalid HCLEN"
+	ErrInvalidSymbol    DeflateError = "invalid symbol"
+	ErrInvalidLength    DeflateError = "invalid length"
+	ErrInvalidDistance  DeflateError = "invalid distance"
+	ErrInvalidHLIT      DeflateError = "invalid HLIT"
+	ErrInvalidHDIST     DeflateError = "invalid HDIST"
+	ErrInvalidHCLEN     DeflateError = "invalid HCLEN"
+	ErrInvalidBitCount  DeflateError = "invalid bit count"
+	ErrWriterClosed     DeflateError = "write to closed DeflateWriter"
+	ErrCodeTooLong      DeflateError = "huffman code longer than 15 bits"
+	ErrOversubscribed   DeflateError = "huffman code lengths oversubscribed"
+	ErrCodesNotPrefixed DeflateError = "huffman codes not prefix-free"
+	ErrInvalidGzipName  DeflateError = "gzip filename must be Latin-1 without NUL bytes"
+	ErrInvalidLevel     DeflateError = "invalid compression level"
 )
 
 // EncodeLiteral writes a literal symbol (0-255) or end-of-block (256) to the bit writer.
@@ -103,8 +110,56 @@ func EncodeDistance(w *BitWriter, distance int, table Table) error {
 	return nil
 }
 
+// LengthCode returns the DEFLATE length symbol (257-285) for a match length (3-258),
+// the number of extra bits that follow it, and the value stored in those bits.
+// For lengths outside 3-258 it returns code -1.
+func LengthCode(length int) (code, extraBits int, extraValue uint16) {
+	if length < MinMatchLength || length > MaxMatchLength {
+		return -1, 0, 0
+	}
+	code = findLengthCode(length)
+	if code < 0 {
+		return -1, 0, 0
+	}
+	extraBits = int(LengthExtraBits[code-257])
+	extraValue = uint16(length - int(LengthBase[code-257]))
+	return code, extraBits, extraValue
+}
+
+// DistanceCode returns the DEFLATE distance symbol (0-29) for a distance (1-32768),
+// the number of extra bits that follow it, and the value stored in those bits.
+// For distances outside 1-32768 it returns code -1.
+func DistanceCode(distance int) (code, extraBits int, extraValue uint16) {
+	if distance < 1 || distance > MaxDistance {
+		return -1, 0, 0
+	}
+	code = findDistanceCode(distance)
+	if code < 0 {
+		return -1, 0, 0
+	}
+	extraBits = int(DistanceExtraBits[code])
+	extraValue = uint16(distance - int(DistanceBase[code]))
+	return code, extraBits, extraValue
+}
+
+// LengthTables returns copies of the length base and extra-bits tables,
+// indexed by length symbol - 257.
+func LengthTables() (base [29]uint16, extraBits [29]uint8) {
+	return LengthBase, LengthExtraBits
+}
+
+// DistanceTables returns copies of the distance base and extra-bits tables,
+// indexed by distance symbol.
+func DistanceTables() (base [30]uint16, extraBits [30]uint8) {
+	return DistanceBase, DistanceExtraBits
+}
+
 // findLengthCode finds the length code (257-285) for a given length (3-258).
+// Length 258 has its own symbol (285); code 284 only covers 227-257.
 func findLengthCode(length int) int {
+	if length == MaxMatchLength {
+		return 285
+	}
 	for code := 0; code < len(LengthBase); code++ {
 		base := int(LengthBase[code])
 		extraBits := LengthExtraBits[code]
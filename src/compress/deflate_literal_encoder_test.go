@@ -2,7 +2,10 @@ package compress
 
 import (
 	"bytes"
+	"compress/flate"
 	"fmt"
+	"io"
+	"math/rand"
 	"testing"
 )
 
@@ -173,3 +176,130 @@ func TestEncodeDistance_BoundaryValues(t *testing.T) {
 		})
 	}
 }
+
+func TestLengthCode_BoundaryValues(t *testing.T) {
+	tests := []struct {
+		length     int
+		code       int
+		extraBits  int
+		extraValue uint16
+	}{
+		{3, 257, 0, 0},
+		{10, 264, 0, 0},
+		{11, 265, 1, 0},
+		{12, 265, 1, 1},
+		{19, 269, 2, 0},
+		{22, 269, 2, 3},
+		{35, 273, 3, 0},
+		{67, 277, 4, 0},
+		{131, 281, 5, 0},
+		{227, 284, 5, 0},
+		{257, 284, 5, 30},
+		{258, 285, 0, 0},
+		{2, -1, 0, 0},
+		{259, -1, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("length_%d", tt.length), func(t *testing.T) {
+			code, extraBits, extraValue := LengthCode(tt.length)
+			if code != tt.code || extraBits != tt.extraBits || extraValue != tt.extraValue {
+				t.Errorf("LengthCode(%d) = (%d, %d, %d), want (%d, %d, %d)",
+					tt.length, code, extraBits, extraValue, tt.code, tt.extraBits, tt.extraValue)
+			}
+		})
+	}
+}
+
+func TestDistanceCode_BoundaryValues(t *testing.T) {
+	tests := []struct {
+		distance   int
+		code       int
+		extraBits  int
+		extraValue uint16
+	}{
+		{1, 0, 0, 0},
+		{4, 3, 0, 0},
+		{5, 4, 1, 0},
+		{6, 4, 1, 1},
+		{7, 5, 1, 0},
+		{257, 16, 7, 0},
+		{24577, 29, 13, 0},
+		{32768, 29, 13, 8191},
+		{0, -1, 0, 0},
+		{32769, -1, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("distance_%d", tt.distance), func(t *testing.T) {
+			code, extraBits, extraValue := DistanceCode(tt.distance)
+			if code != tt.code || extraBits != tt.extraBits || extraValue != tt.extraValue {
+				t.Errorf("DistanceCode(%d) = (%d, %d, %d), want (%d, %d, %d)",
+					tt.distance, code, extraBits, extraValue, tt.code, tt.extraBits, tt.extraValue)
+			}
+		})
+	}
+}
+
+func TestLengthAndDistanceTablesAreCopies(t *testing.T) {
+	base, extra := LengthTables()
+	base[0] = 99
+	extra[0] = 9
+	if LengthBase[0] != 3 || LengthExtraBits[0] != 0 {
+		t.Errorf("LengthTables() returned shared storage")
+	}
+
+	distBase, distExtra := DistanceTables()
+	if distBase[29] != 24577 || distExtra[29] != 13 {
+		t.Errorf("DistanceTables() last entry = (%d, %d), want (24577, 13)", distBase[29], distExtra[29])
+	}
+}
+
+func TestFixedBlockMatchRoundTrip(t *testing.T) {
+	history := make([]byte, MaxDistance)
+	rand.New(rand.NewSource(5)).Read(history)
+
+	// The first and last distance of every distance code, each with the full
+	// length range.
+	var distances []int
+	for code, base := range DistanceBase {
+		last := int(base) + 1<<DistanceExtraBits[code] - 1
+		distances = append(distances, int(base))
+		if last != int(base) && last <= MaxDistance {
+			distances = append(distances, last)
+		}
+	}
+
+	fr := flate.NewReader(bytes.NewReader(nil))
+	for _, distance := range distances {
+		// A stored block supplies the history, so the fixed block holds just the match.
+		var prefix bytes.Buffer
+		if err := WriteStoredBlocks(&prefix, history[:distance], false); err != nil {
+			t.Fatalf("WriteStoredBlocks() error = %v", err)
+		}
+
+		for length := MinMatchLength; length <= MaxMatchLength; length++ {
+			var block bytes.Buffer
+			if err := WriteFixedBlock(&block, true, []Token{TokenMatch(uint16(distance), uint16(length))}); err != nil {
+				t.Fatalf("WriteFixedBlock(distance %d, length %d) error = %v", distance, length, err)
+			}
+			stream := io.MultiReader(bytes.NewReader(prefix.Bytes()), &block)
+			if err := fr.(flate.Resetter).Reset(stream, nil); err != nil {
+				t.Fatalf("flate reset error = %v", err)
+			}
+			got, err := io.ReadAll(fr)
+			if err != nil {
+				t.Fatalf("distance %d, length %d: flate decompression error = %v", distance, length, err)
+			}
+
+			if len(got) != distance+length {
+				t.Fatalf("distance %d, length %d: decoded %d bytes, want %d", distance, length, len(got), distance+length)
+			}
+			for i := distance; i < len(got); i++ {
+				if got[i] != got[i-distance] {
+					t.Fatalf("distance %d, length %d: byte %d = %d, want copy of byte %d = %d", distance, length, i, got[i], i-distance, got[i-distance])
+				}
+			}
+		}
+	}
+}
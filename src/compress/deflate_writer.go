@@ -0,0 +1,115 @@
+package compress
+
+import "io"
+
+// deflateWriterBlockSize is the amount of pending input that triggers a block flush.
+const deflateWriterBlockSize = 64 * 1024
+
+// DeflateWriter compresses data written to it in pieces into a single DEFLATE stream.
+// Input is buffered until a full block has accrued; LZ77 matches may reach back
+// across Write calls up to the 32K window. Close must be called to emit the final block.
+type DeflateWriter struct {
+	bw      *BitWriter
+	lz77    *LZ77Encoder
+	buf     []byte // window history followed by pending (not yet encoded) input
+	pending int    // index in buf where pending input starts
+	closed  bool
+}
+
+// NewDeflateWriter creates a DeflateWriter writing to w with the given compression level (1-9).
+func NewDeflateWriter(w io.Writer, level int) *DeflateWriter {
+	lz77 := NewLZ77Encoder()
+	lz77.SetCompressionLevel(level)
+	return &DeflateWriter{
+		bw:   NewBitWriter(w),
+		lz77: lz77,
+	}
+}
+
+// Write buffers p, compressing and writing complete blocks as enough data accrues.
+func (dw *DeflateWriter) Write(p []byte) (int, error) {
+	if dw.closed {
+		return 0, ErrWriterClosed
+	}
+
+	dw.buf = append(dw.buf, p...)
+	for len(dw.buf)-dw.pending >= deflateWriterBlockSize {
+		if err := dw.writeBlock(dw.pending+deflateWriterBlockSize, false); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// Close compresses any remaining input as the final block and flushes the stream.
+// Calling Close more than once has no effect.
+func (dw *DeflateWriter) Close() error {
+	if dw.closed {
+		return nil
+	}
+	dw.closed = true
+
+	if err := dw.writeBlock(len(dw.buf), true); err != nil {
+		return err
+	}
+	return dw.bw.Flush()
+}
+
+// writeBlock encodes buf[pending:end] as one block, choosing fixed or dynamic
+// Huffman tables by size, then slides the window so at most 32K of history remains.
+// The sizes are counted from the symbol frequencies, so the tokens are only
+// encoded once.
+func (dw *DeflateWriter) writeBlock(end int, final bool) error {
+	tokens := dw.lz77.EncodeFrom(dw.buf[:end], dw.pending)
+
+	var err error
+	if litTable, distTable, ok := smallerDynamicTables(tokens); ok {
+		err = writeDynamicBlockTables(dw.bw, final, tokens, litTable, distTable)
+	} else {
+		err = writeFixedBlock(dw.bw, final, tokens)
+	}
+	if err != nil {
+		return err
+	}
+
+	dw.pending = end
+	if dw.pending > maxDistance {
+		drop := dw.pending - maxDistance
+		dw.buf = append(dw.buf[:0], dw.buf[drop:]...)
+		dw.pending -= drop
+	}
+
+	return nil
+}
+
+// smallerDynamicTables builds dynamic Huffman tables for tokens and reports
+// whether a block using them is smaller than one using the fixed tables. Extra
+// bits and the block header cost the same either way, so only the code-length
+// header and the symbol codes are counted.
+func smallerDynamicTables(tokens []Token) (litTable, distTable Table, ok bool) {
+	if len(tokens) == 0 {
+		return Table{}, Table{}, false
+	}
+	litFreq, distFreq := countTokenFrequencies(tokens)
+	litTable, distTable = BuildDynamicTables(litFreq, distFreq)
+
+	header := NewBitWriter(io.Discard)
+	if err := WriteDynamicHeader(header, extractCodeLengths(litTable), extractCodeLengths(distTable)); err != nil {
+		return Table{}, Table{}, false
+	}
+	dynamicBits := header.BitsWritten() + symbolBits(litFreq, litTable) + symbolBits(distFreq, distTable)
+	fixedBits := symbolBits(litFreq, LiteralLengthTable()) + symbolBits(distFreq, DistanceTable())
+	return litTable, distTable, dynamicBits < fixedBits
+}
+
+// symbolBits returns the bits freq costs under table's code lengths.
+func symbolBits(freq []int, table Table) int64 {
+	var bits int64
+	for sym, f := range freq {
+		if f > 0 && sym < len(table.Codes) {
+			bits += int64(f) * int64(table.Codes[sym].Length)
+		}
+	}
+	return bits
+}
@@ -0,0 +1,87 @@
+package compress
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func deflateWriterTestData() []byte {
+	var buf bytes.Buffer
+	for i := 0; buf.Len() < 150000; i++ {
+		fmt.Fprintf(&buf, "row %d: the quick brown fox jumps over the lazy dog %d\n", i%97, i%13)
+	}
+	return buf.Bytes()
+}
+
+func TestDeflateWriter_ChunkedMatchesOneShot(t *testing.T) {
+	data := deflateWriterTestData()
+
+	oneShot, err := NewDeflateEncoder().EncodeAuto(data)
+	if err != nil {
+		t.Fatalf("EncodeAuto failed: %v", err)
+	}
+	want, err := io.ReadAll(flate.NewReader(bytes.NewReader(oneShot)))
+	if err != nil {
+		t.Fatalf("one-shot decompression failed: %v", err)
+	}
+
+	for _, chunkSize := range []int{1, 100, len(data)} {
+		t.Run(fmt.Sprintf("chunk=%d", chunkSize), func(t *testing.T) {
+			var out bytes.Buffer
+			dw := NewDeflateWriter(&out, 6)
+			for i := 0; i < len(data); i += chunkSize {
+				end := i + chunkSize
+				if end > len(data) {
+					end = len(data)
+				}
+				if _, err := dw.Write(data[i:end]); err != nil {
+					t.Fatalf("Write failed: %v", err)
+				}
+			}
+			if err := dw.Close(); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+
+			got, err := io.ReadAll(flate.NewReader(bytes.NewReader(out.Bytes())))
+			if err != nil {
+				t.Fatalf("decompression failed: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("decompressed %d bytes, want %d matching bytes", len(got), len(want))
+			}
+			if out.Len() >= len(data)/2 {
+				t.Errorf("compressed size %d, want well under %d", out.Len(), len(data)/2)
+			}
+		})
+	}
+}
+
+func TestDeflateWriter_Empty(t *testing.T) {
+	var out bytes.Buffer
+	dw := NewDeflateWriter(&out, 6)
+	if err := dw.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	got, err := io.ReadAll(flate.NewReader(bytes.NewReader(out.Bytes())))
+	if err != nil {
+		t.Fatalf("decompression failed: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %d bytes, want 0", len(got))
+	}
+}
+
+func TestDeflateWriter_WriteAfterClose(t *testing.T) {
+	var out bytes.Buffer
+	dw := NewDeflateWriter(&out, 6)
+	if err := dw.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := dw.Write([]byte("x")); err != ErrWriterClosed {
+		t.Errorf("Write after Close error = %v, want %v", err, ErrWriterClosed)
+	}
+}
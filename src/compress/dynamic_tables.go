@@ -11,6 +11,7 @@ func BuildDynamicTables(litFreq []int, distFreq []int) (litTable Table, distTabl
 	litLengths := make([]int, 287)
 	if litTree != nil {
 		codesMap := GenerateCodes(litTree)
+		LimitCodeLengths(codesMap, MaxCodeLength)
 		canonCodes, canonLengths := Canonicalize(codesMap)
 		if canonCodes != nil {
 			copy(litCodes, canonCodes)
@@ -31,6 +32,7 @@ func BuildDynamicTables(litFreq []int, distFreq []int) (litTable Table, distTabl
 	distLengths := make([]int, 30)
 	if distTree != nil {
 		codesMap := GenerateCodes(distTree)
+		LimitCodeLengths(codesMap, MaxCodeLength)
 		canonCodes, canonLengths := Canonicalize(codesMap)
 		if canonCodes != nil {
 			copy(distCodes, canonCodes)
@@ -59,7 +61,7 @@ func BuildDynamicTables(litFreq []int, distFreq []int) (litTable Table, distTabl
 }
 
 // ensureAtLeastTwoSymbols ensures the frequency table has at least 2 non-zero entries.
-// If only one symbol has non-zero frequency, injects a dummy second symbol (first unused symbol).
+// If only one symbol has non-zero frequency, injects a dummy second symbol (0, or 1 if 0 is used).
 // This prevents degenerate single-symbol Huffman trees that would produce zero-length codes.
 func ensureAtLeastTwoSymbols(freq []int, maxSymbol int) []int {
 	result := make([]int, maxSymbol)
@@ -86,24 +88,14 @@ func ensureAtLeastTwoSymbols(freq []int, maxSymbol int) []int {
 	}
 
 	if nonZeroCount == 1 {
-		dummySymbol := firstNonZero + 1
-		if dummySymbol >= len(result) {
-			dummySymbol = 0
-			if dummySymbol == firstNonZero {
-				dummySymbol = 1
-			}
-		}
-		if dummySymbol == firstNonZero {
-			for i := range result {
-				if i != firstNonZero && result[i] == 0 {
-					dummySymbol = i
-					break
-				}
-			}
-		}
-		if dummySymbol < len(result) && dummySymbol != firstNonZero {
-			result[dummySymbol] = 1
+		// Pad with symbol 0, or 1 when 0 is the one in use. Both are valid in every
+		// DEFLATE alphabet, whereas firstNonZero+1 could be the unused literal/length
+		// code 286. Low symbols also keep the header's HLIT/HDIST counts small.
+		dummySymbol := 0
+		if firstNonZero == 0 {
+			dummySymbol = 1
 		}
+		result[dummySymbol] = 1
 	}
 
 	return result
@@ -1,6 +1,9 @@
 package compress
 
 import (
+	"bytes"
+	"compress/flate"
+	"io"
 	"testing"
 )
 
@@ -211,3 +214,91 @@ func TestBuildDynamicTables_Structure(t *testing.T) {
 		t.Error("Expected codes in distance table, got empty")
 	}
 }
+
+func TestEnsureAtLeastTwoSymbols(t *testing.T) {
+	tests := []struct {
+		name      string
+		used      int
+		maxSymbol int
+		wantDummy int
+	}{
+		{"literal", 65, 287, 0},
+		{"symbol zero", 0, 30, 1},
+		{"last valid length code", 285, 287, 0},
+		{"last distance code", 29, 30, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			freq := make([]int, tt.maxSymbol)
+			freq[tt.used] = 7
+
+			got := ensureAtLeastTwoSymbols(freq, tt.maxSymbol)
+			for i, f := range got {
+				want := 0
+				switch i {
+				case tt.used:
+					want = 7
+				case tt.wantDummy:
+					want = 1
+				}
+				if f != want {
+					t.Errorf("freq[%d] = %d, want %d", i, f, want)
+				}
+			}
+		})
+	}
+}
+
+// kraftSum returns the Kraft sum of a table's code lengths scaled by 2^15; a
+// complete prefix code, as inflaters require, sums to exactly 1<<15.
+func kraftSum(table Table) (sum, symbols int) {
+	for _, c := range table.Codes {
+		if c.Length > 0 {
+			sum += 1 << (15 - c.Length)
+			symbols++
+		}
+	}
+	return sum, symbols
+}
+
+func TestBuildDynamicTables_OneLiteralOneMatch(t *testing.T) {
+	tokens := []Token{TokenLiteral('a'), TokenMatch(1, 10)}
+	litFreq, distFreq := countTokenFrequencies(tokens)
+	litTable, distTable := BuildDynamicTables(litFreq, distFreq)
+
+	for name, table := range map[string]Table{"literal/length": litTable, "distance": distTable} {
+		sum, symbols := kraftSum(table)
+		if symbols < 2 || sum != 1<<15 {
+			t.Errorf("%s table has %d symbols with Kraft sum %d/32768, want >= 2 symbols and a complete code", name, symbols, sum)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDynamicBlock(&buf, true, tokens); err != nil {
+		t.Fatalf("WriteDynamicBlock() error = %v", err)
+	}
+	got, err := io.ReadAll(flate.NewReader(&buf))
+	if err != nil {
+		t.Fatalf("flate decompression error = %v", err)
+	}
+	if want := bytes.Repeat([]byte("a"), 11); !bytes.Equal(got, want) {
+		t.Errorf("decompressed = %q, want %q", got, want)
+	}
+}
+
+func TestBuildDynamicTables_EOBAndOneLength(t *testing.T) {
+	litFreq := make([]int, 287)
+	litFreq[EndOfBlockSymbol] = 1
+	litFreq[260] = 5
+	distFreq := make([]int, 30)
+	distFreq[3] = 5
+
+	litTable, distTable := BuildDynamicTables(litFreq, distFreq)
+	for name, table := range map[string]Table{"literal/length": litTable, "distance": distTable} {
+		sum, symbols := kraftSum(table)
+		if symbols != 2 || sum != 1<<15 {
+			t.Errorf("%s table has %d symbols with Kraft sum %d/32768, want 2 symbols and a complete code", name, symbols, sum)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package compress
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+const (
+	gzipID1       = 0x1f
+	gzipID2       = 0x8b
+	gzipDeflate   = 8
+	gzipFlagName  = 0x08
+	gzipOSUnknown = 255
+)
+
+// GzipBytes compresses data into a complete gzip member (RFC 1952): a header
+// with the optional filename and modification time (omitted when mtime is the
+// zero time), the DEFLATE stream from EncodeAuto, then the CRC-32 and length
+// of the uncompressed data. A filename gzip cannot store, one containing NUL or
+// characters outside Latin-1, returns ErrInvalidGzipName.
+func GzipBytes(data []byte, filename string, mtime time.Time) ([]byte, error) {
+	header := []byte{gzipID1, gzipID2, gzipDeflate, 0, 0, 0, 0, 0, 0, gzipOSUnknown}
+	if !mtime.IsZero() && mtime.Unix() > 0 {
+		binary.LittleEndian.PutUint32(header[4:8], uint32(mtime.Unix()))
+	}
+	if filename != "" {
+		header[3] |= gzipFlagName
+		for _, r := range filename {
+			if r == 0 || r > 0xFF {
+				return nil, ErrInvalidGzipName
+			}
+			header = append(header, byte(r))
+		}
+		header = append(header, 0)
+	}
+
+	deflated, err := NewDeflateEncoder().EncodeAuto(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]byte, 0, len(header)+len(deflated)+8)
+	result = append(result, header...)
+	result = append(result, deflated...)
+	result = binary.LittleEndian.AppendUint32(result, CRC32(data))
+	result = binary.LittleEndian.AppendUint32(result, uint32(len(data)))
+	return result, nil
+}
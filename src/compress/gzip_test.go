@@ -0,0 +1,61 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestGzipBytes(t *testing.T) {
+	data := bytes.Repeat([]byte("gzip round trip "), 64)
+	mtime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		filename string
+		mtime    time.Time
+	}{
+		{"with name and time", data, "sprite.png", mtime},
+		{"no name", data, "", mtime},
+		{"zero time", data, "a.txt", time.Time{}},
+		{"empty data", nil, "empty", mtime},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gz, err := GzipBytes(tt.data, tt.filename, tt.mtime)
+			if err != nil {
+				t.Fatalf("GzipBytes() error = %v", err)
+			}
+
+			r, err := gzip.NewReader(bytes.NewReader(gz))
+			if err != nil {
+				t.Fatalf("gzip.NewReader() error = %v", err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("gzip decompression error = %v", err)
+			}
+			if !bytes.Equal(got, tt.data) {
+				t.Error("decompressed data does not match input")
+			}
+			if r.Name != tt.filename {
+				t.Errorf("Name = %q, want %q", r.Name, tt.filename)
+			}
+			if !r.ModTime.Equal(tt.mtime) && !(tt.mtime.IsZero() && r.ModTime.IsZero()) {
+				t.Errorf("ModTime = %v, want %v", r.ModTime, tt.mtime)
+			}
+		})
+	}
+}
+
+func TestGzipBytesInvalidName(t *testing.T) {
+	for _, name := range []string{"a\x00b", "名前"} {
+		if _, err := GzipBytes([]byte("x"), name, time.Time{}); err != ErrInvalidGzipName {
+			t.Errorf("GzipBytes(name %q) error = %v, want %v", name, err, ErrInvalidGzipName)
+		}
+	}
+}
@@ -31,6 +31,81 @@ func GenerateCodes(node *Node) map[int]Code {
 	return codes
 }
 
+// LimitCodeLengths caps the code lengths in codes at maxLength while keeping the
+// code complete. Leaves deeper than maxLength are clamped, the Kraft sum is brought
+// back to exactly one by moving leaves between levels, and the resulting lengths are
+// reassigned so symbols that had shorter codes keep shorter (or equal) codes.
+func LimitCodeLengths(codes map[int]Code, maxLength int) {
+	type symbolLength struct {
+		symbol int
+		length int
+	}
+
+	var symbols []symbolLength
+	overflow := false
+	for symbol, code := range codes {
+		if code.Length > 0 {
+			symbols = append(symbols, symbolLength{symbol: symbol, length: code.Length})
+			if code.Length > maxLength {
+				overflow = true
+			}
+		}
+	}
+	if !overflow || len(symbols) > 1<<uint(maxLength) {
+		return
+	}
+
+	blCount := make([]int, maxLength+1)
+	for _, sl := range symbols {
+		blCount[minInt(sl.length, maxLength)]++
+	}
+
+	// Kraft sum in units of 2^-maxLength; a complete code sums to exactly 1<<maxLength.
+	full := 1 << uint(maxLength)
+	kraft := 0
+	for bits := 1; bits <= maxLength; bits++ {
+		kraft += blCount[bits] << uint(maxLength-bits)
+	}
+
+	// Push leaves one level deeper until the code is no longer oversubscribed.
+	for kraft > full {
+		bits := maxLength - 1
+		for blCount[bits] == 0 {
+			bits--
+		}
+		blCount[bits]--
+		blCount[bits+1]++
+		kraft -= 1 << uint(maxLength-bits-1)
+	}
+
+	// Pull the deepest leaves up until the slack is used and the code is complete.
+	for kraft < full {
+		bits := maxLength
+		for blCount[bits] == 0 || 1<<uint(maxLength-bits) > full-kraft {
+			bits--
+		}
+		blCount[bits]--
+		blCount[bits-1]++
+		kraft += 1 << uint(maxLength-bits)
+	}
+
+	sort.Slice(symbols, func(i, j int) bool {
+		if symbols[i].length != symbols[j].length {
+			return symbols[i].length < symbols[j].length
+		}
+		return symbols[i].symbol < symbols[j].symbol
+	})
+
+	bits := 1
+	for _, sl := range symbols {
+		for blCount[bits] == 0 {
+			bits++
+		}
+		blCount[bits]--
+		codes[sl.symbol] = Code{Length: bits}
+	}
+}
+
 // Canonicalize converts code lengths to canonical Huffman codes (RFC 1951).
 // Codes are assigned in order: first by length, then by symbol value.
 // Bits are stored LSB-first (bit-reversed) for DEFLATE compatibility.
@@ -158,3 +158,74 @@ func TestBuildTreeAndCanonicalize(t *testing.T) {
 		}
 	}
 }
+
+// fibonacciFrequencies returns n Fibonacci-weighted frequencies, which produce a
+// maximally skewed Huffman tree of depth n-1.
+func fibonacciFrequencies(n int) []int {
+	freq := make([]int, n)
+	a, b := 1, 1
+	for i := range freq {
+		freq[i] = a
+		a, b = b, a+b
+	}
+	return freq
+}
+
+func TestLimitCodeLengths(t *testing.T) {
+	tests := []struct {
+		name      string
+		symbols   int
+		maxLength int
+	}{
+		{"fibonacci 25 to 15 bits", 25, 15},
+		{"fibonacci 30 to 15 bits", 30, 15},
+		{"fibonacci 19 to 7 bits", 19, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codes := GenerateCodes(BuildTree(fibonacciFrequencies(tt.symbols)))
+			deepest := 0
+			for _, code := range codes {
+				if code.Length > deepest {
+					deepest = code.Length
+				}
+			}
+			if deepest <= tt.maxLength {
+				t.Fatalf("unlimited tree depth = %d, want > %d", deepest, tt.maxLength)
+			}
+
+			LimitCodeLengths(codes, tt.maxLength)
+
+			canon, _ := Canonicalize(codes)
+			table := Table{Codes: canon, MaxLength: tt.maxLength}
+			for symbol, code := range canon {
+				if code.Length == 0 || code.Length > tt.maxLength {
+					t.Errorf("symbol %d length = %d, want 1..%d", symbol, code.Length, tt.maxLength)
+				}
+			}
+			if err := ValidateTable(table); err != nil {
+				t.Errorf("ValidateTable() = %v, want nil", err)
+			}
+
+			// Rarer symbols must never get shorter codes than more frequent ones.
+			for symbol := 1; symbol < len(canon); symbol++ {
+				if canon[symbol].Length > canon[symbol-1].Length {
+					t.Errorf("symbol %d length %d > symbol %d length %d", symbol, canon[symbol].Length, symbol-1, canon[symbol-1].Length)
+				}
+			}
+		})
+	}
+}
+
+func TestLimitCodeLengths_NoOverflowUnchanged(t *testing.T) {
+	codes := map[int]Code{0: {Length: 1}, 1: {Length: 2}, 2: {Length: 2}}
+	LimitCodeLengths(codes, 15)
+
+	want := map[int]int{0: 1, 1: 2, 2: 2}
+	for symbol, length := range want {
+		if codes[symbol].Length != length {
+			t.Errorf("symbol %d length = %d, want %d", symbol, codes[symbol].Length, length)
+		}
+	}
+}
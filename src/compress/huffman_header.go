@@ -153,6 +153,7 @@ func buildCodeLengthLengths(litLengths []int, distLengths []int) []int {
 	}
 
 	codesMap := GenerateCodes(tree)
+	LimitCodeLengths(codesMap, maxCodeLengthCodeLen)
 	codes, lengths := Canonicalize(codesMap)
 
 	if codes == nil || lengths == nil {
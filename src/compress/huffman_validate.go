@@ -0,0 +1,69 @@
+package compress
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxCodeLength is the longest Huffman code length DEFLATE allows.
+const MaxCodeLength = 15
+
+// ValidateTable checks that a Huffman table is usable by a DEFLATE decoder:
+// no code exceeds MaxCodeLength bits, the lengths satisfy the Kraft inequality,
+// and no code is a prefix of another.
+func ValidateTable(t Table) error {
+	kraft := 0
+	for _, code := range t.Codes {
+		if code.Length == 0 {
+			continue
+		}
+		if code.Length < 0 || code.Length > MaxCodeLength {
+			return ErrCodeTooLong
+		}
+		kraft += 1 << uint(MaxCodeLength-code.Length)
+	}
+	if kraft > 1<<MaxCodeLength {
+		return ErrOversubscribed
+	}
+
+	for i, a := range t.Codes {
+		if a.Length == 0 {
+			continue
+		}
+		for j := i + 1; j < len(t.Codes); j++ {
+			b := t.Codes[j]
+			if b.Length == 0 {
+				continue
+			}
+			if isCodePrefix(a, b) {
+				return ErrCodesNotPrefixed
+			}
+		}
+	}
+
+	return nil
+}
+
+// isCodePrefix reports whether the shorter of two LSB-first codes is a prefix of the other.
+func isCodePrefix(a, b Code) bool {
+	n := a.Length
+	if b.Length < n {
+		n = b.Length
+	}
+	mask := uint16(1)<<uint(n) - 1
+	return a.Bits&mask == b.Bits&mask
+}
+
+// Describe returns one line per coded symbol in the form "symbol: bits (length)",
+// with bits printed MSB-first as they appear in the code tree.
+func (t Table) Describe() string {
+	var sb strings.Builder
+	for symbol, code := range t.Codes {
+		if code.Length == 0 {
+			continue
+		}
+		msb := ReverseBits(code.Bits, code.Length)
+		fmt.Fprintf(&sb, "%d: %0*b (%d)\n", symbol, code.Length, msb, code.Length)
+	}
+	return sb.String()
+}
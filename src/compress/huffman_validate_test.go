@@ -0,0 +1,64 @@
+package compress
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateTable(t *testing.T) {
+	overfull, _ := buildTableFromLengths([]int{1, 1, 2})
+	tooLong := []Code{{Bits: 0, Length: 1}, {Bits: 1, Length: 16}}
+	notPrefixFree := []Code{{Bits: 0b01, Length: 2}, {Bits: 0b101, Length: 3}}
+
+	tests := []struct {
+		name  string
+		table Table
+		want  error
+	}{
+		{"fixed literal table", LiteralLengthTable(), nil},
+		{"fixed distance table", DistanceTable(), nil},
+		{"empty table", Table{}, nil},
+		{"over-full lengths", Table{Codes: overfull, MaxLength: 2}, ErrOversubscribed},
+		{"code too long", Table{Codes: tooLong, MaxLength: 16}, ErrCodeTooLong},
+		{"shared prefix", Table{Codes: notPrefixFree, MaxLength: 3}, ErrCodesNotPrefixed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateTable(tt.table); err != tt.want {
+				t.Errorf("ValidateTable() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTable_DynamicTables(t *testing.T) {
+	litFreq := make([]int, 286)
+	for i := range litFreq {
+		litFreq[i] = i%17 + 1
+	}
+	distFreq := []int{5, 1, 1, 3, 8, 2}
+
+	litTable, distTable := BuildDynamicTables(litFreq, distFreq)
+	if err := ValidateTable(litTable); err != nil {
+		t.Errorf("ValidateTable(litTable) = %v, want nil", err)
+	}
+	if err := ValidateTable(distTable); err != nil {
+		t.Errorf("ValidateTable(distTable) = %v, want nil", err)
+	}
+}
+
+func TestTableDescribe(t *testing.T) {
+	codes, _ := buildTableFromLengths([]int{2, 1, 3, 3})
+	got := Table{Codes: codes, MaxLength: 3}.Describe()
+	want := strings.Join([]string{
+		"0: 10 (2)",
+		"1: 0 (1)",
+		"2: 110 (3)",
+		"3: 111 (3)",
+	}, "\n") + "\n"
+
+	if got != want {
+		t.Errorf("Describe() = %q, want %q", got, want)
+	}
+}
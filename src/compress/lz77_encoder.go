@@ -13,6 +13,7 @@ type LZ77Encoder struct {
 	compressionLevel int
 	maxChainLen      int
 	minMatchLen      int
+	blockStart       int
 }
 
 // NewLZ77Encoder creates a new LZ77 encoder.
@@ -27,6 +28,7 @@ func NewLZ77Encoder() *LZ77Encoder {
 
 // SetCompressionLevel sets the compression level (1-9).
 // Higher levels produce better compression but are slower.
+// It also resets the minimum match length to the level's default.
 func (enc *LZ77Encoder) SetCompressionLevel(level int) {
 	if level < 1 {
 		level = 1
@@ -37,8 +39,9 @@ func (enc *LZ77Encoder) SetCompressionLevel(level int) {
 
 	switch level {
 	case 1:
+		// A 3-byte match rarely beats three literals once its distance code is paid for
 		enc.maxChainLen = 4
-		enc.minMatchLen = 3
+		enc.minMatchLen = 4
 	case 2:
 		enc.maxChainLen = 8
 		enc.minMatchLen = 3
@@ -66,10 +69,41 @@ func (enc *LZ77Encoder) SetCompressionLevel(level int) {
 	}
 }
 
+// SetMinMatch sets the shortest match the encoder emits; shorter repeats are
+// written as literals. n is clamped to the DEFLATE range 3-258. The hash still
+// covers the first 3 bytes, which every candidate of at least n bytes shares.
+func (enc *LZ77Encoder) SetMinMatch(n int) {
+	if n < minMatchLength {
+		n = minMatchLength
+	} else if n > maxMatchLength {
+		n = maxMatchLength
+	}
+	enc.minMatchLen = n
+}
+
+// SetBlockStart stops matches at or after pos (an index into the data passed to
+// Encode or EncodeFrom) from referring to data before pos, and matches before pos
+// from running past it, so a block starting there can be decoded without any
+// earlier output. Zero, the default, allows the
+// full 32K window.
+func (enc *LZ77Encoder) SetBlockStart(pos int) {
+	if pos < 0 {
+		pos = 0
+	}
+	enc.blockStart = pos
+}
+
 // Encode processes the input data and returns a sequence of tokens.
 // Tokens are either literals or matches (back-references).
 func (enc *LZ77Encoder) Encode(data []byte) []Token {
-	if len(data) == 0 {
+	return enc.EncodeFrom(data, 0)
+}
+
+// EncodeFrom encodes data[start:], treating data[:start] as history that
+// matches may refer back to (up to the 32K DEFLATE window). Only tokens for
+// data[start:] are returned. This lets a stream be encoded in pieces.
+func (enc *LZ77Encoder) EncodeFrom(data []byte, start int) []Token {
+	if len(data) == 0 || start >= len(data) {
 		return nil
 	}
 
@@ -81,8 +115,19 @@ func (enc *LZ77Encoder) Encode(data []byte) []Token {
 		enc.prev = make([]int32, len(data))
 	}
 
+	// Seed the hash chains with the history that is still within reach
+	historyStart := start - maxDistance
+	if historyStart < 0 {
+		historyStart = 0
+	}
+	for i := historyStart; i < start && i+enc.minMatchLen <= len(data); i++ {
+		h := enc.getHash(data[i : i+enc.minMatchLen])
+		enc.prev[i] = enc.head[h]
+		enc.head[h] = int32(i)
+	}
+
 	var tokens []Token
-	pos := 0
+	pos := start
 
 	for pos < len(data) {
 		remaining := len(data) - pos
@@ -130,6 +175,16 @@ func (enc *LZ77Encoder) findMatch(data []byte, pos int) (Match, bool) {
 	h := enc.getHash(data[pos : pos+enc.minMatchLen])
 	matchPos := enc.head[h]
 
+	// A match starting before the block must end at it, so it never covers
+	// bytes the block has to decode on its own
+	maxMatch := maxMatchLength
+	if pos+maxMatch > len(data) {
+		maxMatch = len(data) - pos
+	}
+	if pos < enc.blockStart && pos+maxMatch > enc.blockStart {
+		maxMatch = enc.blockStart - pos
+	}
+
 	bestLen := 0
 	var bestMatch Match
 
@@ -137,18 +192,15 @@ func (enc *LZ77Encoder) findMatch(data []byte, pos int) (Match, bool) {
 	chainLen := 0
 
 	for matchPos != -1 && chainLen < enc.maxChainLen {
+		// Chains run from newest to oldest position, so distances only grow:
+		// once one link is out of the window or before the block, every later link is too.
 		dist := pos - int(matchPos)
-		if dist > maxDistance {
+		if dist > maxDistance || (pos >= enc.blockStart && int(matchPos) < enc.blockStart) {
 			break
 		}
 
 		// Check match length
 		matchLen := 0
-		maxMatch := maxMatchLength
-		if pos+maxMatch > len(data) {
-			maxMatch = len(data) - pos
-		}
-
 		for matchLen < maxMatch && data[pos+matchLen] == data[int(matchPos)+matchLen] {
 			matchLen++
 		}
@@ -1,6 +1,10 @@
 package compress
 
 import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"math/rand"
 	"testing"
 )
 
@@ -123,3 +127,201 @@ func TestLZ77EncoderWindowUpdate(t *testing.T) {
 		}
 	}
 }
+
+func TestLZ77Encoder_EncodeFromUsesHistory(t *testing.T) {
+	data := []byte("abcdefghabcdefgh")
+	enc := NewLZ77Encoder()
+
+	tokens := enc.EncodeFrom(data, 8)
+
+	if len(tokens) != 1 || tokens[0].IsLiteral {
+		t.Fatalf("EncodeFrom tokens = %+v, want a single match", tokens)
+	}
+	if tokens[0].Match.Distance != 8 || tokens[0].Match.Length != 8 {
+		t.Errorf("match = %+v, want distance 8 length 8", tokens[0].Match)
+	}
+}
+
+func TestLZ77Encoder_SetMinMatch(t *testing.T) {
+	// "ABC" repeats (length 3) and "WXYZ" repeats (length 4).
+	data := []byte("ABC-ABC+WXYZ_WXYZ")
+
+	tests := []struct {
+		name     string
+		minMatch int
+		want     int // shortest match length allowed
+		wantAny  bool
+	}{
+		{"default 3", 3, 3, true},
+		{"4 skips ABC", 4, 4, true},
+		{"5 skips both", 5, 5, false},
+		{"below range clamps to 3", 1, 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc := NewLZ77Encoder()
+			enc.SetMinMatch(tt.minMatch)
+			tokens := enc.Encode(data)
+
+			matches := 0
+			for _, tok := range tokens {
+				if tok.IsLiteral {
+					continue
+				}
+				matches++
+				if int(tok.Match.Length) < tt.want {
+					t.Errorf("Match.Length = %d, want >= %d", tok.Match.Length, tt.want)
+				}
+			}
+			if (matches > 0) != tt.wantAny {
+				t.Errorf("found %d matches, want any = %v", matches, tt.wantAny)
+			}
+		})
+	}
+}
+
+func TestLZ77Encoder_LevelOneMinMatch(t *testing.T) {
+	enc := NewLZ77Encoder()
+	enc.SetCompressionLevel(1)
+	for _, tok := range enc.Encode([]byte("ABC-ABC-ABC")) {
+		if !tok.IsLiteral && tok.Match.Length < 4 {
+			t.Errorf("level 1 Match.Length = %d, want >= 4", tok.Match.Length)
+		}
+	}
+
+	enc.SetCompressionLevel(6)
+	if enc.minMatchLen != minMatchLength {
+		t.Errorf("minMatchLen after SetCompressionLevel(6) = %d, want %d", enc.minMatchLen, minMatchLength)
+	}
+}
+
+func TestLZ77Encoder_MaxDistanceBoundary(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const repeat = 16
+
+	tests := []struct {
+		name     string
+		distance int
+		want     bool
+	}{
+		{"exactly 32768", maxDistance, true},
+		{"one past the window", maxDistance + 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := make([]byte, tt.distance+repeat)
+			rng.Read(data)
+			copy(data[tt.distance:], data[:repeat])
+
+			enc := NewLZ77Encoder()
+			enc.SetCompressionLevel(9)
+			tokens := enc.Encode(data)
+
+			found := false
+			for _, tok := range tokens {
+				if !tok.IsLiteral && int(tok.Match.Distance) == tt.distance && tok.Match.Length == repeat {
+					found = true
+				}
+			}
+			if found != tt.want {
+				t.Errorf("match at distance %d found = %v, want %v", tt.distance, found, tt.want)
+			}
+
+			var buf bytes.Buffer
+			if err := WriteDynamicBlock(&buf, true, tokens); err != nil {
+				t.Fatalf("WriteDynamicBlock() error = %v", err)
+			}
+			got, err := io.ReadAll(flate.NewReader(&buf))
+			if err != nil {
+				t.Fatalf("flate decompression error = %v", err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Error("decompressed data does not match input")
+			}
+		})
+	}
+}
+
+func TestLZ77Encoder_SetBlockStart(t *testing.T) {
+	// The second half repeats the first; with a boundary at 16 none of it may
+	// be copied from before the boundary.
+	data := []byte("0123456789abcdef0123456789abcdef0123")
+	const blockStart = 16
+
+	enc := NewLZ77Encoder()
+	enc.SetBlockStart(blockStart)
+	tokens := enc.Encode(data)
+
+	pos := 0
+	matches := 0
+	for _, tok := range tokens {
+		if tok.IsLiteral {
+			pos++
+			continue
+		}
+		matches++
+		if pos >= blockStart && pos-int(tok.Match.Distance) < blockStart {
+			t.Errorf("match at %d has distance %d, reaching before block start %d", pos, tok.Match.Distance, blockStart)
+		}
+		pos += int(tok.Match.Length)
+	}
+	// "0123" at 32 repeats in-block data at 16.
+	if matches == 0 {
+		t.Error("expected an in-block match")
+	}
+
+	enc.SetBlockStart(0)
+	tokens = enc.Encode(data)
+	if len(tokens) <= blockStart || tokens[blockStart].IsLiteral || tokens[blockStart].Match.Distance != blockStart {
+		t.Errorf("without a block start, token %d should match 16 bytes back, tokens = %+v", blockStart, tokens)
+	}
+}
+
+func TestLZ77Encoder_SetBlockStartEndsEarlierMatches(t *testing.T) {
+	// A run of one byte would otherwise be covered by a single match from
+	// position 1 straight across the boundary.
+	data := bytes.Repeat([]byte{'a'}, 80)
+	const blockStart = 16
+
+	enc := NewLZ77Encoder()
+	enc.SetBlockStart(blockStart)
+	pos := 0
+	for _, tok := range enc.Encode(data) {
+		if tok.IsLiteral {
+			pos++
+			continue
+		}
+		if end := pos + int(tok.Match.Length); pos < blockStart && end > blockStart {
+			t.Errorf("match at %d runs to %d, crossing block start %d", pos, end, blockStart)
+		}
+		pos += int(tok.Match.Length)
+	}
+	if pos != len(data) {
+		t.Errorf("tokens cover %d bytes, want %d", pos, len(data))
+	}
+}
+
+func BenchmarkLZ77(b *testing.B) {
+	random := make([]byte, 64*1024)
+	rand.New(rand.NewSource(1)).Read(random)
+	inputs := []struct {
+		name string
+		data []byte
+	}{
+		{"repetitive", bytes.Repeat([]byte("0123456789abcdef0123456789ABCDEF"), 2048)},
+		{"random", random},
+	}
+
+	for _, in := range inputs {
+		b.Run(in.name, func(b *testing.B) {
+			enc := NewLZ77Encoder()
+			b.SetBytes(int64(len(in.data)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				enc.Encode(in.data)
+			}
+		})
+	}
+}
@@ -98,3 +98,36 @@ func StoredBlockBytes(data []byte, final bool) ([]byte, error) {
 
 	return result, nil
 }
+
+// maxStoredBlockSize is the largest payload a single stored block can hold (LEN is 16 bits).
+const maxStoredBlockSize = 65535
+
+// WriteStoredBlocks writes data of any size as a sequence of stored blocks of at most
+// 65535 bytes each. Only the last block carries BFINAL, and only if final is true.
+// Empty data is written as a single empty block.
+func WriteStoredBlocks(w io.Writer, data []byte, final bool) error {
+	for {
+		n := len(data)
+		if n > maxStoredBlockSize {
+			n = maxStoredBlockSize
+		}
+		last := n == len(data)
+
+		if err := WriteStoredBlock(w, final && last, data[:n]); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+		data = data[n:]
+	}
+}
+
+// StoredBlocksSize returns the number of bytes WriteStoredBlocks produces for n bytes of data.
+func StoredBlocksSize(n int) int {
+	blocks := (n + maxStoredBlockSize - 1) / maxStoredBlockSize
+	if blocks == 0 {
+		blocks = 1
+	}
+	return n + 5*blocks
+}
@@ -2,7 +2,9 @@ package compress
 
 import (
 	"bytes"
+	"compress/flate"
 	"encoding/binary"
+	"io"
 	"testing"
 )
 
@@ -329,3 +331,83 @@ func TestStoredBlockBytes_onesComplement(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteStoredBlocks_MultipleBlocks(t *testing.T) {
+	data := make([]byte, 200000)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteStoredBlocks(&buf, data, true); err != nil {
+		t.Fatalf("WriteStoredBlocks failed: %v", err)
+	}
+
+	if buf.Len() != StoredBlocksSize(len(data)) {
+		t.Errorf("output length = %d, want %d", buf.Len(), StoredBlocksSize(len(data)))
+	}
+
+	// 200000 bytes = 3 full blocks + 1 partial block
+	out := buf.Bytes()
+	offset := 0
+	blocks := 0
+	for offset < len(out) {
+		header := out[offset]
+		length := int(binary.LittleEndian.Uint16(out[offset+1 : offset+3]))
+		blocks++
+		offset += 5 + length
+		isLast := offset == len(out)
+		if (header == 0x01) != isLast {
+			t.Errorf("block %d header = 0x%02X, last = %v", blocks, header, isLast)
+		}
+	}
+	if blocks != 4 {
+		t.Errorf("block count = %d, want 4", blocks)
+	}
+
+	got, err := io.ReadAll(flate.NewReader(bytes.NewReader(out)))
+	if err != nil {
+		t.Fatalf("flate decompression failed: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("decompressed data mismatch")
+	}
+}
+
+func TestWriteStoredBlocks_NotFinal(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteStoredBlocks(&buf, []byte{1, 2, 3}, false); err != nil {
+		t.Fatalf("WriteStoredBlocks failed: %v", err)
+	}
+	if buf.Bytes()[0] != 0x00 {
+		t.Errorf("header = 0x%02X, want 0x00", buf.Bytes()[0])
+	}
+}
+
+func TestWriteStoredBlocks_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteStoredBlocks(&buf, nil, true); err != nil {
+		t.Fatalf("WriteStoredBlocks failed: %v", err)
+	}
+	want := []byte{0x01, 0x00, 0x00, 0xFF, 0xFF}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("got % x, want % x", buf.Bytes(), want)
+	}
+}
+
+func TestStoredBlocksSize(t *testing.T) {
+	tests := []struct {
+		n    int
+		want int
+	}{
+		{0, 5},
+		{1, 6},
+		{65535, 65540},
+		{65536, 65546},
+	}
+	for _, tt := range tests {
+		if got := StoredBlocksSize(tt.n); got != tt.want {
+			t.Errorf("StoredBlocksSize(%d) = %d, want %d", tt.n, got, tt.want)
+		}
+	}
+}
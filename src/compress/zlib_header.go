@@ -44,23 +44,32 @@ func WriteCMF(w io.Writer, windowSize int) error {
 	return err
 }
 
+// ZlibLevel maps a DEFLATE compression level (1-9) onto the 2-bit zlib FLEVEL
+// field used by WriteFLG, following zlib's own convention:
+//   - 0: fastest (level 1 or below)
+//   - 1: fast (levels 2-5)
+//   - 2: default (level 6)
+//   - 3: maximum compression (levels 7-9)
+func ZlibLevel(compressionLevel int) uint8 {
+	switch {
+	case compressionLevel <= 1:
+		return 0
+	case compressionLevel <= 5:
+		return 1
+	case compressionLevel == 6:
+		return 2
+	default:
+		return 3
+	}
+}
+
 func WriteFLG(w io.Writer, cmf byte, level uint8) error {
 	if level > 3 {
 		return ErrInvalidCompressionLevel
 	}
 
-	fdict := uint8(0)
-	flevel := level & 3
-	base := (flevel << 6) | ((fdict & 1) << 5)
-
-	fcheck := 31 - ((int(cmf)*256 + int(base)) % 31)
-	if fcheck == 31 {
-		fcheck = 0
-	}
-
-	flg := base | uint8(fcheck)
 	var buf [1]byte
-	buf[0] = flg
+	buf[0] = flgByte(cmf, level, false)
 	_, err := w.Write(buf[:])
 	return err
 }
@@ -87,18 +96,43 @@ func ZlibHeaderBytes(windowSize int, level uint8) ([]byte, error) {
 		return nil, err
 	}
 	buf[0] = cmf
+	buf[1] = flgByte(cmf, level, false)
+	return buf[:], nil
+}
+
+// ZlibDictHeaderBytes returns a zlib header for a stream compressed against the
+// preset dictionary dict: CMF, FLG with the FDICT bit set, and DICTID (the
+// Adler32 of dict). A decoder must be given the same dictionary to inflate it.
+func ZlibDictHeaderBytes(windowSize int, level uint8, dict []byte) ([]byte, error) {
+	if level > 3 {
+		return nil, ErrInvalidCompressionLevel
+	}
+
+	cmf, err := cmfByte(windowSize)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 6)
+	buf[0] = cmf
+	buf[1] = flgByte(cmf, level, true)
+	binary.BigEndian.PutUint32(buf[2:], Adler32(dict))
+	return buf, nil
+}
 
-	fdict := uint8(0)
-	flevel := level & 3
-	base := (flevel << 6) | ((fdict & 1) << 5)
+// flgByte builds the FLG byte: FLEVEL, FDICT, and the FCHECK bits that make
+// CMF*256+FLG a multiple of 31.
+func flgByte(cmf byte, level uint8, fdict bool) byte {
+	base := (level & 3) << 6
+	if fdict {
+		base |= 1 << 5
+	}
 
 	fcheck := 31 - ((int(cmf)*256 + int(base)) % 31)
 	if fcheck == 31 {
 		fcheck = 0
 	}
-
-	buf[1] = base | uint8(fcheck)
-	return buf[:], nil
+	return base | uint8(fcheck)
 }
 
 func ZlibFooterBytes(checksum uint32) [4]byte {
@@ -2,6 +2,7 @@ package compress
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	"math/bits"
 	"testing"
@@ -167,3 +168,60 @@ func TestZlibHeaderBytesInvalidLevel(t *testing.T) {
 		})
 	}
 }
+
+func TestZlibLevel(t *testing.T) {
+	tests := []struct {
+		level int
+		want  uint8
+	}{
+		{0, 0},
+		{1, 0},
+		{2, 1},
+		{5, 1},
+		{6, 2},
+		{7, 3},
+		{9, 3},
+		{12, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("level=%d", tt.level), func(t *testing.T) {
+			got := ZlibLevel(tt.level)
+			if got != tt.want {
+				t.Fatalf("ZlibLevel(%d) = %d, want %d", tt.level, got, tt.want)
+			}
+
+			header, err := ZlibHeaderBytes(32768, got)
+			if err != nil {
+				t.Fatalf("ZlibHeaderBytes() failed: %v", err)
+			}
+			if (int(header[0])*256+int(header[1]))%31 != 0 {
+				t.Fatalf("header 0x%02X%02X not divisible by 31", header[0], header[1])
+			}
+		})
+	}
+}
+
+func TestZlibDictHeaderBytes(t *testing.T) {
+	dict := []byte("preset dictionary")
+	header, err := ZlibDictHeaderBytes(32768, 2, dict)
+	if err != nil {
+		t.Fatalf("ZlibDictHeaderBytes() error = %v", err)
+	}
+	if len(header) != 6 {
+		t.Fatalf("len(header) = %d, want 6", len(header))
+	}
+	if header[1]&0x20 == 0 {
+		t.Errorf("FLG = 0x%02X, want FDICT bit set", header[1])
+	}
+	if (int(header[0])*256+int(header[1]))%31 != 0 {
+		t.Errorf("header 0x%02X%02X not divisible by 31", header[0], header[1])
+	}
+	if got, want := binary.BigEndian.Uint32(header[2:]), Adler32(dict); got != want {
+		t.Errorf("DICTID = 0x%08X, want 0x%08X", got, want)
+	}
+
+	if _, err := ZlibDictHeaderBytes(32768, 4, dict); err != ErrInvalidCompressionLevel {
+		t.Errorf("ZlibDictHeaderBytes(level=4) error = %v, want %v", err, ErrInvalidCompressionLevel)
+	}
+}
@@ -1,5 +1,7 @@
 package png
 
+import "fmt"
+
 func HasAlpha(pixels []byte, colorType ColorType) bool {
 	if colorType != ColorRGBA {
 		return false
@@ -13,6 +15,38 @@ func HasAlpha(pixels []byte, colorType ColorType) bool {
 	return false
 }
 
+// IsFullyTransparent reports whether every pixel of an RGBA image has alpha 0.
+// It returns false for other color types and for empty input.
+func IsFullyTransparent(pixels []byte, colorType ColorType) bool {
+	if colorType != ColorRGBA || len(pixels) < 4 {
+		return false
+	}
+	for i := 3; i < len(pixels); i += 4 {
+		if pixels[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ExtractAlphaChannel returns the alpha samples of RGBA pixels as a grayscale
+// buffer, one byte per pixel, ready to encode with ColorGrayscale. Other color
+// types have no alpha channel and return an error.
+func ExtractAlphaChannel(pixels []byte, colorType ColorType) ([]byte, error) {
+	if colorType != ColorRGBA {
+		return nil, fmt.Errorf("png: %s has no alpha channel", colorType)
+	}
+	if len(pixels)%4 != 0 {
+		return nil, fmt.Errorf("png: RGBA pixel data length %d is not a multiple of 4", len(pixels))
+	}
+
+	alpha := make([]byte, len(pixels)/4)
+	for i := range alpha {
+		alpha[i] = pixels[i*4+3]
+	}
+	return alpha, nil
+}
+
 func OptimizeAlpha(pixels []byte, colorType ColorType) []byte {
 	if colorType != ColorRGBA {
 		return pixels
@@ -30,3 +64,44 @@ func OptimizeAlpha(pixels []byte, colorType ColorType) []byte {
 	}
 	return result
 }
+
+// FlattenAlpha composites RGBA pixels over a solid background color using the
+// "over" operator and returns RGB pixels (3 bytes per pixel).
+func FlattenAlpha(pixels []byte, width, height int, bg Color) []byte {
+	count := width * height
+	if count <= 0 || len(pixels) < count*4 {
+		return []byte{}
+	}
+
+	result := make([]byte, count*3)
+	for i := 0; i < count; i++ {
+		src := pixels[i*4 : i*4+4]
+		a := int(src[3])
+		dst := result[i*3 : i*3+3]
+		dst[0] = blendOver(src[0], bg.R, a)
+		dst[1] = blendOver(src[1], bg.G, a)
+		dst[2] = blendOver(src[2], bg.B, a)
+	}
+	return result
+}
+
+// blendOver mixes a foreground channel with alpha a (0-255) over a background channel, rounding.
+func blendOver(fg, bg uint8, a int) uint8 {
+	return uint8((int(fg)*a + int(bg)*(255-a) + 127) / 255)
+}
+
+// HasUniformAlpha reports whether every pixel of RGBA data has the same alpha
+// value, and returns that value. It returns false for empty input.
+func HasUniformAlpha(pixels []byte) (uint8, bool) {
+	if len(pixels) < 4 {
+		return 0, false
+	}
+
+	alpha := pixels[3]
+	for i := 7; i < len(pixels); i += 4 {
+		if pixels[i] != alpha {
+			return 0, false
+		}
+	}
+	return alpha, true
+}
@@ -1,6 +1,7 @@
 package png
 
 import (
+	"bytes"
 	"testing"
 )
 
@@ -109,3 +110,157 @@ func TestOptimizeAlpha(t *testing.T) {
 		}
 	})
 }
+
+func TestFlattenAlpha(t *testing.T) {
+	white := Color{255, 255, 255}
+	tests := []struct {
+		name   string
+		pixels []byte
+		bg     Color
+		want   []byte
+	}{
+		{"half red over white", []byte{255, 0, 0, 128}, white, []byte{255, 127, 127}},
+		{"opaque unchanged", []byte{10, 20, 30, 255}, white, []byte{10, 20, 30}},
+		{"transparent shows background", []byte{10, 20, 30, 0}, Color{1, 2, 3}, []byte{1, 2, 3}},
+		{"quarter green over black", []byte{0, 200, 0, 64}, Color{}, []byte{0, 50, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FlattenAlpha(tt.pixels, 1, 1, tt.bg)
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("FlattenAlpha() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeFlattenBackground(t *testing.T) {
+	pixels := []byte{
+		255, 0, 0, 128, 0, 0, 255, 255,
+	}
+	opts := FastOptions(2, 1)
+	opts.FlattenBackground = &Color{255, 255, 255}
+
+	data, err := EncodeWithOptions(pixels, opts)
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+
+	if ihdr := findFirstChunk(t, parsePNGChunks(t, data), "IHDR"); ColorType(ihdr.Data[9]) != ColorRGB {
+		t.Errorf("IHDR color type = %v, want %v", ColorType(ihdr.Data[9]), ColorRGB)
+	}
+	assertDecodedPixels(t, data, 2, 1, ColorRGB, []byte{255, 127, 127, 0, 0, 255})
+}
+
+func TestExtractAlphaChannel(t *testing.T) {
+	width, height := 16, 4
+	rgba := make([]byte, width*height*4)
+	want := make([]byte, width*height)
+	for i := range want {
+		want[i] = uint8(i % width * 17)
+		rgba[i*4], rgba[i*4+1], rgba[i*4+2], rgba[i*4+3] = 9, 8, 7, want[i]
+	}
+
+	mask, err := ExtractAlphaChannel(rgba, ColorRGBA)
+	if err != nil {
+		t.Fatalf("ExtractAlphaChannel() error = %v", err)
+	}
+	if !bytes.Equal(mask, want) {
+		t.Errorf("ExtractAlphaChannel() = %v, want %v", mask, want)
+	}
+
+	encoder, err := NewEncoder(width, height, ColorGrayscale)
+	if err != nil {
+		t.Fatalf("NewEncoder() error = %v", err)
+	}
+	data, err := encoder.Encode(mask)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	img, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if img.ColorType != ColorGrayscale || !bytes.Equal(img.Pixels, want) {
+		t.Errorf("decoded mask = %s %v, want grayscale %v", img.ColorType, img.Pixels, want)
+	}
+
+	for _, ct := range []ColorType{ColorGrayscale, ColorRGB} {
+		if _, err := ExtractAlphaChannel(make([]byte, 12), ct); err == nil {
+			t.Errorf("ExtractAlphaChannel(%s) error = nil, want error", ct)
+		}
+	}
+	if _, err := ExtractAlphaChannel(make([]byte, 6), ColorRGBA); err == nil {
+		t.Error("ExtractAlphaChannel() with a partial pixel error = nil, want error")
+	}
+}
+
+func TestIsFullyTransparent(t *testing.T) {
+	tests := []struct {
+		name      string
+		pixels    []byte
+		colorType ColorType
+		want      bool
+	}{
+		{"all transparent", []byte{10, 20, 30, 0, 0, 0, 0, 0}, ColorRGBA, true},
+		{"one visible pixel", []byte{0, 0, 0, 0, 0, 0, 0, 1}, ColorRGBA, false},
+		{"RGB", []byte{0, 0, 0}, ColorRGB, false},
+		{"empty", []byte{}, ColorRGBA, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsFullyTransparent(tt.pixels, tt.colorType); got != tt.want {
+				t.Errorf("IsFullyTransparent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeFullyTransparent(t *testing.T) {
+	width, height := 100, 100
+	pixels := make([]byte, width*height*4)
+	for i := 0; i < len(pixels); i += 4 {
+		pixels[i] = uint8(i)
+	}
+	opts := BalancedOptions(width, height)
+
+	data, err := EncodeWithOptions(pixels, opts)
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+
+	if len(data) > 300 {
+		t.Errorf("encoded size = %d bytes, want <= 300", len(data))
+	}
+	chunks := parsePNGChunks(t, data)
+	if ihdr := findFirstChunk(t, chunks, "IHDR"); ColorType(ihdr.Data[9]) != ColorIndexed {
+		t.Errorf("IHDR color type = %v, want %v", ColorType(ihdr.Data[9]), ColorIndexed)
+	}
+	findFirstChunk(t, chunks, "tRNS")
+	assertDecodedPixels(t, data, width, height, ColorRGBA, make([]byte, width*height*4))
+}
+
+func TestHasUniformAlpha(t *testing.T) {
+	tests := []struct {
+		name      string
+		pixels    []byte
+		wantAlpha uint8
+		wantOK    bool
+	}{
+		{"uniform 200", []byte{1, 2, 3, 200, 4, 5, 6, 200}, 200, true},
+		{"opaque", []byte{1, 2, 3, 255}, 255, true},
+		{"mixed", []byte{1, 2, 3, 200, 4, 5, 6, 201}, 0, false},
+		{"empty", nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alpha, ok := HasUniformAlpha(tt.pixels)
+			if alpha != tt.wantAlpha || ok != tt.wantOK {
+				t.Errorf("HasUniformAlpha() = (%d, %v), want (%d, %v)", alpha, ok, tt.wantAlpha, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package png
+
+import "fmt"
+
+// AtlasEntry is one image to pack with EncodeAtlas.
+type AtlasEntry struct {
+	Pixels    []byte
+	Width     int
+	Height    int
+	ColorType ColorType // ColorGrayscale, ColorRGB, or ColorRGBA
+}
+
+// AtlasRect is where an entry was placed on the atlas canvas, in pixels.
+type AtlasRect struct {
+	X, Y          int
+	Width, Height int
+}
+
+// Layout describes a packed atlas: the canvas size and one rectangle per
+// entry, in the order the entries were given.
+type Layout struct {
+	Width  int
+	Height int
+	Rects  []AtlasRect
+}
+
+// EncodeAtlas packs images into a single RGBA PNG using shelf packing: entries
+// are placed left to right in the order given, and when opts.Width is set, an
+// entry that would cross that width starts a new shelf below the tallest image
+// of the current one. With opts.Width zero every entry goes on one row. Any
+// canvas area no entry covers is fully transparent. opts.Height and
+// opts.ColorType are replaced by the canvas size and ColorRGBA; opts.Width is
+// replaced by the packed width. Each entry and the canvas are limited to
+// opts.MaxPixels, checked before the canvas is allocated.
+func EncodeAtlas(images []AtlasEntry, opts Options) ([]byte, Layout, error) {
+	layout, err := packAtlas(images, opts.Width, opts.MaxPixels)
+	if err != nil {
+		return nil, Layout{}, err
+	}
+	if err := checkImageSize(layout.Width, layout.Height, opts.MaxPixels); err != nil {
+		return nil, Layout{}, err
+	}
+
+	canvas := make([]byte, layout.Width*layout.Height*4)
+	for i, img := range images {
+		blitRGBA(canvas, layout.Width, layout.Rects[i], img)
+	}
+
+	opts.Width = layout.Width
+	opts.Height = layout.Height
+	opts.ColorType = ColorRGBA
+	opts.RowStride = 0
+	opts.InputBitDepth = 0
+	opts.InputIsBGRA = false
+	encoder, err := NewEncoderWithOptions(opts)
+	if err != nil {
+		return nil, Layout{}, err
+	}
+	data, err := encoder.Encode(canvas)
+	if err != nil {
+		return nil, Layout{}, err
+	}
+	return data, layout, nil
+}
+
+// packAtlas validates images and places them on shelves no wider than
+// maxWidth, or on a single row if maxWidth is zero. Each entry must fit within
+// maxPixels (see checkImageSize).
+func packAtlas(images []AtlasEntry, maxWidth, maxPixels int) (Layout, error) {
+	if len(images) == 0 {
+		return Layout{}, ErrEmptyPixels
+	}
+	if maxWidth < 0 {
+		return Layout{}, ErrInvalidDimensions
+	}
+
+	layout := Layout{Rects: make([]AtlasRect, len(images))}
+	x, y, shelfHeight := 0, 0, 0
+	for i, img := range images {
+		if img.Width <= 0 || img.Height <= 0 {
+			return Layout{}, fmt.Errorf("png: atlas entry %d: %w", i, ErrInvalidDimensions)
+		}
+		if err := checkImageSize(img.Width, img.Height, maxPixels); err != nil {
+			return Layout{}, fmt.Errorf("png: atlas entry %d: %w", i, err)
+		}
+		switch img.ColorType {
+		case ColorGrayscale, ColorRGB, ColorRGBA:
+		default:
+			return Layout{}, fmt.Errorf("png: atlas entry %d: unsupported ColorType %s", i, img.ColorType)
+		}
+		if want := img.Width * img.Height * BytesPerPixel(img.ColorType); len(img.Pixels) != want {
+			return Layout{}, fmt.Errorf("png: atlas entry %d: pixel count mismatch: got %d bytes, want %d", i, len(img.Pixels), want)
+		}
+		if maxWidth > 0 && img.Width > maxWidth {
+			return Layout{}, fmt.Errorf("png: atlas entry %d is %d pixels wide, wider than the atlas width %d", i, img.Width, maxWidth)
+		}
+
+		if maxWidth > 0 && x+img.Width > maxWidth {
+			x, y, shelfHeight = 0, y+shelfHeight, 0
+		}
+		layout.Rects[i] = AtlasRect{X: x, Y: y, Width: img.Width, Height: img.Height}
+		x += img.Width
+		if img.Height > shelfHeight {
+			shelfHeight = img.Height
+		}
+		if x > layout.Width {
+			layout.Width = x
+		}
+	}
+	layout.Height = y + shelfHeight
+	return layout, nil
+}
+
+// blitRGBA copies img into rect of an RGBA canvas canvasWidth pixels wide,
+// expanding grayscale and RGB samples to opaque RGBA.
+func blitRGBA(canvas []byte, canvasWidth int, rect AtlasRect, img AtlasEntry) {
+	bpp := BytesPerPixel(img.ColorType)
+	for y := 0; y < rect.Height; y++ {
+		src := img.Pixels[y*rect.Width*bpp : (y+1)*rect.Width*bpp]
+		dst := canvas[((rect.Y+y)*canvasWidth+rect.X)*4:]
+		if bpp == 4 {
+			copy(dst, src)
+			continue
+		}
+		for x := 0; x < rect.Width; x++ {
+			px := src[x*bpp : (x+1)*bpp]
+			if bpp == 1 {
+				dst[x*4], dst[x*4+1], dst[x*4+2] = px[0], px[0], px[0]
+			} else {
+				dst[x*4], dst[x*4+1], dst[x*4+2] = px[0], px[1], px[2]
+			}
+			dst[x*4+3] = 255
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package png
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	stdpng "image/png"
+	"testing"
+)
+
+func TestEncodeAtlas(t *testing.T) {
+	images := []AtlasEntry{
+		{Pixels: []byte{10, 20, 30, 40}, Width: 2, Height: 2, ColorType: ColorGrayscale},
+		{Pixels: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 11, 12, 13, 14, 15, 16, 17, 18, 19}, Width: 3, Height: 2, ColorType: ColorRGB},
+		{Pixels: []byte{200, 0, 0, 128, 0, 200, 0, 0, 0, 0, 200, 255}, Width: 1, Height: 3, ColorType: ColorRGBA},
+	}
+
+	tests := []struct {
+		name      string
+		width     int
+		wantRects []AtlasRect
+		wantSize  [2]int
+	}{
+		{"row", 0, []AtlasRect{{0, 0, 2, 2}, {2, 0, 3, 2}, {5, 0, 1, 3}}, [2]int{6, 3}},
+		{"shelves", 5, []AtlasRect{{0, 0, 2, 2}, {2, 0, 3, 2}, {0, 2, 1, 3}}, [2]int{5, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := FastOptions(tt.width, 0)
+			data, layout, err := EncodeAtlas(images, opts)
+			if err != nil {
+				t.Fatalf("EncodeAtlas() error = %v", err)
+			}
+			if layout.Width != tt.wantSize[0] || layout.Height != tt.wantSize[1] {
+				t.Errorf("layout size = %dx%d, want %dx%d", layout.Width, layout.Height, tt.wantSize[0], tt.wantSize[1])
+			}
+			for i, want := range tt.wantRects {
+				if layout.Rects[i] != want {
+					t.Errorf("Rects[%d] = %+v, want %+v", i, layout.Rects[i], want)
+				}
+			}
+
+			img, err := stdpng.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("image/png.Decode() error = %v", err)
+			}
+			covered := make(map[[2]int]bool)
+			for i, entry := range images {
+				rect := layout.Rects[i]
+				bpp := BytesPerPixel(entry.ColorType)
+				for y := 0; y < rect.Height; y++ {
+					for x := 0; x < rect.Width; x++ {
+						px := entry.Pixels[(y*rect.Width+x)*bpp:]
+						want := color.NRGBA{px[0], px[0], px[0], 255}
+						if bpp >= 3 {
+							want.G, want.B = px[1], px[2]
+						}
+						if bpp == 4 {
+							want.A = px[3]
+						}
+						got := color.NRGBAModel.Convert(img.At(rect.X+x, rect.Y+y)).(color.NRGBA)
+						if got != want {
+							t.Errorf("entry %d pixel (%d,%d) = %v, want %v", i, x, y, got, want)
+						}
+						covered[[2]int{rect.X + x, rect.Y + y}] = true
+					}
+				}
+			}
+			for y := 0; y < layout.Height; y++ {
+				for x := 0; x < layout.Width; x++ {
+					if _, _, _, a := img.At(x, y).RGBA(); !covered[[2]int{x, y}] && a != 0 {
+						t.Errorf("gutter pixel (%d,%d) alpha = %d, want 0", x, y, a)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeAtlasErrors(t *testing.T) {
+	valid := AtlasEntry{Pixels: []byte{1, 2, 3}, Width: 1, Height: 1, ColorType: ColorRGB}
+
+	tests := []struct {
+		name   string
+		images []AtlasEntry
+		width  int
+		want   error // checked with errors.Is when set
+	}{
+		{"no images", nil, 0, nil},
+		{"zero size", []AtlasEntry{{Width: 0, Height: 1, ColorType: ColorRGB}}, 0, nil},
+		{"short pixels", []AtlasEntry{{Pixels: []byte{1}, Width: 1, Height: 1, ColorType: ColorRGB}}, 0, nil},
+		{"indexed", []AtlasEntry{{Pixels: []byte{1}, Width: 1, Height: 1, ColorType: ColorIndexed}}, 0, nil},
+		{"wider than atlas", []AtlasEntry{valid, {Pixels: make([]byte, 9), Width: 3, Height: 1, ColorType: ColorRGB}}, 2, nil},
+		{"entry too large", []AtlasEntry{{Width: 1 << 20, Height: 1 << 20, ColorType: ColorRGB}}, 0, ErrImageTooLarge},
+		{"canvas too large", []AtlasEntry{
+			{Pixels: make([]byte, 100000), Width: 100000, Height: 1, ColorType: ColorGrayscale},
+			{Pixels: make([]byte, 100000), Width: 1, Height: 100000, ColorType: ColorGrayscale},
+		}, 0, ErrImageTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := EncodeAtlas(tt.images, FastOptions(tt.width, 0))
+			if err == nil {
+				t.Error("EncodeAtlas() error = nil, want error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("EncodeAtlas() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
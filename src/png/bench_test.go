@@ -0,0 +1,127 @@
+package png
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// benchPixels returns a deterministic photo-like image: smooth gradients with a
+// little noise, so filters and LZ77 see realistic structure.
+func benchPixels(width, height, bpp int) []byte {
+	rng := rand.New(rand.NewSource(42))
+	pixels := make([]byte, width*height*bpp)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			off := (y*width + x) * bpp
+			for c := 0; c < bpp; c++ {
+				pixels[off+c] = uint8(x*(c+1)+y*(3-c)) + uint8(rng.Intn(6))
+			}
+			if bpp == 4 {
+				pixels[off+3] = 255
+			}
+		}
+	}
+	return pixels
+}
+
+func BenchmarkEncodeRGBA(b *testing.B) {
+	width, height := 256, 256
+	pixels := benchPixels(width, height, 4)
+	encoder, err := NewEncoderWithOptions(BalancedOptions(width, height))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(int64(len(pixels)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := encoder.Encode(pixels); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeGrayscale(b *testing.B) {
+	width, height := 256, 256
+	pixels := benchPixels(width, height, 1)
+	opts := BalancedOptions(width, height)
+	opts.ColorType = ColorGrayscale
+	encoder, err := NewEncoderWithOptions(opts)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(int64(len(pixels)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := encoder.Encode(pixels); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkQuantize256(b *testing.B) {
+	pixels := benchPixels(128, 128, 3)
+
+	b.SetBytes(int64(len(pixels)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Quantize(pixels, int(ColorRGB), 256)
+	}
+}
+
+func BenchmarkFloydSteinberg2D(b *testing.B) {
+	width, height := 128, 128
+	pixels := benchPixels(width, height, 3)
+	_, palette := Quantize(pixels, int(ColorRGB), 64)
+
+	b.SetBytes(int64(len(pixels)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		FloydSteinberg2D(pixels, width, height, palette)
+	}
+}
+
+func BenchmarkQuantizeToPalette(b *testing.B) {
+	width, height := 1024, 1024
+	pixels := benchPixels(width, height, 3)
+	palette := ExtractPalette(pixels, int(ColorRGB), 256)
+
+	b.Run("serial", func(b *testing.B) {
+		b.SetBytes(int64(len(pixels)))
+		for i := 0; i < b.N; i++ {
+			QuantizeToPalette(pixels, int(ColorRGB), palette)
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		b.SetBytes(int64(len(pixels)))
+		for i := 0; i < b.N; i++ {
+			QuantizeToPaletteParallel(pixels, width, int(ColorRGB), palette, 0)
+		}
+	})
+}
+
+func BenchmarkFilterSubUp(b *testing.B) {
+	width, height := 2048, 64
+	pixels := benchPixels(width, height, 4)
+	rowLen := width * 4
+
+	run := func(b *testing.B) {
+		b.SetBytes(int64(len(pixels)) * 2)
+		for i := 0; i < b.N; i++ {
+			var prev []byte
+			for y := 0; y < height; y++ {
+				row := pixels[y*rowLen : (y+1)*rowLen]
+				ApplyFilterSub(row, 4)
+				ApplyFilterUp(row, prev)
+				prev = row
+			}
+		}
+	}
+	b.Run("scalar", func(b *testing.B) { withScalarFilters(func() { run(b) }) })
+	b.Run("wide", run)
+}
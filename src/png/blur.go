@@ -0,0 +1,89 @@
+package png
+
+import "math"
+
+// BoxBlur averages each sample with its neighbors within radius pixels, as a
+// horizontal pass followed by a vertical pass. Pixels beyond the image edge are
+// treated as copies of the nearest edge pixel. All channels are blurred, including
+// alpha. radius <= 0, or pixels shorter than width*height pixels, returns a copy.
+// A radius beyond max(width, height) is clamped to it, which keeps the window
+// sums from overflowing and the setup loop bounded by the image size.
+func BoxBlur(pixels []byte, width, height int, colorType ColorType, radius int) []byte {
+	bpp := BytesPerPixel(colorType)
+	result := append([]byte(nil), pixels...)
+	if radius <= 0 || width <= 0 || height <= 0 || len(pixels) < width*height*bpp {
+		return result
+	}
+	if limit := max(width, height); radius > limit {
+		radius = limit
+	}
+
+	tmp := make([]byte, width*height*bpp)
+	boxBlurPass(tmp, pixels, width, height, bpp, radius, true)
+	boxBlurPass(result, tmp, width, height, bpp, radius, false)
+	return result
+}
+
+// UnsharpMask sharpens pixels by adding back amount times the detail removed by a
+// box blur of the given radius: original + amount*(original - blur), clamped to
+// 0-255. Alpha is left unchanged. amount <= 0 or radius <= 0 returns a copy.
+func UnsharpMask(pixels []byte, width, height int, colorType ColorType, amount float64, radius int) []byte {
+	result := append([]byte(nil), pixels...)
+	if amount <= 0 || radius <= 0 {
+		return result
+	}
+
+	blurred := BoxBlur(pixels, width, height, colorType, radius)
+	bpp := BytesPerPixel(colorType)
+	channels := bpp
+	if colorType == ColorRGBA {
+		channels = 3
+	}
+	for i := 0; i+bpp <= len(blurred) && i+bpp <= len(result); i += bpp {
+		for c := 0; c < channels; c++ {
+			orig := float64(pixels[i+c])
+			v := orig + amount*(orig-float64(blurred[i+c]))
+			result[i+c] = uint8(clampInt(int(math.Round(v))))
+		}
+	}
+	return result
+}
+
+// boxBlurPass blurs src into dst along rows (horizontal) or columns, clamping at edges.
+func boxBlurPass(dst, src []byte, width, height, bpp, radius int, horizontal bool) {
+	lines, length := height, width
+	if !horizontal {
+		lines, length = width, height
+	}
+	offset := func(line, pos int) int {
+		if horizontal {
+			return (line*width + pos) * bpp
+		}
+		return (pos*width + line) * bpp
+	}
+	clampPos := func(pos int) int {
+		if pos < 0 {
+			return 0
+		}
+		if pos >= length {
+			return length - 1
+		}
+		return pos
+	}
+
+	window := 2*radius + 1
+	for line := 0; line < lines; line++ {
+		for c := 0; c < bpp; c++ {
+			// Running sum over the window centered on pos
+			sum := 0
+			for k := -radius; k <= radius; k++ {
+				sum += int(src[offset(line, clampPos(k))+c])
+			}
+			for pos := 0; pos < length; pos++ {
+				dst[offset(line, pos)+c] = uint8((sum + window/2) / window)
+				sum -= int(src[offset(line, clampPos(pos-radius))+c])
+				sum += int(src[offset(line, clampPos(pos+radius+1))+c])
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package png
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestBoxBlurSpreadsSinglePixel(t *testing.T) {
+	tests := []struct {
+		name      string
+		colorType ColorType
+	}{
+		{"RGB", ColorRGB},
+		{"RGBA", ColorRGBA},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, height := 9, 9
+			bpp := BytesPerPixel(tt.colorType)
+			pixels := make([]byte, width*height*bpp)
+			center := (4*width + 4) * bpp
+			for c := 0; c < bpp; c++ {
+				pixels[center+c] = 255
+			}
+
+			got := BoxBlur(pixels, width, height, tt.colorType, 1)
+
+			for c := 0; c < bpp; c++ {
+				total := 0
+				for i := c; i < len(got); i += bpp {
+					total += int(got[i])
+				}
+				if total < 245 || total > 265 {
+					t.Errorf("channel %d total intensity = %d, want about 255", c, total)
+				}
+			}
+			for _, xy := range [][2]int{{3, 3}, {4, 3}, {5, 5}, {3, 5}} {
+				if v := got[(xy[1]*width+xy[0])*bpp]; v == 0 {
+					t.Errorf("neighbor (%d,%d) = 0, want energy spread to it", xy[0], xy[1])
+				}
+			}
+			if v := got[(2*width+2)*bpp]; v != 0 {
+				t.Errorf("pixel (2,2) outside radius = %d, want 0", v)
+			}
+			if got[center] >= 255 {
+				t.Errorf("center = %d, want dimmed", got[center])
+			}
+		})
+	}
+}
+
+func TestBoxBlurEdgeClamping(t *testing.T) {
+	// A uniform image stays uniform, including at the edges
+	pixels := bytes.Repeat([]byte{40, 80, 120}, 5*3)
+	if got := BoxBlur(pixels, 5, 3, ColorRGB, 2); !bytes.Equal(got, pixels) {
+		t.Errorf("BoxBlur() of uniform image = %v, want unchanged", got)
+	}
+}
+
+func TestBoxBlurClampsHugeRadius(t *testing.T) {
+	pixels := []byte{0, 50, 100, 150, 200, 250}
+	want := BoxBlur(pixels, 3, 2, ColorGrayscale, 3)
+	if got := BoxBlur(pixels, 3, 2, ColorGrayscale, math.MaxInt/2); !bytes.Equal(got, want) {
+		t.Errorf("BoxBlur(huge radius) = %v, want the radius-3 result %v", got, want)
+	}
+}
+
+func TestBoxBlurZeroRadiusCopies(t *testing.T) {
+	pixels := []byte{1, 2, 3, 4}
+	got := BoxBlur(pixels, 2, 2, ColorGrayscale, 0)
+	if !bytes.Equal(got, pixels) {
+		t.Errorf("BoxBlur(radius 0) = %v, want %v", got, pixels)
+	}
+	got[0] = 9
+	if pixels[0] != 1 {
+		t.Error("BoxBlur() returned the input slice instead of a copy")
+	}
+}
+
+func TestUnsharpMaskIncreasesEdgeContrast(t *testing.T) {
+	// 8x2 gray image: dark left half, light right half
+	width, height := 8, 2
+	row := []byte{80, 80, 80, 80, 160, 160, 160, 160}
+	pixels := append(append([]byte{}, row...), row...)
+
+	got := UnsharpMask(pixels, width, height, ColorGrayscale, 1.0, 1)
+
+	before := int(pixels[4]) - int(pixels[3])
+	after := int(got[4]) - int(got[3])
+	if after <= before {
+		t.Errorf("contrast across edge = %d after sharpening, want > %d", after, before)
+	}
+	if got[0] != 80 || got[7] != 160 {
+		t.Errorf("flat areas changed: got[0] = %d, got[7] = %d", got[0], got[7])
+	}
+}
+
+func TestUnsharpMaskKeepsAlpha(t *testing.T) {
+	pixels := []byte{
+		0, 0, 0, 10, 255, 255, 255, 200,
+		0, 0, 0, 30, 255, 255, 255, 40,
+	}
+	got := UnsharpMask(pixels, 2, 2, ColorRGBA, 2.0, 1)
+	for i := 3; i < len(got); i += 4 {
+		if got[i] != pixels[i] {
+			t.Errorf("alpha at pixel %d = %d, want %d", i/4, got[i], pixels[i])
+		}
+	}
+}
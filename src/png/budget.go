@@ -0,0 +1,82 @@
+package png
+
+import "fmt"
+
+// budgetQuantizeColors lists the MaxColors values tried, in order, once lossless
+// settings are exhausted.
+var budgetQuantizeColors = []int{255, 128, 64, 32, 16, 8, 4, 2}
+
+// EncodeUnderBudget encodes pixels with increasing effort until the PNG is at most
+// maxBytes long. It first tries lossless settings (stronger filter strategies and
+// compression levels), then quantizes RGB/RGBA input with decreasing MaxColors. Width, Height,
+// ColorType and the alpha/metadata settings come from opts.
+//
+// It returns the first result that fits together with the Options that produced it.
+// If nothing fits, it returns the smallest result, its Options, and ErrBudgetUnmet.
+func EncodeUnderBudget(pixels []byte, opts Options, maxBytes int) ([]byte, Options, error) {
+	if maxBytes <= 0 {
+		return nil, opts, fmt.Errorf("png: invalid size budget %d", maxBytes)
+	}
+
+	var best []byte
+	var bestOpts Options
+	for _, candidate := range budgetCandidates(opts) {
+		encoder, err := NewEncoderWithOptions(candidate)
+		if err != nil {
+			return nil, candidate, err
+		}
+		data, err := encoder.Encode(pixels)
+		if err != nil {
+			return nil, candidate, err
+		}
+
+		if len(data) <= maxBytes {
+			return data, candidate, nil
+		}
+		if best == nil || len(data) < len(best) {
+			best = data
+			bestOpts = candidate
+		}
+	}
+
+	return best, bestOpts, ErrBudgetUnmet
+}
+
+// budgetCandidates returns the option sets EncodeUnderBudget tries, cheapest first.
+func budgetCandidates(opts Options) []Options {
+	lossless := []struct {
+		strategy FilterStrategy
+		level    int
+		optimal  bool
+	}{
+		{FilterStrategyAdaptiveFast, 6, false},
+		{FilterStrategyAdaptive, 9, false},
+		{FilterStrategyMinSum, 9, true},
+	}
+
+	candidates := make([]Options, 0, len(lossless)+len(budgetQuantizeColors))
+	for _, l := range lossless {
+		o := opts
+		o.FilterStrategy = l.strategy
+		o.CompressionLevel = l.level
+		o.OptimalDeflate = l.optimal
+		candidates = append(candidates, o)
+	}
+
+	// Quantize only reads RGB samples; grayscale is already one byte per pixel.
+	if opts.ColorType != ColorRGB && opts.ColorType != ColorRGBA {
+		return candidates
+	}
+	for _, colors := range budgetQuantizeColors {
+		o := opts
+		o.FilterStrategy = FilterStrategyMinSum
+		o.CompressionLevel = 9
+		o.OptimalDeflate = true
+		o.MaxColors = colors
+		o.ReduceColorType = false
+		o.AutoPalette = false
+		candidates = append(candidates, o)
+	}
+
+	return candidates
+}
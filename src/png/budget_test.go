@@ -0,0 +1,72 @@
+package png
+
+import (
+	"bytes"
+	stdpng "image/png"
+	"math/rand"
+	"testing"
+)
+
+func TestEncodeUnderBudget(t *testing.T) {
+	width, height := 64, 64
+	rng := rand.New(rand.NewSource(1))
+	noise := make([]byte, width*height*3)
+	rng.Read(noise)
+
+	t.Run("lossless fits", func(t *testing.T) {
+		opts := FastOptions(width, height)
+		opts.ColorType = ColorRGB
+		data, used, err := EncodeUnderBudget(make([]byte, width*height*3), opts, 50*1024)
+		if err != nil {
+			t.Fatalf("EncodeUnderBudget() error = %v", err)
+		}
+		if len(data) > 50*1024 {
+			t.Errorf("len(data) = %d, want <= %d", len(data), 50*1024)
+		}
+		if used.MaxColors != 0 {
+			t.Errorf("MaxColors = %d, want 0 (no quantization)", used.MaxColors)
+		}
+	})
+
+	t.Run("tight budget quantizes", func(t *testing.T) {
+		opts := FastOptions(width, height)
+		opts.ColorType = ColorRGB
+		budget := 4000
+
+		data, used, err := EncodeUnderBudget(noise, opts, budget)
+		if err != nil {
+			t.Fatalf("EncodeUnderBudget() error = %v", err)
+		}
+		if len(data) > budget {
+			t.Errorf("len(data) = %d, want <= %d", len(data), budget)
+		}
+		if used.MaxColors == 0 {
+			t.Error("MaxColors = 0, want quantization to kick in")
+		}
+		if _, err := stdpng.Decode(bytes.NewReader(data)); err != nil {
+			t.Errorf("image/png.Decode() error = %v", err)
+		}
+	})
+
+	t.Run("unreachable budget", func(t *testing.T) {
+		opts := FastOptions(width, height)
+		opts.ColorType = ColorRGB
+
+		data, used, err := EncodeUnderBudget(noise, opts, 10)
+		if err != ErrBudgetUnmet {
+			t.Fatalf("EncodeUnderBudget() error = %v, want %v", err, ErrBudgetUnmet)
+		}
+		if len(data) == 0 {
+			t.Error("expected the smallest result to be returned")
+		}
+		if used.MaxColors == 0 {
+			t.Error("MaxColors = 0, want the smallest (quantized) settings reported")
+		}
+	})
+
+	t.Run("invalid budget", func(t *testing.T) {
+		if _, _, err := EncodeUnderBudget(noise, FastOptions(width, height), 0); err == nil {
+			t.Error("expected error for zero budget")
+		}
+	})
+}
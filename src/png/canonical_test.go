@@ -0,0 +1,83 @@
+package png
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+type canonicalFixture struct {
+	name      string
+	width     int
+	height    int
+	colorType ColorType
+	pixels    []byte
+	golden    string // hex-encoded PNG
+}
+
+// canonicalFixtures are tiny images covering the indexed and truecolor paths.
+func canonicalFixtures() []canonicalFixture {
+	// 4x4 RGBA, three colors plus a transparent border pixel: written indexed.
+	indexed := make([]byte, 0, 4*4*4)
+	for i := 0; i < 16; i++ {
+		switch {
+		case i == 0:
+			indexed = append(indexed, 0, 0, 0, 0)
+		case i%3 == 0:
+			indexed = append(indexed, 255, 0, 0, 255)
+		case i%3 == 1:
+			indexed = append(indexed, 0, 128, 255, 255)
+		default:
+			indexed = append(indexed, 250, 250, 250, 255)
+		}
+	}
+
+	// 17x16 RGB with 272 distinct colors: too many for a palette.
+	truecolor := make([]byte, 0, 17*16*3)
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 17; x++ {
+			truecolor = append(truecolor, uint8(x*15), uint8(y*16), uint8(x*y))
+		}
+	}
+
+	return []canonicalFixture{
+		{
+			name: "indexed", width: 4, height: 4, colorType: ColorRGBA, pixels: indexed,
+			golden: "89504e470d0a1a0a0000000d49484452000000040000000408030000009e2f6e4c0000000c504c5445" +
+				"000000ff00000080fffafafa182d698d0000000174524e530040e6d8660000001849444154789c636660" +
+				"6262606462fc07c68ccc0c8c8cff0015ed03117ea23f240000000049454e44ae426082",
+		},
+		{
+			name: "truecolor", width: 17, height: 16, colorType: ColorRGB, pixels: truecolor,
+			golden: "89504e470d0a1a0a0000000d49484452000000110000001008020000007f5303080000005a49444154" +
+				"789c95d37f0780301087f15bdbfab57ebdff57db256676dbed7b3cff7efe7c1c11ddc6023dac581aca66" +
+				"c22b8d07ab4c4092260e6b9a59af671625c5acbd74b3351b9a5d869854059aa30c3767ce64ae3fabf96e" +
+				"7801eb22076e7aa809ae0000000049454e44ae426082",
+		},
+	}
+}
+
+// TestCanonicalOptionsGolden pins the exact bytes CanonicalOptions produces. If
+// it fails, the change alters canonical output, which callers diff against
+// stored files; update the golden values only for an intentional format change.
+func TestCanonicalOptionsGolden(t *testing.T) {
+	for _, fx := range canonicalFixtures() {
+		t.Run(fx.name, func(t *testing.T) {
+			opts := CanonicalOptions(fx.width, fx.height)
+			opts.ColorType = fx.colorType
+			data, err := EncodeWithOptions(fx.pixels, opts)
+			if err != nil {
+				t.Fatalf("EncodeWithOptions() error = %v", err)
+			}
+
+			want, err := hex.DecodeString(fx.golden)
+			if err != nil {
+				t.Fatalf("bad golden hex: %v", err)
+			}
+			if !bytes.Equal(data, want) {
+				t.Errorf("canonical output changed:\n got %x\nwant %x", data, want)
+			}
+			assertDecodedPixels(t, data, fx.width, fx.height, fx.colorType, fx.pixels)
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package png
 
 import (
+	"bytes"
 	"encoding/binary"
+	"fmt"
 	"io"
 
 	"github.com/mac/go-pixo/src/compress"
@@ -12,6 +14,30 @@ type Chunk struct {
 	Data      []byte
 }
 
+// NewChunk creates a chunk with an arbitrary type, such as a private ancillary
+// chunk. The type must be four ASCII letters with the reserved (third) letter
+// uppercase, as the PNG spec requires.
+func NewChunk(chunkType string, data []byte) (*Chunk, error) {
+	if !isValidChunkType(chunkType) {
+		return nil, ErrInvalidChunkType
+	}
+	return &Chunk{chunkType: ChunkType(chunkType), Data: data}, nil
+}
+
+// isValidChunkType reports whether t is four ASCII letters with the reserved bit clear.
+func isValidChunkType(t string) bool {
+	if len(t) != 4 {
+		return false
+	}
+	for i := 0; i < 4; i++ {
+		c := t[i]
+		if !(c >= 'A' && c <= 'Z') && !(c >= 'a' && c <= 'z') {
+			return false
+		}
+	}
+	return t[2]&0x20 == 0
+}
+
 func (c *Chunk) Len() int {
 	return len(c.Data)
 }
@@ -20,10 +46,10 @@ func (c *Chunk) Type() string {
 	return string(c.chunkType)
 }
 
+// CRC returns the CRC-32 over the chunk type and data. The type is hashed in
+// place and the data fed in afterwards, so nothing is joined or allocated.
 func (c *Chunk) CRC() uint32 {
-	typeBytes := []byte(c.chunkType)
-	combined := append(typeBytes, c.Data...)
-	return compress.CRC32(combined)
+	return compress.UpdateCRC32(compress.UpdateCRC32String(0, string(c.chunkType)), c.Data)
 }
 
 func (c *Chunk) Bytes() []byte {
@@ -32,18 +58,40 @@ func (c *Chunk) Bytes() []byte {
 	crc := c.CRC()
 
 	result := make([]byte, 4+4+len(c.Data)+4)
-	binary.BigEndian.PutUint32(result[0:4], length)
+	writeUint32BE(result[0:4], length)
 	copy(result[4:8], typeBytes)
 	copy(result[8:8+len(c.Data)], c.Data)
-	binary.BigEndian.PutUint32(result[8+len(c.Data):], crc)
+	writeUint32BE(result[8+len(c.Data):], crc)
 
 	return result
 }
 
+// WriteTo writes the chunk's length, type, data, and CRC. The data is written
+// straight from c.Data rather than copied into a joined buffer first.
 func (c *Chunk) WriteTo(w io.Writer) (int64, error) {
-	bytes := c.Bytes()
-	n, err := w.Write(bytes)
-	return int64(n), err
+	var header [8]byte
+	writeUint32BE(header[0:4], uint32(len(c.Data)))
+	copy(header[4:8], c.chunkType)
+
+	var total int64
+	for _, part := range [][]byte{header[:], c.Data} {
+		n, err := w.Write(part)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+
+	var crc [4]byte
+	writeUint32BE(crc[:], c.CRC())
+	n, err := w.Write(crc[:])
+	return total + int64(n), err
+}
+
+// writeUint32BE stores v in buf[0:4] in network (big-endian) byte order. Every
+// chunk length and CRC field is written through it.
+func writeUint32BE(buf []byte, v uint32) {
+	binary.BigEndian.PutUint32(buf, v)
 }
 
 // IsCritical returns true if the chunk is critical for PNG decoding.
@@ -62,3 +110,82 @@ func (c *Chunk) IsCritical() bool {
 func (c *Chunk) IsRequired() bool {
 	return c.chunkType == ChunkIHDR || c.chunkType == ChunkIDAT || c.chunkType == ChunkIEND
 }
+
+// IsPrivate returns true if the chunk type is private rather than registered.
+// Private chunks have a lowercase second letter in their type.
+func (c *Chunk) IsPrivate() bool {
+	return len(c.chunkType) >= 2 && (c.chunkType[1]&0x20) != 0
+}
+
+// IterateChunks walks the chunks of an encoded PNG in order, calling fn for each.
+// It checks the signature, chunk bounds, and CRCs, and stops at the first error
+// returned by fn.
+func IterateChunks(data []byte, fn func(c *Chunk) error) error {
+	return iterateChunks(data, true, fn)
+}
+
+// iterateChunks is IterateChunks with CRC checking optional.
+func iterateChunks(data []byte, verifyCRC bool, fn func(c *Chunk) error) error {
+	if len(data) < len(PNG_SIGNATURE) || !bytes.Equal(data[:len(PNG_SIGNATURE)], PNG_SIGNATURE[:]) {
+		return ErrInvalidSignature
+	}
+
+	for off := len(PNG_SIGNATURE); off < len(data); {
+		if off+12 > len(data) {
+			return ErrInvalidChunkData
+		}
+		length := int(binary.BigEndian.Uint32(data[off : off+4]))
+		dataStart := off + 8
+		dataEnd := dataStart + length
+		if length < 0 || dataEnd+4 > len(data) {
+			return ErrInvalidChunkData
+		}
+
+		chunk := &Chunk{
+			chunkType: ChunkType(data[off+4 : dataStart]),
+			Data:      data[dataStart:dataEnd],
+		}
+		if verifyCRC && chunk.CRC() != binary.BigEndian.Uint32(data[dataEnd:dataEnd+4]) {
+			return ErrInvalidChunkData
+		}
+		if err := fn(chunk); err != nil {
+			return err
+		}
+
+		off = dataEnd + 4
+	}
+
+	return nil
+}
+
+// readChunkHeader reads a chunk's length and type from a PNG stream.
+func readChunkHeader(r io.Reader) (uint32, string, error) {
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, "", fmt.Errorf("png: reading chunk header: %w", err)
+	}
+	return binary.BigEndian.Uint32(header[0:4]), string(header[4:8]), nil
+}
+
+// readChunkBody reads the data and CRC that follow a chunk header and checks
+// the CRC. It returns ErrInvalidChunkData for a bad CRC or a truncated chunk.
+func readChunkBody(r io.Reader, length uint32, chunkType string) (*Chunk, error) {
+	// The spec limits chunk lengths to 2^31-1.
+	if length > 1<<31-1 {
+		return nil, ErrInvalidChunkData
+	}
+	// LimitReader lets a truncated stream fail without allocating the full
+	// declared length up front.
+	body, err := io.ReadAll(io.LimitReader(r, int64(length)+4))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) != int64(length)+4 {
+		return nil, ErrInvalidChunkData
+	}
+	chunk := &Chunk{chunkType: ChunkType(chunkType), Data: body[:length]}
+	if chunk.CRC() != binary.BigEndian.Uint32(body[length:]) {
+		return nil, ErrInvalidChunkData
+	}
+	return chunk, nil
+}
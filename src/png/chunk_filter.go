@@ -0,0 +1,47 @@
+package png
+
+import (
+	"fmt"
+	"io"
+)
+
+// FilterChunks copies the PNG read from r to w chunk by chunk, dropping every
+// ancillary chunk for which keep returns false. Critical chunks (IHDR, PLTE,
+// IDAT, IEND) are always copied and pixels are never decoded, so the pixel data
+// is preserved byte for byte. How the image displays may still change: dropping
+// tRNS removes transparency and dropping gAMA or other color chunks can shift
+// colors. Each chunk's CRC is verified before it is written; anything after
+// IEND is discarded. A nil keep drops all ancillary chunks.
+func FilterChunks(r io.Reader, w io.Writer, keep func(typ string) bool) error {
+	if err := readSignature(r); err != nil {
+		return err
+	}
+	if _, err := w.Write(Signature()); err != nil {
+		return err
+	}
+
+	first := true
+	for {
+		length, chunkType, err := readChunkHeader(r)
+		if err != nil {
+			return err
+		}
+		chunk, err := readChunkBody(r, length, chunkType)
+		if err != nil {
+			return err
+		}
+		if first && chunk.chunkType != ChunkIHDR {
+			return fmt.Errorf("%w: first chunk must be IHDR", ErrInvalidChunkOrder)
+		}
+		first = false
+
+		if chunk.IsCritical() || (keep != nil && keep(chunkType)) {
+			if _, err := chunk.WriteTo(w); err != nil {
+				return err
+			}
+		}
+		if chunk.chunkType == ChunkIEND {
+			return nil
+		}
+	}
+}
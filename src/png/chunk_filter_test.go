@@ -0,0 +1,98 @@
+package png
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestFilterChunks(t *testing.T) {
+	width, height := 8, 4
+	pixels := benchPixels(width, height, 3)
+	opts := FastOptions(width, height)
+	opts.ColorType = ColorRGB
+	for _, c := range []struct{ typ, data string }{
+		{"tEXt", "Comment\x00hello"},
+		{"pHYs", "\x00\x00\x0b\x13\x00\x00\x0b\x13\x01"},
+		{"prVt", "private"},
+	} {
+		chunk, err := NewChunk(c.typ, []byte(c.data))
+		if err != nil {
+			t.Fatalf("NewChunk(%q) error = %v", c.typ, err)
+		}
+		opts.ExtraChunks = append(opts.ExtraChunks, chunk)
+	}
+	data, err := EncodeWithOptions(pixels, opts)
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+
+	chunkTypes := func(data []byte) []string {
+		var types []string
+		for _, c := range parsePNGChunks(t, data) {
+			types = append(types, c.Type)
+		}
+		return types
+	}
+
+	tests := []struct {
+		name string
+		keep func(string) bool
+		want []string
+	}{
+		{"strip all", nil, []string{"IHDR", "IDAT", "IEND"}},
+		{"keep pHYs", func(typ string) bool { return typ == "pHYs" }, []string{"IHDR", "pHYs", "IDAT", "IEND"}},
+		{"keep all", func(string) bool { return true }, []string{"IHDR", "tEXt", "pHYs", "prVt", "IDAT", "IEND"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := FilterChunks(bytes.NewReader(data), &out, tt.keep); err != nil {
+				t.Fatalf("FilterChunks() error = %v", err)
+			}
+			if got := chunkTypes(out.Bytes()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterChunks() chunks = %v, want %v", got, tt.want)
+			}
+			assertDecodedPixels(t, out.Bytes(), width, height, ColorRGB, pixels)
+		})
+	}
+
+	var out bytes.Buffer
+	if err := FilterChunks(bytes.NewReader(data), &out, func(string) bool { return true }); err != nil {
+		t.Fatalf("FilterChunks() error = %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), data) {
+		t.Error("FilterChunks() keeping every chunk changed the file")
+	}
+}
+
+func TestFilterChunksErrors(t *testing.T) {
+	data, err := EncodeWithOptions(make([]byte, 4), Options{Width: 2, Height: 2, ColorType: ColorGrayscale, CompressionLevel: 6})
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+	badCRC := append([]byte(nil), data...)
+	badCRC[len(badCRC)-1] ^= 0xFF
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"bad signature", []byte("not a png at all"), ErrInvalidSignature},
+		{"bad crc", badCRC, ErrInvalidChunkData},
+		{"truncated chunk", data[:len(data)-14], ErrInvalidChunkData},
+		{"missing IEND", data[:len(data)-12], io.EOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := FilterChunks(bytes.NewReader(tt.data), &bytes.Buffer{}, nil); !errors.Is(err, tt.want) {
+				t.Errorf("FilterChunks() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
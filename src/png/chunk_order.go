@@ -0,0 +1,103 @@
+package png
+
+import "fmt"
+
+// chunksBeforePLTE must appear before PLTE and IDAT.
+var chunksBeforePLTE = map[string]bool{
+	"cHRM": true, "gAMA": true, "iCCP": true, "sBIT": true, "sRGB": true,
+}
+
+// chunksAfterPLTE must appear after PLTE (when present) and before IDAT.
+var chunksAfterPLTE = map[string]bool{
+	"tRNS": true, "bKGD": true, "hIST": true,
+}
+
+// chunksBeforeIDAT must appear before IDAT but have no PLTE constraint.
+var chunksBeforeIDAT = map[string]bool{
+	"pHYs": true, "sPLT": true,
+}
+
+// chunksMultiple may appear more than once; all other known chunks at most once.
+var chunksMultiple = map[string]bool{
+	"IDAT": true, "sPLT": true, "tEXt": true, "zTXt": true, "iTXt": true,
+}
+
+// ValidateChunkOrder checks a sequence of chunk types against the PNG spec's
+// ordering rules: IHDR first, IEND last, PLTE before IDAT, tRNS/bKGD/hIST after
+// PLTE and before IDAT, color-space chunks before PLTE, and IDAT chunks contiguous.
+// Unknown chunk types are allowed anywhere between IHDR and IEND.
+func ValidateChunkOrder(types []string) error {
+	if len(types) == 0 || types[0] != string(ChunkIHDR) {
+		return fmt.Errorf("%w: first chunk must be IHDR", ErrInvalidChunkOrder)
+	}
+	if types[len(types)-1] != string(ChunkIEND) {
+		return fmt.Errorf("%w: last chunk must be IEND", ErrInvalidChunkOrder)
+	}
+
+	seen := make(map[string]int)
+	idatEnded := false
+
+	for i, t := range types {
+		if i > 0 && (t == string(ChunkIHDR) || (t == string(ChunkIEND) && i != len(types)-1)) {
+			return fmt.Errorf("%w: %s at index %d", ErrInvalidChunkOrder, t, i)
+		}
+		if seen[t] > 0 && !chunksMultiple[t] && isKnownChunk(t) {
+			return fmt.Errorf("%w: duplicate %s at index %d", ErrInvalidChunkOrder, t, i)
+		}
+
+		if t == string(ChunkIDAT) {
+			if idatEnded {
+				return fmt.Errorf("%w: IDAT chunks are not contiguous (index %d)", ErrInvalidChunkOrder, i)
+			}
+		} else if seen[string(ChunkIDAT)] > 0 {
+			idatEnded = true
+		}
+
+		afterIDAT := seen[string(ChunkIDAT)] > 0
+		switch {
+		case t == "PLTE" && afterIDAT:
+			return fmt.Errorf("%w: PLTE after IDAT", ErrInvalidChunkOrder)
+		case chunksBeforePLTE[t] && (seen["PLTE"] > 0 || afterIDAT):
+			return fmt.Errorf("%w: %s must precede PLTE and IDAT", ErrInvalidChunkOrder, t)
+		case chunksAfterPLTE[t] && afterIDAT:
+			return fmt.Errorf("%w: %s must precede IDAT", ErrInvalidChunkOrder, t)
+		case chunksBeforeIDAT[t] && afterIDAT:
+			return fmt.Errorf("%w: %s must precede IDAT", ErrInvalidChunkOrder, t)
+		}
+
+		seen[t]++
+	}
+
+	if seen[string(ChunkIDAT)] == 0 {
+		return fmt.Errorf("%w: missing IDAT", ErrInvalidChunkOrder)
+	}
+
+	// tRNS, bKGD, and hIST refer to palette entries, so they must follow a PLTE if one exists.
+	if plte := indexOf(types, "PLTE"); plte >= 0 {
+		for i, t := range types[:plte] {
+			if chunksAfterPLTE[t] {
+				return fmt.Errorf("%w: %s at index %d precedes PLTE", ErrInvalidChunkOrder, t, i)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isKnownChunk reports whether t is a chunk type with spec-defined ordering rules.
+func isKnownChunk(t string) bool {
+	switch t {
+	case string(ChunkIHDR), "PLTE", string(ChunkIDAT), string(ChunkIEND):
+		return true
+	}
+	return chunksBeforePLTE[t] || chunksAfterPLTE[t] || chunksBeforeIDAT[t] || chunksMultiple[t] || t == "tIME"
+}
+
+func indexOf(types []string, t string) int {
+	for i, v := range types {
+		if v == t {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,67 @@
+package png
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateChunkOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   string
+		wantErr bool
+	}{
+		{"minimal", "IHDR IDAT IEND", false},
+		{"split IDAT", "IHDR IDAT IDAT IDAT IEND", false},
+		{"indexed with tRNS", "IHDR PLTE tRNS IDAT IEND", false},
+		{"metadata", "IHDR gAMA sRGB PLTE bKGD pHYs tEXt IDAT tEXt tIME IEND", false},
+		{"private ancillary", "IHDR prVw IDAT IEND", false},
+		{"empty", "", true},
+		{"IHDR not first", "IDAT IHDR IEND", true},
+		{"IEND not last", "IHDR IDAT IEND tEXt", true},
+		{"duplicate IHDR", "IHDR IHDR IDAT IEND", true},
+		{"missing IDAT", "IHDR PLTE IEND", true},
+		{"PLTE after IDAT", "IHDR IDAT PLTE IEND", true},
+		{"tRNS before PLTE", "IHDR tRNS PLTE IDAT IEND", true},
+		{"bKGD after IDAT", "IHDR PLTE IDAT bKGD IEND", true},
+		{"gAMA after PLTE", "IHDR PLTE gAMA IDAT IEND", true},
+		{"pHYs after IDAT", "IHDR IDAT pHYs IEND", true},
+		{"IDAT not contiguous", "IHDR IDAT tEXt IDAT IEND", true},
+		{"duplicate PLTE", "IHDR PLTE PLTE IDAT IEND", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateChunkOrder(strings.Fields(tt.order))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidChunkOrder) {
+					t.Errorf("ValidateChunkOrder(%q) error = %v, want %v", tt.order, err, ErrInvalidChunkOrder)
+				}
+			} else if err != nil {
+				t.Errorf("ValidateChunkOrder(%q) error = %v, want nil", tt.order, err)
+			}
+		})
+	}
+}
+
+func TestValidateChunkOrderEncoderOutput(t *testing.T) {
+	pixels := createTestImage(8, 8)
+	for _, opts := range []Options{FastOptions(8, 8), LossyOptions(8, 8, 4)} {
+		data, err := EncodeWithOptions(pixels, opts)
+		if err != nil {
+			t.Fatalf("EncodeWithOptions() error = %v", err)
+		}
+
+		var types []string
+		if err := IterateChunks(data, func(c *Chunk) error {
+			types = append(types, c.Type())
+			return nil
+		}); err != nil {
+			t.Fatalf("IterateChunks() error = %v", err)
+		}
+		if err := ValidateChunkOrder(types); err != nil {
+			t.Errorf("ValidateChunkOrder(%v) error = %v", types, err)
+		}
+	}
+}
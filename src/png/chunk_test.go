@@ -3,6 +3,8 @@ package png
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/mac/go-pixo/src/compress"
@@ -116,6 +118,34 @@ func TestChunkCRC(t *testing.T) {
 	}
 }
 
+func TestChunkStreamedCRCMatchesJoined(t *testing.T) {
+	data := make([]byte, 70000)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	chunk := &Chunk{chunkType: ChunkIDAT, Data: data}
+
+	joined := append([]byte("IDAT"), data...)
+	want := make([]byte, 0, len(joined)+8)
+	want = binary.BigEndian.AppendUint32(want, uint32(len(data)))
+	want = append(want, joined...)
+	want = binary.BigEndian.AppendUint32(want, compress.CRC32(joined))
+
+	var buf bytes.Buffer
+	if _, err := chunk.WriteTo(&buf); err != nil {
+		t.Fatalf("chunk.WriteTo() error = %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Error("chunk.WriteTo() output differs from the joined type+data encoding")
+	}
+	if !bytes.Equal(chunk.Bytes(), want) {
+		t.Error("chunk.Bytes() output differs from the joined type+data encoding")
+	}
+	if allocs := testing.AllocsPerRun(10, func() { chunk.CRC() }); allocs != 0 {
+		t.Errorf("chunk.CRC() allocs = %v, want 0", allocs)
+	}
+}
+
 func TestChunkBytes(t *testing.T) {
 	data := []byte{0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x08, 0x02, 0x00, 0x00, 0x00}
 	chunk := &Chunk{
@@ -203,3 +233,206 @@ func TestChunkBytesIEND(t *testing.T) {
 		t.Errorf("IEND CRC field = 0x%08x, want 0x%08x", crc, expectedCRC)
 	}
 }
+
+func TestNewChunk(t *testing.T) {
+	tests := []struct {
+		name      string
+		chunkType string
+		wantErr   bool
+	}{
+		{"private ancillary", "prVw", false},
+		{"public ancillary", "tEXt", false},
+		{"critical", "IDAT", false},
+		{"too short", "prV", true},
+		{"too long", "prVww", true},
+		{"non-letter", "pr1w", true},
+		{"reserved bit set", "prvw", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunk, err := NewChunk(tt.chunkType, []byte{1, 2, 3})
+			if tt.wantErr {
+				if err != ErrInvalidChunkType {
+					t.Errorf("NewChunk(%q) error = %v, want %v", tt.chunkType, err, ErrInvalidChunkType)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewChunk(%q) error = %v", tt.chunkType, err)
+			}
+			if chunk.Type() != tt.chunkType {
+				t.Errorf("chunk.Type() = %q, want %q", chunk.Type(), tt.chunkType)
+			}
+		})
+	}
+}
+
+func TestExtraChunkRoundTrip(t *testing.T) {
+	preview, err := NewChunk("prVw", []byte("tiny preview"))
+	if err != nil {
+		t.Fatalf("NewChunk() error = %v", err)
+	}
+	if preview.IsCritical() || !preview.IsPrivate() {
+		t.Errorf("prVw IsCritical() = %v, IsPrivate() = %v, want false, true", preview.IsCritical(), preview.IsPrivate())
+	}
+
+	opts := FastOptions(2, 2)
+	opts.ColorType = ColorRGB
+	opts.ExtraChunks = []*Chunk{preview}
+
+	data, err := EncodeWithOptions(make([]byte, 2*2*3), opts)
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+
+	var types []string
+	var found []byte
+	err = IterateChunks(data, func(c *Chunk) error {
+		types = append(types, c.Type())
+		if c.Type() == "prVw" {
+			found = c.Data
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("IterateChunks() error = %v", err)
+	}
+
+	wantTypes := []string{"IHDR", "prVw", "IDAT", "IEND"}
+	if strings.Join(types, ",") != strings.Join(wantTypes, ",") {
+		t.Errorf("chunk order = %v, want %v", types, wantTypes)
+	}
+	if string(found) != "tiny preview" {
+		t.Errorf("prVw data = %q, want %q", found, "tiny preview")
+	}
+}
+
+func TestExtraChunksRejected(t *testing.T) {
+	newChunk := func(typ string) *Chunk {
+		t.Helper()
+		c, err := NewChunk(typ, []byte{0, 0})
+		if err != nil {
+			t.Fatalf("NewChunk(%q) error = %v", typ, err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		chunk   *Chunk
+		wantErr error
+	}{
+		{"nil", nil, ErrInvalidExtraChunk},
+		{"IHDR", newChunk("IHDR"), ErrInvalidExtraChunk},
+		{"PLTE", newChunk("PLTE"), ErrInvalidExtraChunk},
+		{"IDAT", newChunk("IDAT"), ErrInvalidExtraChunk},
+		{"IEND", newChunk("IEND"), ErrInvalidExtraChunk},
+		{"unknown critical", newChunk("ABCD"), ErrInvalidExtraChunk},
+		{"tRNS", newChunk("tRNS"), ErrInvalidChunkOrder},
+		{"bKGD", newChunk("bKGD"), ErrInvalidChunkOrder},
+		{"hIST", newChunk("hIST"), ErrInvalidChunkOrder},
+	}
+
+	encoder, err := NewEncoderWithOptions(FastOptions(1, 1))
+	if err != nil {
+		t.Fatalf("NewEncoderWithOptions() error = %v", err)
+	}
+	palette := NewPalette(1)
+	palette.AddColor(Color{})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := FastOptions(1, 1)
+			opts.ExtraChunks = []*Chunk{tt.chunk}
+
+			if err := opts.Validate(); err == nil {
+				t.Error("Validate() error = nil, want error")
+			}
+			// Options passed per call skip Validate, so the encoder checks too.
+			if _, err := encoder.EncodeWithOptions(make([]byte, 4), opts); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Encoder.EncodeWithOptions() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := EncodeIndexed([]byte{0}, 1, 1, *palette, opts); !errors.Is(err, tt.wantErr) {
+				t.Errorf("EncodeIndexed() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIterateChunksCorrupt(t *testing.T) {
+	data, err := EncodeWithOptions(make([]byte, 4), FastOptions(1, 1))
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"bad signature", append([]byte{0}, data[1:]...), ErrInvalidSignature},
+		{"truncated", data[:len(data)-3], ErrInvalidChunkData},
+		{"bad crc", func() []byte {
+			d := append([]byte(nil), data...)
+			d[len(d)-1] ^= 0xFF
+			return d
+		}(), ErrInvalidChunkData},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := IterateChunks(tt.data, func(*Chunk) error { return nil }); err != tt.want {
+				t.Errorf("IterateChunks() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkWritersBigEndianFields(t *testing.T) {
+	palette := NewPalette(3)
+	for i := 0; i < 3; i++ {
+		palette.AddColor(Color{uint8(i), 0, 0})
+	}
+	ihdr, err := NewIHDRData(2, 2, 8, uint8(ColorRGB))
+	if err != nil {
+		t.Fatalf("NewIHDRData() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		write     func(w *bytes.Buffer) error
+		chunkType string
+		wantLen   int // -1 when the length depends on compression
+	}{
+		{"IHDR", func(w *bytes.Buffer) error { return WriteIHDR(w, ihdr) }, "IHDR", 13},
+		{"PLTE", func(w *bytes.Buffer) error { return WritePLTE(w, *palette) }, "PLTE", 9},
+		{"tRNS", func(w *bytes.Buffer) error { return WriteTRNS(w, make([]uint8, 256)) }, "tRNS", 256},
+		{"IEND", func(w *bytes.Buffer) error { return WriteIEND(w) }, "IEND", 0},
+		{"IDAT", func(w *bytes.Buffer) error { return WriteIDAT(w, make([]byte, 12), 2, 2, ColorRGB) }, "IDAT", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.write(&buf); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+			data := buf.Bytes()
+			length := binary.BigEndian.Uint32(data[0:4])
+			if tt.wantLen >= 0 && length != uint32(tt.wantLen) {
+				t.Errorf("length field = %d, want %d", length, tt.wantLen)
+			}
+			if int(length)+12 != len(data) {
+				t.Errorf("length field = %d, but chunk is %d bytes", length, len(data))
+			}
+			if got := string(data[4:8]); got != tt.chunkType {
+				t.Errorf("type = %q, want %q", got, tt.chunkType)
+			}
+			crc := binary.BigEndian.Uint32(data[len(data)-4:])
+			if want := compress.CRC32(data[4 : len(data)-4]); crc != want {
+				t.Errorf("CRC field = 0x%08x, want 0x%08x", crc, want)
+			}
+		})
+	}
+}
@@ -61,3 +61,30 @@ func CanReduceToRGB(pixels []byte, width, height int) bool {
 	}
 	return true
 }
+
+// MinGrayscaleBitDepth returns the smallest bit depth (1, 2, 4, or 8) that can
+// hold every 8-bit gray level in pixels without loss. A lower depth d only
+// fits if each level is an exact multiple of 255/(2^d-1), the step a decoder
+// scales d-bit samples up by: 0 and 255 for depth 1, multiples of 85 for 2,
+// and multiples of 17 for 4. Empty input returns 1.
+func MinGrayscaleBitDepth(pixels []byte) int {
+	var used [256]bool
+	for _, v := range pixels {
+		used[v] = true
+	}
+
+	for _, depth := range []int{1, 2, 4} {
+		step := 255 / (1<<depth - 1)
+		fits := true
+		for v, ok := range used {
+			if ok && v%step != 0 {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			return depth
+		}
+	}
+	return 8
+}
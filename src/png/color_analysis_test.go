@@ -134,3 +134,35 @@ func TestColorAnalysisLargeImages(t *testing.T) {
 		}
 	})
 }
+
+func TestMinGrayscaleBitDepth(t *testing.T) {
+	levels := func(step int) []byte {
+		var pixels []byte
+		for v := 0; v <= 255; v += step {
+			pixels = append(pixels, byte(v), byte(v))
+		}
+		return pixels
+	}
+
+	tests := []struct {
+		name   string
+		pixels []byte
+		want   int
+	}{
+		{"empty", nil, 1},
+		{"black and white", []byte{0, 255, 255, 0, 0}, 1},
+		{"single mid gray", []byte{128, 128}, 8},
+		{"four levels", levels(85), 2},
+		{"sixteen levels", levels(17), 4},
+		{"sixteen levels plus an odd one", append(levels(17), 16), 8},
+		{"full range", levels(1), 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MinGrayscaleBitDepth(tt.pixels); got != tt.want {
+				t.Errorf("MinGrayscaleBitDepth() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -48,7 +48,9 @@ func CountColorsWithAlpha(pixels []byte, colorType int) map[ColorWithCount]int {
 	return colorMap
 }
 
-// ToColorWithCountSlice converts a color count map to a sorted slice.
+// ToColorWithCountSlice converts a color count map to a slice sorted by count
+// descending. Ties are broken by R, then G, then B ascending, so the order (and
+// everything derived from it) does not depend on map iteration order.
 func ToColorWithCountSlice(colorMap map[Color]int) []ColorWithCount {
 	result := make([]ColorWithCount, 0, len(colorMap))
 
@@ -60,12 +62,26 @@ func ToColorWithCountSlice(colorMap map[Color]int) []ColorWithCount {
 	}
 
 	sort.Slice(result, func(i, j int) bool {
-		return result[i].Count > result[j].Count
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return colorLess(result[i].Color, result[j].Color)
 	})
 
 	return result
 }
 
+// colorLess orders colors by R, then G, then B.
+func colorLess(a, b Color) bool {
+	if a.R != b.R {
+		return a.R < b.R
+	}
+	if a.G != b.G {
+		return a.G < b.G
+	}
+	return a.B < b.B
+}
+
 // UniqueColorCount returns the number of unique colors in the pixel data.
 func UniqueColorCount(pixels []byte, colorType int) int {
 	colorMap := make(map[colorKey]struct{})
@@ -83,3 +99,37 @@ func UniqueColorCount(pixels []byte, colorType int) int {
 
 	return len(colorMap)
 }
+
+// CountUniqueColorsUpTo counts unique colors like UniqueColorCount but stops
+// scanning as soon as more than limit distinct colors have been seen.
+// When exceeded is true, count is limit+1 rather than the full color count.
+func CountUniqueColorsUpTo(pixels []byte, colorType int, limit int) (count int, exceeded bool) {
+	colorMap := make(map[colorKey]struct{})
+
+	bpp := BytesPerPixel(ColorType(colorType))
+
+	for i := 0; i+bpp <= len(pixels); i += bpp {
+		key := colorKey{r: pixels[i], g: pixels[i], b: pixels[i]}
+		if bpp >= 3 {
+			key.g = pixels[i+1]
+			key.b = pixels[i+2]
+		}
+		colorMap[key] = struct{}{}
+		if len(colorMap) > limit {
+			return len(colorMap), true
+		}
+	}
+
+	return len(colorMap), false
+}
+
+// RecommendMaxColors returns a suggested palette size for the pixel data.
+// It returns the exact number of unique colors when they fit in a PNG palette
+// (so quantization is lossless), or 0 when there are too many colors to quantize.
+func RecommendMaxColors(pixels []byte, colorType int) int {
+	count, exceeded := CountUniqueColorsUpTo(pixels, colorType, 256)
+	if exceeded {
+		return 0
+	}
+	return count
+}
@@ -97,6 +97,29 @@ func TestToColorWithCountSlice(t *testing.T) {
 	}
 }
 
+func TestToColorWithCountSliceTieBreak(t *testing.T) {
+	colorMap := map[Color]int{
+		{0, 0, 9}:   2,
+		{5, 1, 1}:   2,
+		{0, 3, 0}:   2,
+		{0, 0, 1}:   2,
+		{9, 9, 9}:   4,
+		{1, 0, 0}:   2,
+		{0, 3, 7}:   2,
+		{200, 0, 0}: 2,
+	}
+	want := []Color{{9, 9, 9}, {0, 0, 1}, {0, 0, 9}, {0, 3, 0}, {0, 3, 7}, {1, 0, 0}, {5, 1, 1}, {200, 0, 0}}
+
+	for run := 0; run < 20; run++ {
+		slice := ToColorWithCountSlice(colorMap)
+		for i, c := range want {
+			if slice[i].Color != c {
+				t.Fatalf("run %d: ToColorWithCountSlice()[%d] = %v, want %v", run, i, slice[i].Color, c)
+			}
+		}
+	}
+}
+
 func TestUniqueColorCount(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -231,3 +254,63 @@ func TestReflectTypeForColorMap(t *testing.T) {
 		t.Errorf("Color map key should be struct, got %v", keyType.Kind())
 	}
 }
+
+func TestCountUniqueColorsUpTo(t *testing.T) {
+	// 64x64 RGB gradient with 4096 distinct colors
+	pixels := make([]byte, 0, 64*64*3)
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			pixels = append(pixels, byte(x*4), byte(y*4), 0)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		limit        int
+		wantCount    int
+		wantExceeded bool
+	}{
+		{"early exit", 256, 257, true},
+		{"limit one", 1, 2, true},
+		{"limit above total", 5000, 4096, false},
+		{"limit equals total", 4096, 4096, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, exceeded := CountUniqueColorsUpTo(pixels, 2, tt.limit)
+			if count != tt.wantCount || exceeded != tt.wantExceeded {
+				t.Errorf("CountUniqueColorsUpTo() = (%v, %v), want (%v, %v)",
+					count, exceeded, tt.wantCount, tt.wantExceeded)
+			}
+		})
+	}
+}
+
+func TestCountUniqueColorsUpToGrayscale(t *testing.T) {
+	pixels := []byte{0, 10, 10, 20, 0}
+
+	count, exceeded := CountUniqueColorsUpTo(pixels, 0, 256)
+	if count != 3 || exceeded {
+		t.Errorf("CountUniqueColorsUpTo() grayscale = (%v, %v), want (3, false)", count, exceeded)
+	}
+}
+
+func TestRecommendMaxColors(t *testing.T) {
+	few := []byte{255, 0, 0, 0, 255, 0, 255, 0, 0, 0, 0, 255}
+	if got := RecommendMaxColors(few, 2); got != 3 {
+		t.Errorf("RecommendMaxColors() few colors = %v, want 3", got)
+	}
+
+	many := make([]byte, 0, 300*3)
+	for i := 0; i < 300; i++ {
+		many = append(many, byte(i), byte(i>>8), 7)
+	}
+	if got := RecommendMaxColors(many, 2); got != 0 {
+		t.Errorf("RecommendMaxColors() many colors = %v, want 0", got)
+	}
+
+	if got := RecommendMaxColors([]byte{}, 2); got != 0 {
+		t.Errorf("RecommendMaxColors() empty = %v, want 0", got)
+	}
+}
@@ -56,3 +56,26 @@ func ReduceToRGB(pixels []byte, width, height int) ([]byte, ColorType, error) {
 	}
 	return result, ColorRGB, nil
 }
+
+// ReduceToRGBUniformAlpha is like ReduceToRGB but also accepts RGBA pixels whose
+// alpha is the same non-opaque value everywhere (e.g. all 200). The alpha is
+// dropped from the pixels and returned so the caller can record it, for example
+// as a compositing note or by flattening later; PNG's RGB tRNS chunk can only mark
+// one color fully transparent, so it cannot carry a uniform partial alpha.
+// Opaque input returns alpha 255. Mixed alpha returns ErrCannotReduceColorType.
+func ReduceToRGBUniformAlpha(pixels []byte, width, height int) ([]byte, uint8, error) {
+	alpha, ok := HasUniformAlpha(pixels)
+	if !ok || len(pixels) != width*height*4 {
+		return nil, 0, ErrCannotReduceColorType
+	}
+
+	result := make([]byte, width*height*3)
+	for i := 0; i < width*height; i++ {
+		srcOffset := i * 4
+		dstOffset := i * 3
+		result[dstOffset] = pixels[srcOffset]
+		result[dstOffset+1] = pixels[srcOffset+1]
+		result[dstOffset+2] = pixels[srcOffset+2]
+	}
+	return result, alpha, nil
+}
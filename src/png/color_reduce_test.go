@@ -122,6 +122,49 @@ func TestReduceToRGB(t *testing.T) {
 	})
 }
 
+func TestReduceToRGBUniformAlpha(t *testing.T) {
+	t.Run("uniform non-opaque alpha", func(t *testing.T) {
+		pixels := []byte{100, 150, 200, 200, 50, 100, 150, 200}
+		result, alpha, err := ReduceToRGBUniformAlpha(pixels, 2, 1)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if alpha != 200 {
+			t.Errorf("expected alpha 200, got %d", alpha)
+		}
+		expected := []byte{100, 150, 200, 50, 100, 150}
+		for i := range expected {
+			if result[i] != expected[i] {
+				t.Errorf("expected %v, got %v", expected, result)
+				break
+			}
+		}
+	})
+
+	t.Run("opaque", func(t *testing.T) {
+		_, alpha, err := ReduceToRGBUniformAlpha([]byte{1, 2, 3, 255}, 1, 1)
+		if err != nil || alpha != 255 {
+			t.Errorf("expected alpha 255 and no error, got %d, %v", alpha, err)
+		}
+	})
+
+	t.Run("mixed alpha", func(t *testing.T) {
+		pixels := []byte{100, 150, 200, 200, 50, 100, 150, 255}
+		_, _, err := ReduceToRGBUniformAlpha(pixels, 2, 1)
+		if err != ErrCannotReduceColorType {
+			t.Errorf("expected ErrCannotReduceColorType, got %v", err)
+		}
+	})
+
+	t.Run("wrong size", func(t *testing.T) {
+		_, _, err := ReduceToRGBUniformAlpha([]byte{1, 2, 3, 200}, 2, 1)
+		if err != ErrCannotReduceColorType {
+			t.Errorf("expected ErrCannotReduceColorType, got %v", err)
+		}
+	})
+}
+
 func TestColorReduceLargeImages(t *testing.T) {
 	width, height := 100, 100
 
@@ -0,0 +1,132 @@
+package png
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	stdpng "image/png"
+)
+
+// CompareResult reports the outcome of encoding one option set in Compare.
+type CompareResult struct {
+	Options  Options
+	Size     int  // total PNG size in bytes
+	IDATSize int  // combined length of all IDAT chunk data
+	Valid    bool // chunks are in spec order and image/png decodes the output
+	Exact    bool // decoded pixels match the input exactly (false for lossy options)
+}
+
+// Compare encodes the same pixels with each option set and reports the resulting sizes
+// and whether the output decodes back to the input. Width, Height, and ColorType in each
+// option set are overridden by the arguments, so presets can be passed as-is. Every
+// other setting is kept, including the input layout (RowStride, InputBitDepth, and
+// InputIsBGRA), which is also how the decoded output is checked against pixels.
+func Compare(pixels []byte, width, height int, colorType ColorType, optsList []Options) ([]CompareResult, error) {
+	results := make([]CompareResult, 0, len(optsList))
+
+	for i, opts := range optsList {
+		opts.Width = width
+		opts.Height = height
+		opts.ColorType = colorType
+
+		encoder, err := NewEncoderWithOptions(opts)
+		if err != nil {
+			return nil, fmt.Errorf("png: compare option set %d: %w", i, err)
+		}
+		data, err := encoder.Encode(pixels)
+		if err != nil {
+			return nil, fmt.Errorf("png: compare option set %d: %w", i, err)
+		}
+
+		idatSize, err := idatDataSize(data)
+		if err != nil {
+			return nil, fmt.Errorf("png: compare option set %d: %w", i, err)
+		}
+		valid, exact := checkDecoded(data, pixels, opts)
+		results = append(results, CompareResult{
+			Options:  opts,
+			Size:     len(data),
+			IDATSize: idatSize,
+			Valid:    valid,
+			Exact:    exact,
+		})
+	}
+
+	return results, nil
+}
+
+// checkDecoded checks chunk order, decodes data with image/png, and compares it
+// against the source pixels, read with the input layout opts describes.
+func checkDecoded(data, pixels []byte, opts Options) (valid, exact bool) {
+	width, height := opts.Width, opts.Height
+	var types []string
+	if err := IterateChunks(data, func(c *Chunk) error {
+		types = append(types, c.Type())
+		return nil
+	}); err != nil || ValidateChunkOrder(types) != nil {
+		return false, false
+	}
+
+	img, err := stdpng.Decode(bytes.NewReader(data))
+	if err != nil {
+		return false, false
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dx() != width || bounds.Dy() != height {
+		return true, false
+	}
+
+	bpp := BytesPerPixel(opts.ColorType)
+	sampleSize := 1
+	if opts.InputBitDepth == 16 {
+		sampleSize = 2
+	}
+	stride := opts.RowStride
+	if stride == 0 {
+		stride = opts.inputRowSize()
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			offset := y*stride + x*bpp*sampleSize
+			sample := func(c int) uint8 {
+				i := offset + c*sampleSize
+				if sampleSize == 2 {
+					return sample16to8(pixels[i], pixels[i+1])
+				}
+				return pixels[i]
+			}
+			v := sample(0)
+			want := color.NRGBA{R: v, G: v, B: v, A: 0xFF}
+			if bpp >= 3 {
+				want.G = sample(1)
+				want.B = sample(2)
+				if opts.InputIsBGRA {
+					want.R, want.B = want.B, want.R
+				}
+			}
+			if bpp == 4 {
+				want.A = sample(3)
+			}
+
+			got := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			if got != want {
+				return true, false
+			}
+		}
+	}
+
+	return true, true
+}
+
+// idatDataSize sums the data lengths of all IDAT chunks in an encoded PNG.
+func idatDataSize(data []byte) (int, error) {
+	total := 0
+	err := IterateChunks(data, func(c *Chunk) error {
+		if c.chunkType == ChunkIDAT {
+			total += c.Len()
+		}
+		return nil
+	})
+	return total, err
+}
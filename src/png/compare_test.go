@@ -0,0 +1,83 @@
+package png
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	width, height := 64, 64
+	pixels := createTestImage(width, height)
+
+	results, err := Compare(pixels, width, height, ColorRGBA, []Options{
+		FastOptions(0, 0),
+		MaxOptions(0, 0),
+		LossyOptions(0, 0, 4),
+	})
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Compare() returned %d results, want 3", len(results))
+	}
+
+	fastResult, maxResult := results[0], results[1]
+	if maxResult.Size > fastResult.Size {
+		t.Errorf("Compare() Max size = %d, want <= Fast size %d", maxResult.Size, fastResult.Size)
+	}
+
+	for i, r := range results {
+		if r.IDATSize <= 0 || r.IDATSize >= r.Size {
+			t.Errorf("results[%d].IDATSize = %d, want between 0 and Size %d", i, r.IDATSize, r.Size)
+		}
+		if !r.Valid {
+			t.Errorf("results[%d].Valid = false, want true", i)
+		}
+		if r.Options.Width != width || r.Options.Height != height {
+			t.Errorf("results[%d] options size = %dx%d, want %dx%d", i, r.Options.Width, r.Options.Height, width, height)
+		}
+	}
+
+	if !fastResult.Exact || !maxResult.Exact {
+		t.Errorf("Compare() lossless Exact = %v, %v, want true, true", fastResult.Exact, maxResult.Exact)
+	}
+}
+
+func TestCompareInputLayout(t *testing.T) {
+	width, height := 4, 3
+	rgba := createTestImage(width, height)
+
+	// The same image as padded, 16-bit BGRA rows
+	stride := width*8 + 6
+	wide := make([]byte, stride*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			src := rgba[(y*width+x)*4:]
+			dst := wide[y*stride+x*8:]
+			for c, v := range []byte{src[2], src[1], src[0], src[3]} {
+				dst[2*c], dst[2*c+1] = v, v
+			}
+		}
+	}
+	opts := FastOptions(0, 0)
+	opts.RowStride = stride
+	opts.InputBitDepth = 16
+	opts.InputIsBGRA = true
+
+	results, err := Compare(wide, width, height, ColorRGBA, []Options{opts})
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if r := results[0]; !r.Valid || !r.Exact {
+		t.Errorf("Compare() Valid, Exact = %v, %v, want true, true", r.Valid, r.Exact)
+	}
+	if got := results[0].Options; got.RowStride != stride || got.InputBitDepth != 16 || !got.InputIsBGRA {
+		t.Errorf("Compare() result options lost the input layout: %+v", got)
+	}
+}
+
+func TestCompareInvalidOptions(t *testing.T) {
+	opts := FastOptions(0, 0)
+	opts.Dithering = true
+
+	if _, err := Compare(make([]byte, 4), 1, 1, ColorRGBA, []Options{opts}); err == nil {
+		t.Error("Compare() error = nil, want error for invalid options")
+	}
+}
@@ -1,5 +1,7 @@
 package png
 
+import "fmt"
+
 var PNG_SIGNATURE = [8]byte{0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a}
 
 type ChunkType string
@@ -18,3 +20,19 @@ const (
 	ColorRGBA      ColorType = 6
 	ColorIndexed   ColorType = 3
 )
+
+// String returns the color type name, e.g. "RGBA", or "Unknown(N)".
+func (c ColorType) String() string {
+	switch c {
+	case ColorGrayscale:
+		return "Grayscale"
+	case ColorRGB:
+		return "RGB"
+	case ColorRGBA:
+		return "RGBA"
+	case ColorIndexed:
+		return "Indexed"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(c))
+	}
+}
@@ -0,0 +1,24 @@
+package png
+
+import "testing"
+
+func TestColorTypeString(t *testing.T) {
+	tests := []struct {
+		colorType ColorType
+		want      string
+	}{
+		{ColorGrayscale, "Grayscale"},
+		{ColorRGB, "RGB"},
+		{ColorRGBA, "RGBA"},
+		{ColorIndexed, "Indexed"},
+		{ColorType(99), "Unknown(99)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.colorType.String(); got != tt.want {
+				t.Errorf("ColorType(%d).String() = %q, want %q", uint8(tt.colorType), got, tt.want)
+			}
+		})
+	}
+}
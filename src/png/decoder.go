@@ -0,0 +1,314 @@
+package png
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/mac/go-pixo/src/compress"
+)
+
+// DecodedImage is a decoded PNG in one of the encoder's input formats, so its
+// Pixels can be passed straight back to an Encoder.
+type DecodedImage struct {
+	Width     int
+	Height    int
+	ColorType ColorType // ColorGrayscale, ColorRGB, or ColorRGBA
+	Pixels    []byte
+	// Indexed reports that the file stored palette indices; Pixels hold the
+	// expanded colors.
+	Indexed bool
+	// Chunks are the ancillary chunks other than tRNS, in file order.
+	Chunks []*Chunk
+}
+
+// DecodeOptions controls the integrity checks DecodeWithOptions performs. The
+// zero value enables every check. Skipping a check trades corruption detection
+// for speed on trusted data; data that is damaged but still parses then
+// decodes on a best-effort basis.
+type DecodeOptions struct {
+	// SkipCRC disables checking every chunk's CRC-32.
+	SkipCRC bool
+	// SkipAdler disables checking the Adler-32 trailer of the image data's zlib
+	// stream.
+	SkipAdler bool
+	// MaxDecompressedBytes rejects images whose filtered scanlines, as declared
+	// by IHDR, would exceed this many bytes. Zero means no limit beyond the
+	// declared size itself: inflating is always stopped at that size, so image
+	// data that expands further fails with ErrDecompressionLimit instead of
+	// being read into memory.
+	MaxDecompressedBytes int
+}
+
+// Decode decodes an 8-bit, non-interlaced PNG. Indexed images are expanded to
+// RGB, or RGBA when the palette has transparency; a grayscale or RGB tRNS color
+// key is expanded to RGBA. The payloads of consecutive IDAT chunks are joined
+// into one zlib stream. Chunk CRCs and the zlib Adler32 are verified. Valid
+// PNGs it cannot read (other bit depths, interlacing, and grayscale with alpha)
+// return ErrUnsupportedPNG.
+func Decode(data []byte) (*DecodedImage, error) {
+	return DecodeWithOptions(data, DecodeOptions{})
+}
+
+// DecodeWithOptions decodes like Decode, skipping the checksums opts disables.
+func DecodeWithOptions(data []byte, opts DecodeOptions) (*DecodedImage, error) {
+	var (
+		ihdr    *IHDRData
+		palette []byte
+		trns    []byte
+		idat    []byte
+		chunks  []*Chunk
+		sawIEND bool
+		// idatDone is set by the first chunk after the IDAT run; the
+		// IDAT payloads are one zlib stream and must be consecutive.
+		idatDone bool
+	)
+	err := iterateChunks(data, !opts.SkipCRC, func(c *Chunk) error {
+		switch {
+		case sawIEND:
+			return fmt.Errorf("png: chunk %s after IEND", c.Type())
+		case ihdr == nil && c.chunkType != ChunkIHDR:
+			return fmt.Errorf("%w: first chunk must be IHDR", ErrInvalidChunkOrder)
+		}
+
+		switch c.Type() {
+		case "IHDR":
+			if ihdr != nil {
+				return fmt.Errorf("%w: duplicate IHDR", ErrInvalidChunkOrder)
+			}
+			parsed, err := parseIHDR(c.Data)
+			if err != nil {
+				return err
+			}
+			ihdr = parsed
+		case "PLTE":
+			palette = c.Data
+		case "tRNS":
+			trns = c.Data
+		case "IDAT":
+			if idatDone {
+				return fmt.Errorf("%w: IDAT chunks are not consecutive", ErrInvalidChunkOrder)
+			}
+			idat = append(idat, c.Data...)
+		case "IEND":
+			sawIEND = true
+		default:
+			if c.IsCritical() {
+				return fmt.Errorf("%w: unknown critical chunk %s", ErrUnsupportedPNG, c.Type())
+			}
+			chunks = append(chunks, c)
+		}
+		if c.chunkType != ChunkIDAT && len(idat) > 0 {
+			idatDone = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if ihdr == nil || !sawIEND || len(idat) == 0 {
+		return nil, fmt.Errorf("png: missing IHDR, IDAT, or IEND chunk")
+	}
+	if ihdr.BitDepth != 8 || ihdr.Interlace != 0 {
+		return nil, ErrUnsupportedPNG
+	}
+
+	width, height := int(ihdr.Width), int(ihdr.Height)
+	if err := checkImageSize(width, height, DefaultMaxPixels); err != nil {
+		return nil, err
+	}
+
+	bpp := 1
+	if ihdr.ColorType != ColorIndexed {
+		bpp = BytesPerPixel(ihdr.ColorType)
+	}
+	rawSize := (1 + width*bpp) * height
+	if opts.MaxDecompressedBytes > 0 && rawSize > opts.MaxDecompressedBytes {
+		return nil, fmt.Errorf("%w: image needs %d bytes, limit is %d", ErrDecompressionLimit, rawSize, opts.MaxDecompressedBytes)
+	}
+	raw, err := inflateZlib(idat, rawSize, !opts.SkipAdler)
+	if err != nil {
+		return nil, err
+	}
+	pixels, err := unfilterRows(raw, width, height, bpp)
+	if err != nil {
+		return nil, err
+	}
+
+	img := &DecodedImage{Width: width, Height: height, ColorType: ihdr.ColorType, Pixels: pixels, Chunks: chunks}
+	switch ihdr.ColorType {
+	case ColorIndexed:
+		err = img.expandPalette(palette, trns)
+	case ColorGrayscale, ColorRGB:
+		err = img.expandColorKey(trns)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// parseIHDR reads and validates IHDR chunk data.
+func parseIHDR(data []byte) (*IHDRData, error) {
+	if len(data) != 13 {
+		return nil, ErrInvalidChunkData
+	}
+	ihdr := &IHDRData{
+		Width:       binary.BigEndian.Uint32(data[0:4]),
+		Height:      binary.BigEndian.Uint32(data[4:8]),
+		BitDepth:    data[8],
+		ColorType:   ColorType(data[9]),
+		Compression: data[10],
+		Filter:      data[11],
+		Interlace:   data[12],
+	}
+	if ihdr.ColorType == 4 {
+		return nil, ErrUnsupportedPNG
+	}
+	if err := ihdr.Validate(); err != nil {
+		return nil, err
+	}
+	return ihdr, nil
+}
+
+// inflateZlib decompresses a zlib stream that must hold exactly want bytes and,
+// if verifyAdler is set, checks its Adler32 trailer.
+func inflateZlib(data []byte, want int, verifyAdler bool) ([]byte, error) {
+	if len(data) < 6 {
+		return nil, fmt.Errorf("png: zlib stream too short")
+	}
+	if data[0]&0x0F != 8 || (int(data[0])<<8|int(data[1]))%31 != 0 {
+		return nil, fmt.Errorf("png: invalid zlib header")
+	}
+	if data[1]&0x20 != 0 {
+		return nil, fmt.Errorf("%w: zlib preset dictionary", ErrUnsupportedPNG)
+	}
+
+	r := bytes.NewReader(data[2:])
+	fr := flate.NewReader(r)
+	defer fr.Close()
+	// The buffer grows with the data actually inflated, so a header claiming
+	// a huge image cannot allocate want bytes up front
+	raw, err := io.ReadAll(io.LimitReader(fr, int64(want)+1))
+	if err != nil {
+		return nil, fmt.Errorf("png: inflating image data: %w", err)
+	}
+	if len(raw) > want {
+		return nil, fmt.Errorf("%w: image data is longer than %d bytes", ErrDecompressionLimit, want)
+	}
+	if len(raw) < want {
+		return nil, fmt.Errorf("png: inflating image data: %w", io.ErrUnexpectedEOF)
+	}
+
+	if !verifyAdler {
+		return raw, nil
+	}
+	trailer := make([]byte, 4)
+	if _, err := io.ReadFull(r, trailer); err != nil {
+		return nil, fmt.Errorf("png: missing zlib checksum")
+	}
+	if binary.BigEndian.Uint32(trailer) != compress.Adler32(raw) {
+		return nil, fmt.Errorf("png: zlib checksum mismatch")
+	}
+	return raw, nil
+}
+
+// unfilterRows reverses the per-row filters of raw scanline data.
+func unfilterRows(raw []byte, width, height, bpp int) ([]byte, error) {
+	rowLen := width * bpp
+	pixels := make([]byte, 0, rowLen*height)
+	var prev []byte
+	for y := 0; y < height; y++ {
+		line := raw[y*(rowLen+1) : (y+1)*(rowLen+1)]
+		filtered := line[1:]
+
+		var row []byte
+		switch FilterType(line[0]) {
+		case FilterNone:
+			row = ReconstructNone(filtered)
+		case FilterSub:
+			row = ReconstructSub(filtered, bpp)
+		case FilterUp:
+			row = ReconstructUp(filtered, prev)
+		case FilterAverage:
+			row = ReconstructAverage(filtered, prev, bpp)
+		case FilterPaeth:
+			row = ReconstructPaeth(filtered, prev, bpp)
+		default:
+			return nil, fmt.Errorf("png: row %d has invalid filter type %d", y, line[0])
+		}
+		pixels = append(pixels, row...)
+		prev = row
+	}
+	return pixels, nil
+}
+
+// expandPalette replaces palette indices with RGB, or RGBA if trns is present.
+func (img *DecodedImage) expandPalette(plte, trns []byte) error {
+	if len(plte) == 0 || len(plte)%3 != 0 || len(plte) > 256*3 {
+		return fmt.Errorf("png: indexed image has invalid PLTE chunk")
+	}
+	numColors := len(plte) / 3
+	if len(trns) > numColors {
+		return fmt.Errorf("png: tRNS has more entries than PLTE")
+	}
+
+	colorType, bpp := ColorRGB, 3
+	if len(trns) > 0 {
+		colorType, bpp = ColorRGBA, 4
+	}
+	pixels := make([]byte, len(img.Pixels)*bpp)
+	for i, idx := range img.Pixels {
+		if int(idx) >= numColors {
+			return fmt.Errorf("png: pixel %d uses palette index %d, palette has %d colors", i, idx, numColors)
+		}
+		copy(pixels[i*bpp:], plte[int(idx)*3:int(idx)*3+3])
+		if bpp == 4 {
+			pixels[i*4+3] = 255
+			if int(idx) < len(trns) {
+				pixels[i*4+3] = trns[idx]
+			}
+		}
+	}
+	img.Pixels = pixels
+	img.ColorType = colorType
+	img.Indexed = true
+	return nil
+}
+
+// expandColorKey converts grayscale or RGB pixels to RGBA when trns names a
+// transparent color, making matching pixels fully transparent.
+func (img *DecodedImage) expandColorKey(trns []byte) error {
+	if len(trns) == 0 {
+		return nil
+	}
+
+	bpp := BytesPerPixel(img.ColorType)
+	if len(trns) != bpp*2 {
+		return fmt.Errorf("png: tRNS length %d invalid for %s", len(trns), img.ColorType)
+	}
+	// Samples are 16-bit even at depth 8; the low byte holds the value.
+	key := make([]byte, bpp)
+	for c := range key {
+		key[c] = trns[c*2+1]
+	}
+
+	pixels := make([]byte, 0, len(img.Pixels)/bpp*4)
+	for i := 0; i+bpp <= len(img.Pixels); i += bpp {
+		px := img.Pixels[i : i+bpp]
+		alpha := byte(255)
+		if bytes.Equal(px, key) {
+			alpha = 0
+		}
+		if bpp == 1 {
+			pixels = append(pixels, px[0], px[0], px[0], alpha)
+		} else {
+			pixels = append(pixels, px[0], px[1], px[2], alpha)
+		}
+	}
+	img.Pixels = pixels
+	img.ColorType = ColorRGBA
+	return nil
+}
@@ -0,0 +1,291 @@
+package png
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	stdpng "image/png"
+	"testing"
+)
+
+func TestDecodeRoundTrip(t *testing.T) {
+	width, height := 7, 5
+	tests := []struct {
+		name      string
+		colorType ColorType
+		opts      func(w, h int) Options
+	}{
+		{"grayscale fast", ColorGrayscale, FastOptions},
+		{"rgb balanced", ColorRGB, BalancedOptions},
+		{"rgba max", ColorRGBA, MaxOptions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bpp := BytesPerPixel(tt.colorType)
+			pixels := make([]byte, width*height*bpp)
+			for i := range pixels {
+				pixels[i] = byte(i * 37)
+			}
+			opts := tt.opts(width, height)
+			opts.ColorType = tt.colorType
+			opts.ReduceColorType = false
+
+			data, err := EncodeWithOptions(pixels, opts)
+			if err != nil {
+				t.Fatalf("EncodeWithOptions() error = %v", err)
+			}
+			img, err := Decode(data)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if img.Width != width || img.Height != height || img.ColorType != tt.colorType {
+				t.Errorf("Decode() = %dx%d %s, want %dx%d %s", img.Width, img.Height, img.ColorType, width, height, tt.colorType)
+			}
+			if !bytes.Equal(img.Pixels, pixels) {
+				t.Error("decoded pixels do not match input")
+			}
+		})
+	}
+}
+
+func TestDecodeIndexed(t *testing.T) {
+	palette := NewPalette(2)
+	palette.AddColorWithAlpha(Color{255, 0, 0}, 255)
+	palette.AddColorWithAlpha(Color{0, 0, 255}, 64)
+
+	data, err := EncodeIndexed([]byte{0, 1, 1, 0}, 2, 2, *palette, FastOptions(2, 2))
+	if err != nil {
+		t.Fatalf("EncodeIndexed() error = %v", err)
+	}
+	img, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	want := []byte{
+		255, 0, 0, 255, 0, 0, 255, 64,
+		0, 0, 255, 64, 255, 0, 0, 255,
+	}
+	if img.ColorType != ColorRGBA || !img.Indexed {
+		t.Errorf("Decode() ColorType = %s, Indexed = %v, want RGBA, true", img.ColorType, img.Indexed)
+	}
+	if !bytes.Equal(img.Pixels, want) {
+		t.Errorf("Decode() pixels = %v, want %v", img.Pixels, want)
+	}
+}
+
+func TestDecodeStandardLibraryOutput(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	for i := range src.Pix {
+		src.Pix[i] = byte(i * 11)
+	}
+	var buf bytes.Buffer
+	if err := stdpng.Encode(&buf, src); err != nil {
+		t.Fatalf("image/png.Encode() error = %v", err)
+	}
+
+	img, err := Decode(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if img.ColorType != ColorRGBA || !bytes.Equal(img.Pixels, src.Pix) {
+		t.Errorf("Decode() = %s %v, want RGBA %v", img.ColorType, img.Pixels, src.Pix)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	valid, err := EncodeWithOptions([]byte{1, 2, 3, 4, 5, 6}, Options{Width: 2, Height: 1, ColorType: ColorRGB, CompressionLevel: 6})
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+
+	var deep bytes.Buffer
+	gray16 := image.NewGray16(image.Rect(0, 0, 1, 1))
+	gray16.SetGray16(0, 0, color.Gray16{Y: 0x1234})
+	if err := stdpng.Encode(&deep, gray16); err != nil {
+		t.Fatalf("image/png.Encode() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"bad signature", []byte("not a png"), ErrInvalidSignature},
+		{"truncated", valid[:len(valid)-5], ErrInvalidChunkData},
+		{"16-bit", deep.Bytes(), ErrUnsupportedPNG},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(tt.data)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Decode() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeWithOptionsChecksums(t *testing.T) {
+	pixels := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+	valid, err := EncodeWithOptions(pixels, Options{Width: 2, Height: 2, ColorType: ColorRGB, CompressionLevel: 6})
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+
+	// Flip the Adler32 trailer and re-sign the IDAT so only the Adler is wrong.
+	badAdler := append([]byte(nil), valid...)
+	typeAt := bytes.Index(badAdler, []byte("IDAT"))
+	length := int(binary.BigEndian.Uint32(badAdler[typeAt-4:]))
+	idat := &Chunk{chunkType: ChunkIDAT, Data: badAdler[typeAt+4 : typeAt+4+length]}
+	idat.Data[length-1] ^= 0xFF
+	binary.BigEndian.PutUint32(badAdler[typeAt+4+length:], idat.CRC())
+
+	// Flip the IEND CRC.
+	badCRC := append([]byte(nil), valid...)
+	badCRC[len(badCRC)-1] ^= 0xFF
+
+	tests := []struct {
+		name    string
+		data    []byte
+		opts    DecodeOptions
+		wantErr bool
+	}{
+		{"bad adler strict", badAdler, DecodeOptions{}, true},
+		{"bad adler lenient", badAdler, DecodeOptions{SkipAdler: true}, false},
+		{"bad crc strict", badCRC, DecodeOptions{}, true},
+		{"bad crc lenient", badCRC, DecodeOptions{SkipCRC: true}, false},
+		{"valid all checks skipped", valid, DecodeOptions{SkipCRC: true, SkipAdler: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := DecodeWithOptions(tt.data, tt.opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("DecodeWithOptions() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeWithOptions() error = %v", err)
+			}
+			if !bytes.Equal(img.Pixels, pixels) {
+				t.Errorf("DecodeWithOptions() pixels = %v, want %v", img.Pixels, pixels)
+			}
+		})
+	}
+}
+
+func TestDecodeDecompressionLimit(t *testing.T) {
+	// A 2x2 grayscale header whose IDAT inflates to 1 MiB of zeros.
+	ihdr, err := NewIHDRData(2, 2, 8, uint8(ColorGrayscale))
+	if err != nil {
+		t.Fatalf("NewIHDRData() error = %v", err)
+	}
+	var zbuf bytes.Buffer
+	zw := zlib.NewWriter(&zbuf)
+	if _, err := zw.Write(make([]byte, 1<<20)); err != nil {
+		t.Fatalf("zlib Write() error = %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zlib Close() error = %v", err)
+	}
+
+	bomb := append([]byte(nil), Signature()...)
+	for _, c := range []*Chunk{
+		{chunkType: ChunkIHDR, Data: ihdr.Bytes()},
+		{chunkType: ChunkIDAT, Data: zbuf.Bytes()},
+		{chunkType: ChunkIEND},
+	} {
+		bomb = append(bomb, c.Bytes()...)
+	}
+	if _, err := Decode(bomb); !errors.Is(err, ErrDecompressionLimit) {
+		t.Errorf("Decode() of oversized image data error = %v, want %v", err, ErrDecompressionLimit)
+	}
+
+	valid, err := EncodeWithOptions(make([]byte, 16*16), Options{Width: 16, Height: 16, ColorType: ColorGrayscale, CompressionLevel: 6})
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+	opts := DecodeOptions{MaxDecompressedBytes: 16 * 17}
+	if _, err := DecodeWithOptions(valid, opts); err != nil {
+		t.Errorf("DecodeWithOptions() at the limit error = %v", err)
+	}
+	opts.MaxDecompressedBytes--
+	if _, err := DecodeWithOptions(valid, opts); !errors.Is(err, ErrDecompressionLimit) {
+		t.Errorf("DecodeWithOptions() over the limit error = %v, want %v", err, ErrDecompressionLimit)
+	}
+
+	// Setting only the limit must leave the checksums on.
+	badCRC := append([]byte(nil), valid...)
+	badCRC[len(badCRC)-1] ^= 0xFF
+	if _, err := DecodeWithOptions(badCRC, DecodeOptions{MaxDecompressedBytes: 1 << 20}); err == nil {
+		t.Error("DecodeWithOptions() with only MaxDecompressedBytes set accepted a bad CRC")
+	}
+}
+
+func TestDecodeSplitIDAT(t *testing.T) {
+	width, height := 32, 16
+	pixels := benchPixels(width, height, 3)
+	opts := BalancedOptions(width, height)
+	opts.ColorType = ColorRGB
+	opts.ReduceColorType = false
+	opts.MaxIDATChunkSize = 64
+
+	data, err := EncodeWithOptions(pixels, opts)
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+	chunks := parsePNGChunks(t, data)
+	var types []string
+	idats := 0
+	for _, c := range chunks {
+		types = append(types, c.Type)
+		if c.Type == "IDAT" {
+			idats++
+			if len(c.Data) > opts.MaxIDATChunkSize {
+				t.Errorf("IDAT chunk is %d bytes, want at most %d", len(c.Data), opts.MaxIDATChunkSize)
+			}
+		}
+	}
+	if idats < 2 {
+		t.Fatalf("got %d IDAT chunks, want several", idats)
+	}
+	if err := ValidateChunkOrder(types); err != nil {
+		t.Errorf("ValidateChunkOrder() error = %v", err)
+	}
+
+	img, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if !bytes.Equal(img.Pixels, pixels) {
+		t.Error("decoded pixels do not match input")
+	}
+	assertDecodedPixels(t, data, width, height, ColorRGB, pixels)
+
+	// Moving an ancillary chunk between two IDATs breaks the run.
+	text, err := NewChunk("tEXt", []byte("k\x00v"))
+	if err != nil {
+		t.Fatalf("NewChunk() error = %v", err)
+	}
+	split := append([]byte(nil), Signature()...)
+	seen := 0
+	for _, c := range chunks {
+		chunk := &Chunk{chunkType: ChunkType(c.Type), Data: c.Data}
+		split = append(split, chunk.Bytes()...)
+		if c.Type == "IDAT" {
+			if seen++; seen == 1 {
+				split = append(split, text.Bytes()...)
+			}
+		}
+	}
+	if _, err := Decode(split); !errors.Is(err, ErrInvalidChunkOrder) {
+		t.Errorf("Decode() with non-consecutive IDATs error = %v, want %v", err, ErrInvalidChunkOrder)
+	}
+}
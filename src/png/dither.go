@@ -1,5 +1,11 @@
 package png
 
+import "math"
+
+// All dither functions map to palette indices. If the palette has no colors,
+// every pixel maps to index 0 and no error is diffused; callers should not
+// write such output without first checking palette.NumColors.
+
 // Threshold applies no dithering, direct palette mapping.
 // Each pixel is simply mapped to the nearest palette color.
 func Threshold(pixels []byte, palette Palette) []byte {
@@ -26,6 +32,9 @@ func Threshold(pixels []byte, palette Palette) []byte {
 func FloydSteinberg(pixels []byte, palette Palette) []byte {
 	bpp := 3 // RGB
 	width := len(pixels) / bpp
+	if palette.NumColors == 0 {
+		return make([]byte, width)
+	}
 
 	pixelData := make([][3]int, width)
 	for i := 0; i < width; i++ {
@@ -75,108 +84,261 @@ func FloydSteinberg(pixels []byte, palette Palette) []byte {
 	return indexed
 }
 
-// FloydSteinbergRow applies Floyd-Steinberg dithering row by row.
-// This is used for 2D images where errors propagate to the next row.
-func FloydSteinbergRow(pixels []byte, palette Palette, prevErrors [][3]int) ([]byte, [][3]int) {
-	bpp := 3 // RGB
-	width := len(pixels) / bpp
+// DitherConfig tunes the 2D error-diffusion dithers. The zero value clamps
+// error, scans every row left to right, and diffuses at full strength.
+type DitherConfig struct {
+	// UnclampedError measures quantization error from the pixel value plus its
+	// incoming error before that sum is clamped to 0-255, so error pushed past
+	// the representable range keeps propagating instead of being dropped.
+	UnclampedError bool
+	// Serpentine scans odd rows right to left with the kernel mirrored, which
+	// breaks up the diagonal patterns of a fixed scan direction.
+	Serpentine bool
+	// RGBA reads pixels as RGBA instead of RGB. Each pixel is matched with
+	// FindNearestWithAlpha against its own alpha, which is never diffused, so a
+	// palette holding the image's alpha levels keeps them intact. Fully
+	// transparent pixels spread no color error.
+	RGBA bool
+	// Strength scales the diffused error, up to 1 (full diffusion). Zero, the
+	// default, means 1. Values above 1 are clamped to 1, and negative values
+	// turn diffusion off, giving the same indices as Threshold.
+	Strength float64
+}
 
-	pixelData := make([][3]int, width)
-	for i := 0; i < width; i++ {
-		offset := i * bpp
-		pixelData[i] = [3]int{
-			int(pixels[offset]),
-			int(pixels[offset+1]),
-			int(pixels[offset+2]),
-		}
+func (cfg DitherConfig) bpp() int {
+	if cfg.RGBA {
+		return 4
 	}
+	return 3
+}
 
-	indexed := make([]byte, width)
-	errors := make([][3]int, width+2)
+func (cfg DitherConfig) strength() float64 {
+	switch {
+	case cfg.Strength == 0 || cfg.Strength > 1:
+		return 1
+	case cfg.Strength < 0:
+		return 0
+	}
+	return cfg.Strength
+}
 
-	for i := 0; i < width; i++ {
-		r := pixelData[i][0]
-		g := pixelData[i][1]
-		b := pixelData[i][2]
+// ditherTap sends weight/divisor of a pixel's error to the pixel dx columns
+// ahead (in scan direction) and dy rows below.
+type ditherTap struct {
+	dx, dy, weight int
+}
 
-		if prevErrors != nil && i < len(prevErrors) {
-			r += prevErrors[i][0]
-			g += prevErrors[i][1]
-			b += prevErrors[i][2]
-		}
+// ditherKernel is an error-diffusion matrix.
+type ditherKernel struct {
+	taps    []ditherTap
+	divisor int
+	rows    int // 1 + the largest dy
+}
 
-		r = clampInt(r)
-		g = clampInt(g)
-		b = clampInt(b)
+var (
+	floydSteinbergKernel = ditherKernel{
+		taps:    []ditherTap{{1, 0, 7}, {-1, 1, 3}, {0, 1, 5}, {1, 1, 1}},
+		divisor: 16,
+		rows:    2,
+	}
+	jarvisJudiceNinkeKernel = ditherKernel{
+		taps: []ditherTap{
+			{1, 0, 7}, {2, 0, 5},
+			{-2, 1, 3}, {-1, 1, 5}, {0, 1, 7}, {1, 1, 5}, {2, 1, 3},
+			{-2, 2, 1}, {-1, 2, 3}, {0, 2, 5}, {1, 2, 3}, {2, 2, 1},
+		},
+		divisor: 48,
+		rows:    3,
+	}
+	sierraKernel = ditherKernel{
+		taps: []ditherTap{
+			{1, 0, 5}, {2, 0, 3},
+			{-2, 1, 2}, {-1, 1, 4}, {0, 1, 5}, {1, 1, 4}, {2, 1, 2},
+			{-1, 2, 2}, {0, 2, 3}, {1, 2, 2},
+		},
+		divisor: 32,
+		rows:    3,
+	}
+	burkesKernel = ditherKernel{
+		taps: []ditherTap{
+			{1, 0, 8}, {2, 0, 4},
+			{-2, 1, 2}, {-1, 1, 4}, {0, 1, 8}, {1, 1, 4}, {2, 1, 2},
+		},
+		divisor: 32,
+		rows:    2,
+	}
+	// Atkinson diffuses only 6/8 of the error, trading accuracy for contrast.
+	atkinsonKernel = ditherKernel{
+		taps: []ditherTap{
+			{1, 0, 1}, {2, 0, 1},
+			{-1, 1, 1}, {0, 1, 1}, {1, 1, 1},
+			{0, 2, 1},
+		},
+		divisor: 8,
+		rows:    3,
+	}
+)
 
-		c := Color{
-			R: uint8(r),
-			G: uint8(g),
-			B: uint8(b),
-		}
+// FloydSteinbergRow applies Floyd-Steinberg dithering row by row.
+// prevErrors holds the error diffused into this row by the previous one, indexed
+// by column; the returned errors are the same for the next row.
+func FloydSteinbergRow(pixels []byte, palette Palette, prevErrors [][3]int) ([]byte, [][3]int) {
+	width := len(pixels) / 3
+	errs := [][][3]int{make([][3]int, width), make([][3]int, width+2)}
+	copy(errs[0], prevErrors)
 
-		paletteIdx := palette.FindNearest(c)
-		paletteColor := palette.Colors[paletteIdx]
+	indexed := diffuseRow(pixels, palette, floydSteinbergKernel, errs, DitherConfig{}, false)
+	return indexed, errs[1]
+}
 
-		errR := r - int(paletteColor.R)
-		errG := g - int(paletteColor.G)
-		errB := b - int(paletteColor.B)
+// FloydSteinberg2D applies Floyd-Steinberg dithering for 2D images.
+// It propagates errors to both right and below pixels with the standard 7/16,
+// 3/16, 5/16 and 1/16 weights, negative error included. Earlier versions sent
+// only 3/16 of the error to the pixel below and dropped negative error, which
+// left most images close to undithered, so their output differs from this one.
+// Returns an empty slice if pixels holds fewer than width*height RGB pixels
+// (RGBA with DitherConfig.RGBA).
+func FloydSteinberg2D(pixels []byte, width, height int, palette Palette) []byte {
+	return FloydSteinberg2DWithConfig(pixels, width, height, palette, DitherConfig{})
+}
 
-		indexed[i] = uint8(paletteIdx)
+// FloydSteinberg2DWithConfig is FloydSteinberg2D with error clamping, scan
+// order, and RGBA input controlled by cfg.
+func FloydSteinberg2DWithConfig(pixels []byte, width, height int, palette Palette, cfg DitherConfig) []byte {
+	return diffuse2D(pixels, width, height, palette, floydSteinbergKernel, cfg)
+}
 
-		errors[i][0] = errR * 3 / 16
-		errors[i][1] = errG * 3 / 16
-		errors[i][2] = errB * 3 / 16
+// JarvisJudiceNinke2D applies Jarvis-Judice-Ninke dithering to a 2D image,
+// spreading error over two rows below. It returns an empty slice under the same
+// conditions as FloydSteinberg2D.
+func JarvisJudiceNinke2D(pixels []byte, width, height int, palette Palette, cfg DitherConfig) []byte {
+	return diffuse2D(pixels, width, height, palette, jarvisJudiceNinkeKernel, cfg)
+}
 
-		if i+1 < width {
-			errors[i+1][0] += errR * 7 / 16
-			errors[i+1][1] += errG * 7 / 16
-			errors[i+1][2] += errB * 7 / 16
-		}
-		if i+2 < width {
-			errors[i+2][0] += errR * 5 / 16
-			errors[i+2][1] += errG * 5 / 16
-			errors[i+2][2] += errB * 5 / 16
-		}
-		if i+1 < len(errors) {
-			errors[i+1][0] = clampInt(errors[i+1][0])
-			errors[i+1][1] = clampInt(errors[i+1][1])
-			errors[i+1][2] = clampInt(errors[i+1][2])
-		}
-	}
+// Sierra2D applies three-row Sierra dithering, which is close to
+// Jarvis-Judice-Ninke with a cheaper kernel.
+func Sierra2D(pixels []byte, width, height int, palette Palette, cfg DitherConfig) []byte {
+	return diffuse2D(pixels, width, height, palette, sierraKernel, cfg)
+}
 
-	return indexed, errors
+// Burkes2D applies Burkes dithering, a two-row simplification of Stucki.
+func Burkes2D(pixels []byte, width, height int, palette Palette, cfg DitherConfig) []byte {
+	return diffuse2D(pixels, width, height, palette, burkesKernel, cfg)
 }
 
-// FloydSteinberg2D applies Floyd-Steinberg dithering for 2D images.
-// It propagates errors to both right and below pixels.
-func FloydSteinberg2D(pixels []byte, width, height int, palette Palette) []byte {
-	bpp := 3 // RGB
+// Atkinson2D applies Atkinson dithering. Only 3/4 of the error is diffused, so
+// highlights and shadows wash out less noisily but lose some detail.
+func Atkinson2D(pixels []byte, width, height int, palette Palette, cfg DitherConfig) []byte {
+	return diffuse2D(pixels, width, height, palette, atkinsonKernel, cfg)
+}
+
+// diffuse2D dithers an RGB or RGBA image with kernel, keeping one error row per
+// kernel row.
+func diffuse2D(pixels []byte, width, height int, palette Palette, kernel ditherKernel, cfg DitherConfig) []byte {
+	bpp := cfg.bpp()
 	rowSize := width * bpp
+	if width <= 0 || height <= 0 || len(pixels) < rowSize*height {
+		return []byte{}
+	}
 
 	result := make([]byte, width*height)
-
-	var prevErrors [][3]int
+	errs := make([][][3]int, kernel.rows)
+	for i := range errs {
+		errs[i] = make([][3]int, width)
+	}
 
 	for y := 0; y < height; y++ {
-		rowStart := y * rowSize
-		rowPixels := pixels[rowStart : rowStart+rowSize]
-
-		indexed, errors := FloydSteinbergRow(rowPixels, palette, prevErrors)
-
+		reverse := cfg.Serpentine && y%2 == 1
+		indexed := diffuseRow(pixels[y*rowSize:(y+1)*rowSize], palette, kernel, errs, cfg, reverse)
 		copy(result[y*width:(y+1)*width], indexed)
 
-		prevErrors = errors
+		// The current row's errors are spent; recycle it as the lowest row.
+		done := errs[0]
+		copy(errs, errs[1:])
+		for i := range done {
+			done[i] = [3]int{}
+		}
+		errs[len(errs)-1] = done
 	}
 
 	return result
 }
 
+// diffuseRow maps one row to palette indices. errs[0] holds the error
+// already diffused into the row and errs[dy] the rows below; each pixel's own
+// error is spread through kernel, mirrored when reverse scans right to left.
+func diffuseRow(row []byte, palette Palette, kernel ditherKernel, errs [][][3]int, cfg DitherConfig, reverse bool) []byte {
+	bpp := cfg.bpp()
+	width := len(row) / bpp
+	indexed := make([]byte, width)
+	if palette.NumColors == 0 {
+		return indexed
+	}
+	strength := cfg.strength()
+
+	for n := 0; n < width; n++ {
+		x, dir := n, 1
+		if reverse {
+			x, dir = width-1-n, -1
+		}
+
+		var v [3]int
+		var c [3]uint8
+		for ch := range v {
+			v[ch] = int(row[x*bpp+ch]) + errs[0][x][ch]
+			clamped := clampInt(v[ch])
+			c[ch] = uint8(clamped)
+			if !cfg.UnclampedError {
+				v[ch] = clamped
+			}
+		}
+
+		var paletteIdx int
+		if cfg.RGBA {
+			alpha := row[x*bpp+3]
+			paletteIdx = palette.FindNearestWithAlpha(Color{R: c[0], G: c[1], B: c[2]}, alpha)
+			if alpha == 0 {
+				indexed[x] = uint8(paletteIdx)
+				continue
+			}
+		} else {
+			paletteIdx = palette.FindNearest(Color{R: c[0], G: c[1], B: c[2]})
+		}
+		paletteColor := palette.Colors[paletteIdx]
+		indexed[x] = uint8(paletteIdx)
+
+		e := [3]int{
+			v[0] - int(paletteColor.R),
+			v[1] - int(paletteColor.G),
+			v[2] - int(paletteColor.B),
+		}
+		if strength != 1 {
+			for ch := range e {
+				e[ch] = int(math.Round(float64(e[ch]) * strength))
+			}
+		}
+		for _, tap := range kernel.taps {
+			tx := x + tap.dx*dir
+			if tx < 0 || tx >= width || tap.dy >= len(errs) {
+				continue
+			}
+			for ch := range e {
+				errs[tap.dy][tx][ch] += e[ch] * tap.weight / kernel.divisor
+			}
+		}
+	}
+
+	return indexed
+}
+
 // JarvisJudiceNinke applies Jarvis-Judice-Ninke dithering.
 // This produces higher quality dithering but is slower.
 func JarvisJudiceNinke(pixels []byte, palette Palette) []byte {
 	bpp := 3 // RGB
 	width := len(pixels) / bpp
+	if palette.NumColors == 0 {
+		return make([]byte, width)
+	}
 
 	pixelData := make([][3]int, width)
 	for i := 0; i < width; i++ {
@@ -1,6 +1,7 @@
 package png
 
 import (
+	"bytes"
 	"testing"
 )
 
@@ -208,6 +209,242 @@ func TestFloydSteinberg2D(t *testing.T) {
 	}
 }
 
+func TestFloydSteinberg2DDefaultOutput(t *testing.T) {
+	palette := NewPalette(2)
+	palette.AddColor(Color{0, 0, 0})
+	palette.AddColor(Color{255, 255, 255})
+
+	// Pins the full-kernel default. The old weights thresholded every row to
+	// the same 0 0 0 1 1 1.
+	want := []byte{
+		0, 0, 0, 1, 1, 1,
+		0, 0, 1, 0, 1, 1,
+		0, 0, 1, 0, 1, 1,
+	}
+	if got := FloydSteinberg2D(grayGradient(6, 3), 6, 3, *palette); !bytes.Equal(got, want) {
+		t.Errorf("FloydSteinberg2D() = %v, want %v", got, want)
+	}
+}
+
+func grayGradient(width, height int) []byte {
+	pixels := make([]byte, width*height*3)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := byte(x * 255 / (width - 1))
+			i := (y*width + x) * 3
+			pixels[i], pixels[i+1], pixels[i+2] = v, v, v
+		}
+	}
+	return pixels
+}
+
+func TestFloydSteinberg2DWithConfig(t *testing.T) {
+	palette := NewPalette(2)
+	palette.AddColor(Color{0, 0, 0})
+	palette.AddColor(Color{255, 255, 255})
+
+	width, height := 16, 8
+	pixels := grayGradient(width, height)
+
+	plain := FloydSteinberg2DWithConfig(pixels, width, height, *palette, DitherConfig{})
+	if !bytes.Equal(plain, FloydSteinberg2D(pixels, width, height, *palette)) {
+		t.Error("FloydSteinberg2DWithConfig() with zero config differs from FloydSteinberg2D()")
+	}
+
+	serpentine := FloydSteinberg2DWithConfig(pixels, width, height, *palette, DitherConfig{Serpentine: true})
+	if len(serpentine) != width*height {
+		t.Fatalf("FloydSteinberg2DWithConfig() length = %d, want %d", len(serpentine), width*height)
+	}
+	if bytes.Equal(serpentine, plain) {
+		t.Error("FloydSteinberg2DWithConfig() with Serpentine = left-to-right output, want it to differ")
+	}
+	if !bytes.Equal(serpentine[:width], plain[:width]) {
+		t.Error("FloydSteinberg2DWithConfig() with Serpentine changed the first row, which is scanned left to right")
+	}
+}
+
+func TestFloydSteinberg2DUnclampedError(t *testing.T) {
+	palette := NewPalette(3)
+	palette.AddColor(Color{0, 0, 0})
+	palette.AddColor(Color{128, 128, 128})
+	palette.AddColor(Color{255, 255, 255})
+
+	// 225 rounds up to white and pushes the next pixel to 2-13 = -11. Clamped,
+	// that error is lost, so the third pixel's +5 error tips 191 over to white;
+	// unclamped, the -11 cancels it and 191 stays gray.
+	pixels := []byte{225, 225, 225, 2, 2, 2, 5, 5, 5, 191, 191, 191}
+
+	tests := []struct {
+		name string
+		cfg  DitherConfig
+		want []byte
+	}{
+		{"clamped", DitherConfig{}, []byte{2, 0, 0, 2}},
+		{"unclamped", DitherConfig{UnclampedError: true}, []byte{2, 0, 0, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FloydSteinberg2DWithConfig(pixels, 4, 1, *palette, tt.cfg)
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("FloydSteinberg2DWithConfig() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDitherKernelWeights(t *testing.T) {
+	tests := []struct {
+		name   string
+		kernel ditherKernel
+		want   int // total weight, in units of the divisor
+	}{
+		{"Floyd-Steinberg", floydSteinbergKernel, 16},
+		{"Jarvis-Judice-Ninke", jarvisJudiceNinkeKernel, 48},
+		{"Sierra", sierraKernel, 32},
+		{"Burkes", burkesKernel, 32},
+		{"Atkinson", atkinsonKernel, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sum := 0
+			for _, tap := range tt.kernel.taps {
+				sum += tap.weight
+				if tap.dy >= tt.kernel.rows || (tap.dy == 0 && tap.dx <= 0) {
+					t.Errorf("tap %+v is outside the kernel's %d rows or behind the current pixel", tap, tt.kernel.rows)
+				}
+			}
+			if sum != tt.want {
+				t.Errorf("kernel weights sum to %d, want %d", sum, tt.want)
+			}
+		})
+	}
+}
+
+func TestSerpentineDirectionalBias(t *testing.T) {
+	palette := NewPalette(2)
+	palette.AddColor(Color{0, 0, 0})
+	palette.AddColor(Color{255, 255, 255})
+	width, height := 64, 64
+
+	// Atkinson is left out: it diffuses only 3/4 of the error and settles into
+	// periodic textures whichever way it scans.
+	dithers := []struct {
+		name string
+		fn   func([]byte, int, int, Palette, DitherConfig) []byte
+	}{
+		{"FloydSteinberg2D", FloydSteinberg2DWithConfig},
+		{"JarvisJudiceNinke2D", JarvisJudiceNinke2D},
+		{"Sierra2D", Sierra2D},
+		{"Burkes2D", Burkes2D},
+	}
+
+	// bias returns (down-right - down-left) / total diagonal neighbors, summed
+	// over a range of flat gray fields.
+	bias := func(fn func([]byte, int, int, Palette, DitherConfig) []byte, cfg DitherConfig) float64 {
+		var downRight, downLeft int
+		for gray := 32; gray <= 224; gray += 16 {
+			pixels := bytes.Repeat([]byte{byte(gray)}, width*height*3)
+			dr, dl := diagonalRuns(fn(pixels, width, height, *palette, cfg), width, height)
+			downRight += dr
+			downLeft += dl
+		}
+		return float64(downRight-downLeft) / float64(downRight+downLeft)
+	}
+
+	if b := bias(FloydSteinberg2DWithConfig, DitherConfig{}); b < 0.05 {
+		t.Errorf("left-to-right FloydSteinberg2D bias = %.3f, want a clear down-right lean", b)
+	}
+	for _, d := range dithers {
+		t.Run(d.name, func(t *testing.T) {
+			if b := bias(d.fn, DitherConfig{Serpentine: true}); b < -0.03 || b > 0.03 {
+				t.Errorf("serpentine bias = %.3f, want within ±0.03", b)
+			}
+		})
+	}
+}
+
+func TestDither2DRGBA(t *testing.T) {
+	alphas := []uint8{0, 128, 255}
+	palette := NewPalette(6)
+	for _, a := range alphas {
+		palette.AddColorWithAlpha(Color{0, 0, 0}, a)
+		palette.AddColorWithAlpha(Color{255, 255, 255}, a)
+	}
+
+	// A horizontal gray gradient; each row uses one of the palette's alphas.
+	width, height := 16, 6
+	rgb := grayGradient(width, height)
+	pixels := make([]byte, width*height*4)
+	for i := 0; i < width*height; i++ {
+		copy(pixels[i*4:], rgb[i*3:i*3+3])
+		pixels[i*4+3] = alphas[i/width%len(alphas)]
+	}
+
+	for _, serpentine := range []bool{false, true} {
+		indexed := FloydSteinberg2DWithConfig(pixels, width, height, *palette, DitherConfig{RGBA: true, Serpentine: serpentine})
+		if len(indexed) != width*height {
+			t.Fatalf("FloydSteinberg2DWithConfig() length = %d, want %d", len(indexed), width*height)
+		}
+		for i, idx := range indexed {
+			if got, want := palette.GetAlpha(int(idx)), pixels[i*4+3]; got != want {
+				t.Errorf("serpentine=%v: pixel %d mapped to index %d with alpha %d, want alpha %d", serpentine, i, idx, got, want)
+			}
+		}
+	}
+
+	// An opaque row must pick the same colors as dithering its RGB pixels.
+	opaqueRow := pixels[2*width*4 : 3*width*4]
+	got := FloydSteinberg2DWithConfig(opaqueRow, width, 1, *palette, DitherConfig{RGBA: true})
+	want := FloydSteinberg2DWithConfig(rgb[:width*3], width, 1, *palette, DitherConfig{})
+	for i := range want {
+		if palette.GetColor(int(got[i])) != palette.GetColor(int(want[i])) {
+			t.Errorf("opaque RGBA pixel %d = %v, want %v", i, palette.GetColor(int(got[i])), palette.GetColor(int(want[i])))
+		}
+	}
+}
+
+func TestDither2DStrength(t *testing.T) {
+	palette := NewPalette(4)
+	palette.AddColor(Color{0, 0, 0})
+	palette.AddColor(Color{85, 85, 85})
+	palette.AddColor(Color{170, 170, 170})
+	palette.AddColor(Color{255, 255, 255})
+
+	width, height := 16, 8
+	pixels := grayGradient(width, height)
+	threshold := Threshold(pixels, *palette)
+
+	dithers := []struct {
+		name string
+		fn   func([]byte, int, int, Palette, DitherConfig) []byte
+	}{
+		{"FloydSteinberg2D", FloydSteinberg2DWithConfig},
+		{"JarvisJudiceNinke2D", JarvisJudiceNinke2D},
+		{"Sierra2D", Sierra2D},
+		{"Burkes2D", Burkes2D},
+		{"Atkinson2D", Atkinson2D},
+	}
+	for _, d := range dithers {
+		t.Run(d.name, func(t *testing.T) {
+			full := d.fn(pixels, width, height, *palette, DitherConfig{})
+			if bytes.Equal(full, threshold) {
+				t.Fatal("full-strength output equals Threshold; gradient does not exercise diffusion")
+			}
+			if got := d.fn(pixels, width, height, *palette, DitherConfig{Strength: -1}); !bytes.Equal(got, threshold) {
+				t.Errorf("Strength -1 = %v, want Threshold output %v", got, threshold)
+			}
+			if got := d.fn(pixels, width, height, *palette, DitherConfig{Strength: 1}); !bytes.Equal(got, full) {
+				t.Errorf("Strength 1 = %v, want default output %v", got, full)
+			}
+			if got := d.fn(pixels, width, height, *palette, DitherConfig{Strength: 3}); !bytes.Equal(got, full) {
+				t.Errorf("Strength 3 = %v, want it clamped to 1", got)
+			}
+		})
+	}
+}
+
 func TestJarvisJudiceNinke(t *testing.T) {
 	palette := NewPalette(3)
 	palette.AddColor(Color{0, 0, 0})
@@ -324,3 +561,96 @@ func TestDitheringWithSmallPalette(t *testing.T) {
 		}
 	}
 }
+
+func TestDitherEmptyPixels(t *testing.T) {
+	palette := NewPalette(2)
+	palette.AddColor(Color{0, 0, 0})
+	palette.AddColor(Color{255, 255, 255})
+
+	tests := []struct {
+		name   string
+		dither func() []byte
+	}{
+		{"Threshold", func() []byte { return Threshold(nil, *palette) }},
+		{"FloydSteinberg", func() []byte { return FloydSteinberg(nil, *palette) }},
+		{"FloydSteinbergRow", func() []byte { indexed, _ := FloydSteinbergRow(nil, *palette, nil); return indexed }},
+		{"FloydSteinberg2D", func() []byte { return FloydSteinberg2D(nil, 0, 0, *palette) }},
+		{"FloydSteinberg2D_sized", func() []byte { return FloydSteinberg2D(nil, 2, 2, *palette) }},
+		{"JarvisJudiceNinke", func() []byte { return JarvisJudiceNinke(nil, *palette) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dither(); len(got) != 0 {
+				t.Errorf("%s() length = %d, want 0", tt.name, len(got))
+			}
+		})
+	}
+}
+
+func TestDitherEmptyPalette(t *testing.T) {
+	pixels := []byte{10, 20, 30, 200, 100, 50, 0, 0, 0, 255, 255, 255}
+	width := len(pixels) / 3
+
+	palettes := []struct {
+		name    string
+		palette Palette
+	}{
+		{"zero value", Palette{}},
+		{"allocated", *NewPalette(4)},
+	}
+
+	for _, p := range palettes {
+		tests := []struct {
+			name   string
+			dither func() []byte
+		}{
+			{"Threshold", func() []byte { return Threshold(pixels, p.palette) }},
+			{"FloydSteinberg", func() []byte { return FloydSteinberg(pixels, p.palette) }},
+			{"FloydSteinbergRow", func() []byte { indexed, _ := FloydSteinbergRow(pixels, p.palette, nil); return indexed }},
+			{"FloydSteinberg2D", func() []byte { return FloydSteinberg2D(pixels, 2, 2, p.palette) }},
+			{"JarvisJudiceNinke", func() []byte { return JarvisJudiceNinke(pixels, p.palette) }},
+		}
+
+		for _, tt := range tests {
+			t.Run(p.name+"/"+tt.name, func(t *testing.T) {
+				got := tt.dither()
+				if len(got) != width {
+					t.Fatalf("%s() length = %d, want %d", tt.name, len(got), width)
+				}
+				for i, idx := range got {
+					if idx != 0 {
+						t.Errorf("%s()[%d] = %d, want 0", tt.name, i, idx)
+					}
+				}
+			})
+		}
+	}
+}
+
+// diagonalRuns counts minority-index pixels that have a minority-index
+// neighbor diagonally down-right and down-left.
+func diagonalRuns(indexed []byte, width, height int) (downRight, downLeft int) {
+	var ones int
+	for _, v := range indexed {
+		ones += int(v)
+	}
+	minority := byte(0)
+	if ones*2 < len(indexed) {
+		minority = 1
+	}
+	for y := 0; y+1 < height; y++ {
+		for x := 0; x < width; x++ {
+			if indexed[y*width+x] != minority {
+				continue
+			}
+			if x+1 < width && indexed[(y+1)*width+x+1] == minority {
+				downRight++
+			}
+			if x > 0 && indexed[(y+1)*width+x-1] == minority {
+				downLeft++
+			}
+		}
+	}
+	return downRight, downLeft
+}
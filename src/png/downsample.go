@@ -0,0 +1,20 @@
+package png
+
+// Downsample16to8 converts pixels of colorType with big-endian 16-bit samples,
+// as stored in 16-bit PNGs, to 8-bit samples the encoder accepts. Each sample
+// is rounded to the nearest 8-bit value (v*255/65535), so 0xFFFF maps to 255
+// and 0x8080 to 128. Bytes past the last whole pixel are dropped.
+func Downsample16to8(pixels []byte, colorType ColorType) []byte {
+	bpp := BytesPerPixel(colorType)
+	out := make([]byte, len(pixels)/(2*bpp)*bpp)
+	for i := range out {
+		out[i] = sample16to8(pixels[2*i], pixels[2*i+1])
+	}
+	return out
+}
+
+// sample16to8 rounds the big-endian 16-bit sample hi, lo to 8 bits.
+func sample16to8(hi, lo byte) uint8 {
+	v := uint32(hi)<<8 | uint32(lo)
+	return uint8((v*255 + 32767) / 65535)
+}
@@ -0,0 +1,77 @@
+package png
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDownsample16to8(t *testing.T) {
+	tests := []struct {
+		name      string
+		pixels    []byte
+		colorType ColorType
+		want      []byte
+	}{
+		{"extremes", []byte{0x00, 0x00, 0xFF, 0xFF, 0x80, 0x80}, ColorRGB, []byte{0, 255, 128}},
+		{"rounds to nearest", []byte{0x00, 0x80, 0x00, 0x81, 0x7F, 0x80}, ColorRGB, []byte{0, 1, 127}},
+		{"drops partial pixel", []byte{0x12, 0x34, 0xFF, 0xFF, 0xFF}, ColorGrayscale, []byte{0x12, 255}},
+		{"RGBA", []byte{0xFF, 0xFF, 0, 0, 0, 0, 0x80, 0x00}, ColorRGBA, []byte{255, 0, 0, 128}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Downsample16to8(tt.pixels, tt.colorType); !bytes.Equal(got, tt.want) {
+				t.Errorf("Downsample16to8() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDownsample16to8Gradient(t *testing.T) {
+	// v*257 is exactly v in 8 bits; +128 still rounds down and +129 rounds up.
+	for _, offset := range []int{0, 128, 129} {
+		var pixels, want []byte
+		for v := 0; v < 255; v++ {
+			s := v*257 + offset
+			pixels = append(pixels, byte(s>>8), byte(s))
+			w := v
+			if offset > 128 {
+				w++
+			}
+			want = append(want, byte(w))
+		}
+
+		if got := Downsample16to8(pixels, ColorGrayscale); !bytes.Equal(got, want) {
+			t.Errorf("offset %d: Downsample16to8() = %v, want %v", offset, got, want)
+		}
+	}
+}
+
+func TestEncodeInputBitDepth16(t *testing.T) {
+	width, height := 16, 4
+	pixels8 := benchPixels(width, height, 3)
+	pixels16 := make([]byte, 0, len(pixels8)*2)
+	for _, v := range pixels8 {
+		pixels16 = append(pixels16, v, v) // v*257
+	}
+
+	opts := BalancedOptions(width, height)
+	opts.ColorType = ColorRGB
+	want, err := EncodeWithOptions(pixels8, opts)
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() 8-bit error = %v", err)
+	}
+
+	opts.InputBitDepth = 16
+	got, err := EncodeWithOptions(pixels16, opts)
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() 16-bit error = %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("16-bit input encoded differently from the equivalent 8-bit input")
+	}
+
+	if _, err := EncodeWithOptions(pixels8, opts); err == nil {
+		t.Error("EncodeWithOptions() with 8-bit sized input and InputBitDepth 16 error = nil, want length mismatch")
+	}
+}
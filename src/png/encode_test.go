@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"compress/zlib"
 	"encoding/binary"
+	"errors"
 	"image/color"
 	stdpng "image/png"
 	"io"
+	"math"
+	"math/rand"
 	"testing"
 
 	"github.com/mac/go-pixo/src/compress"
@@ -351,6 +354,139 @@ func TestEncodeValidation(t *testing.T) {
 	}
 }
 
+func TestEncodeEmptyPixels(t *testing.T) {
+	enc, err := NewEncoder(2, 2, ColorRGB)
+	if err != nil {
+		t.Fatalf("NewEncoder() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"default", enc.opts},
+		{"quantize", func() Options { o := enc.opts; o.MaxColors = 4; return o }()},
+		{"quantize_dithering", func() Options { o := enc.opts; o.MaxColors = 4; o.Dithering = true; return o }()},
+		{"auto_palette", func() Options { o := enc.opts; o.AutoPalette = true; return o }()},
+		{"reduce_color_type", func() Options { o := enc.opts; o.ReduceColorType = true; return o }()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, pixels := range [][]byte{nil, {}} {
+				if _, err := enc.EncodeWithOptions(pixels, tt.opts); err != ErrEmptyPixels {
+					t.Errorf("EncodeWithOptions() error = %v, want %v", err, ErrEmptyPixels)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeFilterStrategyNoneEmitsOnlyFilterNone(t *testing.T) {
+	width, height := 16, 8
+	rgba := make([]byte, width*height*4)
+	for i := range rgba {
+		rgba[i] = uint8(i * 7)
+	}
+
+	tests := []struct {
+		name      string
+		colorType ColorType
+		pixels    []byte
+		modify    func(*Options)
+	}{
+		{"RGBA", ColorRGBA, rgba, nil},
+		{"grayscale", ColorGrayscale, rgba[:width*height], nil},
+		{"reduced", ColorRGBA, rgba, func(o *Options) { o.ReduceColorType = true }},
+		{"quantized", ColorRGBA, rgba, func(o *Options) { o.MaxColors = 16; o.ReduceColorType = false }},
+		{"auto palette", ColorRGB, make([]byte, width*height*3), func(o *Options) { o.AutoPalette = true }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := MaxOptions(width, height)
+			opts.ColorType = tt.colorType
+			opts.FilterStrategy = FilterStrategyNone
+			if tt.modify != nil {
+				tt.modify(&opts)
+			}
+
+			data, err := EncodeWithOptions(tt.pixels, opts)
+			if err != nil {
+				t.Fatalf("EncodeWithOptions() error = %v", err)
+			}
+
+			chunks := parsePNGChunks(t, data)
+			ihdr := findFirstChunk(t, chunks, "IHDR")
+			rowLen := ScanlineLength(width, ColorType(ihdr.Data[9]))
+
+			zr, err := zlib.NewReader(bytes.NewReader(concatChunkData(chunks, "IDAT")))
+			if err != nil {
+				t.Fatalf("zlib.NewReader() error = %v", err)
+			}
+			defer zr.Close()
+			raw, err := io.ReadAll(zr)
+			if err != nil {
+				t.Fatalf("zlib decompression error = %v", err)
+			}
+			if len(raw) != rowLen*height {
+				t.Fatalf("scanline data length = %d, want %d", len(raw), rowLen*height)
+			}
+
+			for y := 0; y < height; y++ {
+				if f := raw[y*rowLen]; f != byte(FilterNone) {
+					t.Errorf("row %d filter byte = %d, want 0", y, f)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeDeterministic(t *testing.T) {
+	width, height := 32, 32
+	pixels := make([]byte, width*height*4)
+	for i := 0; i < width*height; i++ {
+		// 64 colors with equal counts, so palette order relies on tie-breaking
+		pixels[i*4] = uint8(i%64) * 4
+		pixels[i*4+1] = uint8(i%64) * 3
+		pixels[i*4+2] = 255 - uint8(i%64)*2
+		pixels[i*4+3] = 255 - uint8(i%2)*128
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Options)
+	}{
+		{"lossless", nil},
+		{"quantized", func(o *Options) { o.MaxColors = 16; o.ReduceColorType = false }},
+		{"quantized dithered", func(o *Options) { o.MaxColors = 8; o.Dithering = true; o.ReduceColorType = false }},
+		{"auto palette", func(o *Options) { o.AutoPalette = true; o.ReduceColorType = false }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := MaxOptions(width, height)
+			if tt.modify != nil {
+				tt.modify(&opts)
+			}
+
+			first, err := EncodeWithOptions(pixels, opts)
+			if err != nil {
+				t.Fatalf("EncodeWithOptions() error = %v", err)
+			}
+			for run := 1; run < 50; run++ {
+				data, err := EncodeWithOptions(pixels, opts)
+				if err != nil {
+					t.Fatalf("run %d: EncodeWithOptions() error = %v", run, err)
+				}
+				if !bytes.Equal(data, first) {
+					t.Fatalf("run %d: output differs from first encode", run)
+				}
+			}
+		})
+	}
+}
+
 func encodeTestImage(t *testing.T, width, height int, colorType ColorType, pixels []byte) []byte {
 	t.Helper()
 
@@ -594,3 +730,311 @@ func concatChunkData(chunks []parsedChunk, typ string) []byte {
 	}
 	return out
 }
+
+func TestCheckImageSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		width     int
+		height    int
+		maxPixels int
+		want      error
+	}{
+		{"at default limit", 1 << 14, 1 << 14, 0, nil},
+		{"over default limit", 1<<14 + 1, 1 << 14, 0, ErrImageTooLarge},
+		{"at custom limit", 100, 100, 10000, nil},
+		{"one over custom limit", 100, 101, 10000, ErrImageTooLarge},
+		{"product overflows int", math.MaxInt / 2, math.MaxInt / 2, math.MaxInt, ErrImageTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkImageSize(tt.width, tt.height, tt.maxPixels); err != tt.want {
+				t.Errorf("checkImageSize(%d, %d, %d) = %v, want %v", tt.width, tt.height, tt.maxPixels, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncoderRejectsTooLargeImages(t *testing.T) {
+	if _, err := NewEncoder(100000, 100000, ColorRGBA); err != ErrImageTooLarge {
+		t.Errorf("NewEncoder(100000, 100000) error = %v, want %v", err, ErrImageTooLarge)
+	}
+
+	opts := FastOptions(64, 64)
+	opts.MaxPixels = 64 * 63
+	if _, err := NewEncoderWithOptions(opts); err != ErrImageTooLarge {
+		t.Errorf("NewEncoderWithOptions() error = %v, want %v", err, ErrImageTooLarge)
+	}
+
+	enc, err := NewEncoder(1, 1, ColorRGBA)
+	if err != nil {
+		t.Fatalf("NewEncoder() error = %v", err)
+	}
+	huge := FastOptions(1<<20, 1<<20)
+	if _, err := enc.EncodeWithOptions(make([]byte, 4), huge); err != ErrImageTooLarge {
+		t.Errorf("EncodeWithOptions() error = %v, want %v", err, ErrImageTooLarge)
+	}
+}
+
+func TestEncodeIndexed(t *testing.T) {
+	palette := NewPalette(3)
+	palette.AddColor(Color{255, 0, 0})
+	palette.AddColor(Color{0, 255, 0})
+	palette.AddColorWithAlpha(Color{0, 0, 255}, 64)
+
+	indexed := []byte{
+		0, 1, 2,
+		2, 1, 0,
+	}
+
+	data, err := EncodeIndexed(indexed, 3, 2, *palette, FastOptions(0, 0))
+	if err != nil {
+		t.Fatalf("EncodeIndexed() error = %v", err)
+	}
+
+	chunks := parsePNGChunks(t, data)
+	if ihdr := findFirstChunk(t, chunks, "IHDR"); ColorType(ihdr.Data[9]) != ColorIndexed {
+		t.Errorf("IHDR color type = %v, want %v", ColorType(ihdr.Data[9]), ColorIndexed)
+	}
+	findFirstChunk(t, chunks, "PLTE")
+	findFirstChunk(t, chunks, "tRNS")
+
+	want := []byte{
+		255, 0, 0, 255, 0, 255, 0, 255, 0, 0, 255, 64,
+		0, 0, 255, 64, 0, 255, 0, 255, 255, 0, 0, 255,
+	}
+	assertDecodedPixels(t, data, 3, 2, ColorRGBA, want)
+}
+
+func TestEncodeIndexedValidation(t *testing.T) {
+	palette := NewPalette(2)
+	palette.AddColor(Color{0, 0, 0})
+	palette.AddColor(Color{255, 255, 255})
+
+	tests := []struct {
+		name    string
+		indexed []byte
+		palette Palette
+		want    error
+	}{
+		{"index out of range", []byte{0, 1, 2, 0}, *palette, nil},
+		{"empty palette", []byte{0, 0, 0, 0}, *NewPalette(4), ErrMissingPalette},
+		{"wrong length", []byte{0, 1, 0}, *palette, nil},
+		{"empty pixels", nil, *palette, ErrEmptyPixels},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := EncodeIndexed(tt.indexed, 2, 2, tt.palette, FastOptions(0, 0))
+			if err == nil {
+				t.Fatal("EncodeIndexed() error = nil, want error")
+			}
+			if tt.want != nil && err != tt.want {
+				t.Errorf("EncodeIndexed() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeIndexedWithoutPalette(t *testing.T) {
+	if _, err := NewEncoder(2, 2, ColorIndexed); err != ErrMissingPalette {
+		t.Errorf("NewEncoder(ColorIndexed) error = %v, want %v", err, ErrMissingPalette)
+	}
+
+	enc, err := NewEncoder(2, 2, ColorGrayscale)
+	if err != nil {
+		t.Fatalf("NewEncoder() error = %v", err)
+	}
+	opts := enc.opts
+	opts.ColorType = ColorIndexed
+	if _, err := enc.EncodeWithOptions(make([]byte, 4), opts); err != ErrMissingPalette {
+		t.Errorf("EncodeWithOptions(ColorIndexed) error = %v, want %v", err, ErrMissingPalette)
+	}
+}
+
+func TestEncodeRowStride(t *testing.T) {
+	// 3x2 RGB sub-image in rows of 5 pixels; the final row is not padded.
+	tight := []byte{
+		255, 0, 0, 0, 255, 0, 0, 0, 255,
+		10, 20, 30, 40, 50, 60, 70, 80, 90,
+	}
+	stride := 5 * 3
+	padded := make([]byte, stride+9)
+	for i := range padded {
+		padded[i] = 0xEE
+	}
+	copy(padded, tight[:9])
+	copy(padded[stride:], tight[9:])
+
+	// FastOptions filters the strided rows directly; BalancedOptions analyses
+	// the pixels, so the rows are packed first.
+	for name, opts := range map[string]Options{"fast": FastOptions(3, 2), "balanced": BalancedOptions(3, 2)} {
+		t.Run(name, func(t *testing.T) {
+			opts.ColorType = ColorRGB
+			opts.RowStride = stride
+
+			data, err := EncodeWithOptions(padded, opts)
+			if err != nil {
+				t.Fatalf("EncodeWithOptions() error = %v", err)
+			}
+			assertDecodedPixels(t, data, 3, 2, ColorRGB, tight)
+
+			if _, err := EncodeWithOptions(padded[:len(padded)-1], opts); err == nil {
+				t.Error("EncodeWithOptions() with short buffer error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestEncodeGrayscaleQuantized(t *testing.T) {
+	pixels := []byte{0, 0, 255, 255, 0, 255}
+	opts := LossyOptions(3, 2, 2)
+	opts.ColorType = ColorGrayscale
+
+	data, err := EncodeWithOptions(pixels, opts)
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+	img, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	want := []byte{0, 0, 0, 0, 0, 0, 255, 255, 255, 255, 255, 255, 0, 0, 0, 255, 255, 255}
+	if !bytes.Equal(img.Pixels, want) {
+		t.Errorf("decoded pixels = %v, want %v", img.Pixels, want)
+	}
+}
+
+func TestEncodeQuantizedKeepsAlpha(t *testing.T) {
+	// Eight color/alpha pairs, two of which differ only in alpha, fit a
+	// 16-color palette exactly.
+	combos := [][4]byte{
+		{255, 0, 0, 255}, {255, 0, 0, 128}, {0, 255, 0, 255}, {0, 0, 255, 64},
+		{0, 0, 0, 0}, {200, 200, 200, 255}, {30, 60, 90, 200}, {30, 60, 90, 10},
+	}
+	width, height := 8, 8
+	pixels := make([]byte, 0, width*height*4)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := combos[(x+y)%len(combos)]
+			pixels = append(pixels, c[:]...)
+		}
+	}
+
+	for _, mode := range []string{"default", "dithering", "parallel"} {
+		t.Run(mode, func(t *testing.T) {
+			opts := LossyOptions(width, height, 16)
+			opts.Dithering = mode == "dithering"
+			opts.Parallel = mode == "parallel"
+
+			data, err := EncodeWithOptions(pixels, opts)
+			if err != nil {
+				t.Fatalf("EncodeWithOptions() error = %v", err)
+			}
+			chunks := parsePNGChunks(t, data)
+			if ct := ColorType(findFirstChunk(t, chunks, "IHDR").Data[9]); ct != ColorIndexed {
+				t.Fatalf("IHDR color type = %v, want %v", ct, ColorIndexed)
+			}
+			findFirstChunk(t, chunks, "tRNS")
+			assertDecodedPixels(t, data, width, height, ColorRGBA, pixels)
+		})
+	}
+}
+
+func TestEncoderEncodeInto(t *testing.T) {
+	width, height := 24, 16
+	photo := benchPixels(width, height, 4)
+	noise := make([]byte, width*height*4)
+	rand.New(rand.NewSource(7)).Read(noise)
+
+	split := FastOptions(width, height)
+	split.MaxIDATChunkSize = 64
+	withExtras := BalancedOptions(width, height)
+	withExtras.ExtraChunks = []*Chunk{{chunkType: "tEXt", Data: []byte("Comment\x00atlas")}}
+
+	tests := []struct {
+		name   string
+		opts   Options
+		pixels []byte
+	}{
+		{"balanced RGBA", BalancedOptions(width, height), photo},
+		{"lossy indexed", LossyOptions(width, height, 16), photo},
+		{"fully transparent", BalancedOptions(width, height), make([]byte, width*height*4)},
+		{"stored noise", FastOptions(width, height), noise},
+		{"split IDAT noise", split, noise},
+		{"extra chunks", withExtras, photo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder, err := NewEncoderWithOptions(tt.opts)
+			if err != nil {
+				t.Fatalf("NewEncoderWithOptions() error = %v", err)
+			}
+			want, err := encoder.Encode(tt.pixels)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			bound := encoder.MaxEncodedSize()
+			if len(want) > bound {
+				t.Fatalf("Encode() = %d bytes, exceeds MaxEncodedSize() %d", len(want), bound)
+			}
+
+			dst := make([]byte, bound)
+			n, err := encoder.EncodeInto(dst, tt.pixels)
+			if err != nil {
+				t.Fatalf("EncodeInto() error = %v", err)
+			}
+			if !bytes.Equal(dst[:n], want) {
+				t.Errorf("EncodeInto() wrote %d bytes that differ from Encode() (%d bytes)", n, len(want))
+			}
+		})
+	}
+}
+
+func TestEncoderEncodeIntoNilExtraChunk(t *testing.T) {
+	opts := FastOptions(4, 4)
+	opts.ExtraChunks = []*Chunk{{chunkType: "tEXt", Data: []byte("Comment\x00x")}}
+	encoder, err := NewEncoderWithOptions(opts)
+	if err != nil {
+		t.Fatalf("NewEncoderWithOptions() error = %v", err)
+	}
+	// The encoder shares the caller's slice, so the entry can go nil later.
+	opts.ExtraChunks[0] = nil
+
+	dst := make([]byte, encoder.MaxEncodedSize())
+	if _, err := encoder.EncodeInto(dst, benchPixels(4, 4, 4)); !errors.Is(err, ErrInvalidExtraChunk) {
+		t.Errorf("EncodeInto() error = %v, want %v", err, ErrInvalidExtraChunk)
+	}
+}
+
+func TestEncoderEncodeIntoLeavesDstOnError(t *testing.T) {
+	pixels := benchPixels(8, 8, 3)
+	encoder, err := NewEncoder(8, 8, ColorRGB)
+	if err != nil {
+		t.Fatalf("NewEncoder() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		size    int
+		pixels  []byte
+		wantErr error
+	}{
+		{"short buffer", encoder.MaxEncodedSize() - 1, pixels, ErrBufferTooSmall},
+		{"bad pixels", encoder.MaxEncodedSize(), pixels[:len(pixels)-1], nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := bytes.Repeat([]byte{0xAA}, tt.size)
+			n, err := encoder.EncodeInto(dst, tt.pixels)
+			if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("EncodeInto() = %d, %v; want error %v", n, err, tt.wantErr)
+			}
+			if n != 0 || !bytes.Equal(dst, bytes.Repeat([]byte{0xAA}, tt.size)) {
+				t.Errorf("EncodeInto() = %d and modified dst on error", n)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"math"
 )
 
 type Encoder struct {
@@ -17,11 +18,18 @@ func NewEncoder(width, height int, colorType ColorType) (*Encoder, error) {
 	if width <= 0 || height <= 0 {
 		return nil, ErrInvalidDimensions
 	}
+	if err := checkImageSize(width, height, DefaultMaxPixels); err != nil {
+		return nil, err
+	}
 
 	// Validate parameters by creating a dummy IHDR
 	if _, err := NewIHDRData(width, height, 8, uint8(colorType)); err != nil {
 		return nil, err
 	}
+	// Indexed output needs a PLTE, which only EncodeIndexed or quantization supplies.
+	if colorType == ColorIndexed {
+		return nil, ErrMissingPalette
+	}
 
 	opts := FastOptions(width, height)
 	opts.ColorType = colorType
@@ -38,6 +46,12 @@ func NewEncoderWithOptions(opts Options) (*Encoder, error) {
 	if opts.Width <= 0 || opts.Height <= 0 {
 		return nil, ErrInvalidDimensions
 	}
+	if err := checkImageSize(opts.Width, opts.Height, opts.MaxPixels); err != nil {
+		return nil, err
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
 
 	// Validate parameters by creating a dummy IHDR
 	if _, err := NewIHDRData(opts.Width, opts.Height, 8, uint8(opts.ColorType)); err != nil {
@@ -56,50 +70,151 @@ func (e *Encoder) Encode(pixels []byte) ([]byte, error) {
 	return e.EncodeWithOptions(pixels, e.opts)
 }
 
+// EncodeInto encodes pixels like Encode but assembles the PNG directly in dst,
+// avoiding a separate output allocation and copy, and returns the number of
+// bytes written. dst must hold at least MaxEncodedSize bytes, the largest PNG
+// the encoder's options can produce, or EncodeInto returns ErrBufferTooSmall
+// without encoding. dst is left unchanged on any error, except that if the PNG
+// still outgrows dst, which means the bound was wrong, the part that fit may
+// have been written before ErrBufferTooSmall is returned.
+func (e *Encoder) EncodeInto(dst, pixels []byte) (int, error) {
+	if len(dst) < e.MaxEncodedSize() {
+		return 0, ErrBufferTooSmall
+	}
+	buf := bytes.NewBuffer(dst[:0])
+	if err := encode(buf, pixels, e.opts); err != nil {
+		return 0, err
+	}
+	if n := buf.Len(); n > 0 && &buf.Bytes()[0] != &dst[0] {
+		return 0, fmt.Errorf("%w: the %d-byte PNG outgrew dst and was reallocated", ErrBufferTooSmall, n)
+	}
+	return buf.Len(), nil
+}
+
+// MaxEncodedSize returns an upper bound on the size of any PNG the encoder
+// produces: the stored-block bound from MaxIDATSize plus 12 bytes of framing
+// for each IDAT chunk opts.MaxIDATChunkSize splits it into, the signature,
+// IHDR, IEND, a full PLTE and tRNS, and opts.ExtraChunks.
+func (e *Encoder) MaxEncodedSize() int {
+	idat := MaxIDATSize(e.opts.Width, e.opts.Height, e.opts.ColorType)
+	if idat == math.MaxInt {
+		return math.MaxInt
+	}
+	if len(e.opts.ZlibDictionary) > 0 {
+		idat += 4 // DICTID
+	}
+
+	size := int64(len(Signature())) + 25 + int64(idatChunksSize(idat, e.opts.MaxIDATChunkSize)) + 12
+	size += 12 + 256*3 // PLTE
+	size += 12 + 256   // tRNS
+	for _, c := range e.opts.ExtraChunks {
+		if c != nil { // encoding rejects nil entries
+			size += 12 + int64(len(c.Data))
+		}
+	}
+	return clampToInt(size)
+}
+
+// EncodeWithOptions encodes pixels as a PNG using opts.
+// It returns ErrInvalidDimensions for a non-positive size, ErrImageTooLarge when
+// Width*Height exceeds opts.MaxPixels, and ErrEmptyPixels when pixels is empty;
+// ErrMissingPalette for ColorIndexed, which needs EncodeIndexed to supply the
+// palette; any other length mismatch is reported as an error. With opts.RowStride set,
+// pixels must hold Height-1 full strides plus one Width*bpp row; with
+// opts.InputBitDepth 16, rows are twice as long.
 func (e *Encoder) EncodeWithOptions(pixels []byte, opts Options) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encode(&buf, pixels, opts); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encode appends the PNG for pixels to buf. Every step that can fail runs
+// before the first write, so buf is unchanged on error.
+func encode(buf *bytes.Buffer, pixels []byte, opts Options) error {
+	if opts.Width <= 0 || opts.Height <= 0 {
+		return ErrInvalidDimensions
+	}
+	if err := checkImageSize(opts.Width, opts.Height, opts.MaxPixels); err != nil {
+		return err
+	}
+	if len(pixels) == 0 {
+		return ErrEmptyPixels
+	}
+	if opts.ColorType == ColorIndexed {
+		return ErrMissingPalette
+	}
+
 	colorType := opts.ColorType
-	bpp := BytesPerPixel(colorType)
-	expectedSize := opts.Width * opts.Height * bpp
-	if len(pixels) != expectedSize {
-		return nil, fmt.Errorf("png: pixel count mismatch: got %d bytes, want %d", len(pixels), expectedSize)
+	rowSize := opts.inputRowSize()
+	if err := checkRowStride(len(pixels), rowSize, opts.Height, opts.RowStride); err != nil {
+		return err
+	}
+	if opts.needsPackedRows() {
+		if opts.RowStride != 0 && opts.RowStride != rowSize {
+			pixels = packRows(pixels, rowSize, opts.Height, opts.RowStride)
+		}
+		opts.RowStride = 0
+	}
+	if opts.InputBitDepth == 16 {
+		pixels = Downsample16to8(pixels, colorType)
 	}
 
+	opts.reportProgress(ProgressAnalysis, 0)
 	processedPixels := pixels
 
-	// 0. Quantization (Lossy) - before other optimizations
+	// 0a. Pre-encode Transforms - alpha flattening and optional adjustments
+	processedPixels, colorType = applyTransforms(processedPixels, colorType, opts)
+	// TransparentColor names a color of these pixels, whatever the reductions do
+	keyType := colorType
+
+	// 0b. Fully Transparent - checked before quantization or reduction rewrites
+	// the pixels; the candidate is encoded in step 5, where it is compared
+	fullyTransparent := (opts.OptimizeAlpha || opts.AutoPalette) && IsFullyTransparent(processedPixels, colorType)
+
+	// 0c. Quantization (Lossy) - before other optimizations
 	if opts.MaxColors > 0 && opts.MaxColors < 256 {
 		var indexedPixels []byte
 		var palette Palette
 
-		if opts.Dithering {
-			indexedPixels, palette = QuantizeWithDithering(processedPixels, int(colorType), opts.MaxColors)
-		} else {
-			indexedPixels, palette = Quantize(processedPixels, int(colorType), opts.MaxColors)
-		}
-
-		var buf bytes.Buffer
-
-		if err := writeSignature(&buf); err != nil {
-			return nil, err
+		// The quantizers read RGB triples
+		if colorType == ColorGrayscale {
+			processedPixels, colorType = expandGrayToRGB(processedPixels), ColorRGB
 		}
 
-		if err := writeIHDR(&buf, opts.Width, opts.Height, ColorIndexed); err != nil {
-			return nil, err
+		// Translucent RGBA keeps its alpha in the palette, written as tRNS
+		hasAlpha := colorType == ColorRGBA && !CanReduceToRGB(processedPixels, opts.Width, opts.Height)
+		if hasAlpha && opts.OptimizeAlpha {
+			processedPixels = OptimizeAlpha(processedPixels, colorType)
 		}
 
-		if err := WritePLTE(&buf, palette); err != nil {
-			return nil, err
+		switch {
+		case hasAlpha && opts.Dithering:
+			palette = quantizePaletteWithAlpha(processedPixels, int(colorType), opts.MaxColors)
+			indexedPixels = FloydSteinberg2DWithConfig(processedPixels, opts.Width, opts.Height, palette, DitherConfig{RGBA: true})
+		case hasAlpha && opts.Parallel:
+			palette = quantizePaletteWithAlpha(processedPixels, int(colorType), opts.MaxColors)
+			indexedPixels = mapToPaletteParallel(processedPixels, opts.Width, 4, &palette, 0, mapToPaletteWithAlpha)
+		case hasAlpha:
+			indexedPixels, palette = QuantizeWithAlpha(processedPixels, int(colorType), opts.MaxColors)
+		case opts.Dithering:
+			indexedPixels, palette = QuantizeWithDithering(processedPixels, int(colorType), opts.MaxColors)
+		case opts.Parallel:
+			palette = quantizePalette(processedPixels, int(colorType), opts.MaxColors)
+			indexedPixels = QuantizeToPaletteParallel(processedPixels, opts.Width, int(colorType), palette, 0)
+		default:
+			indexedPixels, palette = Quantize(processedPixels, int(colorType), opts.MaxColors)
 		}
 
-		if err := WriteIDATWithOptions(&buf, indexedPixels, opts.Width, opts.Height, ColorIndexed, opts); err != nil {
-			return nil, err
-		}
+		return encodeIndexed(buf, indexedPixels, palette, opts)
+	}
 
-		if err := writeIEND(&buf); err != nil {
-			return nil, err
+	// 0d. Exact Palette (Lossless) - when the image has few enough colors
+	if opts.AutoPalette {
+		if indexedPixels, palette, ok := BuildExactPalette(processedPixels, colorType); ok {
+			return encodeIndexed(buf, indexedPixels, palette, opts)
 		}
-
-		return buf.Bytes(), nil
 	}
 
 	// 1. Color Reduction (Lossless)
@@ -108,16 +223,14 @@ func (e *Encoder) EncodeWithOptions(pixels []byte, opts Options) ([]byte, error)
 			var err error
 			processedPixels, colorType, err = ReduceToRGB(processedPixels, opts.Width, opts.Height)
 			if err != nil {
-				return nil, err
+				return err
 			}
-			bpp = BytesPerPixel(colorType)
 		} else if CanReduceToGrayscale(processedPixels, opts.Width, opts.Height, colorType) {
 			var err error
 			processedPixels, colorType, err = ReduceToGrayscale(processedPixels, opts.Width, opts.Height, colorType)
 			if err != nil {
-				return nil, err
+				return err
 			}
-			bpp = BytesPerPixel(colorType)
 		}
 	}
 
@@ -126,34 +239,200 @@ func (e *Encoder) EncodeWithOptions(pixels []byte, opts Options) ([]byte, error)
 		processedPixels = OptimizeAlpha(processedPixels, colorType)
 	}
 
-	var buf bytes.Buffer
-
-	// 3. Write PNG Signature
-	if err := writeSignature(&buf); err != nil {
-		return nil, err
+	// 3. Signature, IHDR and ancillary chunks - assembled apart so nothing
+	// reaches buf until the image data has been compressed
+	var head bytes.Buffer
+	if err := writeSignature(&head); err != nil {
+		return err
 	}
-
-	// 4. Write IHDR Chunk (Critical)
-	if err := writeIHDR(&buf, opts.Width, opts.Height, colorType); err != nil {
-		return nil, err
+	if err := writeIHDR(&head, opts.Width, opts.Height, colorType); err != nil {
+		return err
+	}
+	if err := writeExtraChunks(&head, opts.ExtraChunks); err != nil {
+		return err
+	}
+	if opts.TransparentColor != nil {
+		if err := writeColorKey(&head, keyType, colorType, *opts.TransparentColor); err != nil {
+			return err
+		}
 	}
 
 	// Note: If we had ancillary chunks (metadata), we would check opts.StripMetadata
 	// here before writing them. Currently, we only write required chunks.
 
-	// 5. Write IDAT Chunk (Critical) - Includes Filter Strategy and Deflate Compression
-	if err := WriteIDATWithOptions(&buf, processedPixels, opts.Width, opts.Height, colorType, opts); err != nil {
-		return nil, err
+	// 4. IDAT data - Filter Strategy and Deflate Compression
+	zlibData, err := idatData(processedPixels, opts.Width, opts.Height, colorType, opts)
+	if err != nil {
+		return err
+	}
+
+	// 5. Fully Transparent - a single transparent palette entry is usually smaller;
+	// kept only if it beats the regular encode (PLTE+tRNS overhead wins on tiny images)
+	if fullyTransparent {
+		palette := NewPalette(1)
+		palette.AddColorWithAlpha(Color{}, 0)
+		candidate := opts
+		candidate.Progress = nil
+		var transparent bytes.Buffer
+		if err := encodeIndexed(&transparent, make([]byte, opts.Width*opts.Height), *palette, candidate); err != nil {
+			return err
+		}
+		if transparent.Len() < head.Len()+idatChunksSize(len(zlibData), opts.MaxIDATChunkSize)+12 {
+			buf.Write(transparent.Bytes())
+			opts.reportProgress(ProgressDone, 1)
+			return nil
+		}
+	}
+
+	// 6. Write the PNG - the chunks above, IDAT, and IEND
+	return writePNG(buf, head.Bytes(), zlibData, opts)
+}
+
+// needsPackedRows reports whether any stage before filtering works on the
+// whole pixel buffer, so RowStride padding has to be removed first. Otherwise
+// the strided rows are filtered in place.
+func (o Options) needsPackedRows() bool {
+	return o.InputBitDepth == 16 || o.hasTransforms() || o.MaxColors > 0 ||
+		o.AutoPalette || o.ReduceColorType || o.OptimizeAlpha
+}
+
+// packRows copies height rows of rowSize bytes, stride bytes apart, into a
+// tightly packed buffer.
+func packRows(pixels []byte, rowSize, height, stride int) []byte {
+	packed := make([]byte, rowSize*height)
+	for y := 0; y < height; y++ {
+		copy(packed[y*rowSize:(y+1)*rowSize], pixels[y*stride:y*stride+rowSize])
 	}
+	return packed
+}
 
-	// 6. Write IEND Chunk (Critical)
-	if err := writeIEND(&buf); err != nil {
+// EncodeIndexed writes an indexed PNG directly from palette indices (one byte per
+// pixel) without quantizing. Every index must refer to a color in palette.
+// Palette alpha, if any, is written as a tRNS chunk. Width and height override opts.
+func EncodeIndexed(indexed []byte, width, height int, palette Palette, opts Options) ([]byte, error) {
+	if width <= 0 || height <= 0 {
+		return nil, ErrInvalidDimensions
+	}
+	if err := checkImageSize(width, height, opts.MaxPixels); err != nil {
 		return nil, err
 	}
+	if len(indexed) == 0 {
+		return nil, ErrEmptyPixels
+	}
+	if len(indexed) != width*height {
+		return nil, fmt.Errorf("png: pixel count mismatch: got %d bytes, want %d", len(indexed), width*height)
+	}
+
+	numColors := palette.Len()
+	if numColors == 0 {
+		return nil, ErrMissingPalette
+	}
+	if numColors > 256 {
+		return nil, fmt.Errorf("png: palette must have 1 to 256 colors, got %d", numColors)
+	}
+	for i, idx := range indexed {
+		if int(idx) >= numColors {
+			return nil, fmt.Errorf("png: pixel %d uses palette index %d, palette has %d colors", i, idx, numColors)
+		}
+	}
 
+	palette.NumColors = numColors
+	opts.Width = width
+	opts.Height = height
+	var buf bytes.Buffer
+	if err := encodeIndexed(&buf, indexed, palette, opts); err != nil {
+		return nil, err
+	}
 	return buf.Bytes(), nil
 }
 
+// encodeIndexed appends a complete indexed PNG (IHDR, PLTE, optional tRNS,
+// IDAT, IEND) to buf, leaving buf unchanged on error.
+func encodeIndexed(buf *bytes.Buffer, indexedPixels []byte, palette Palette, opts Options) error {
+	// Indices are always one tightly packed byte per pixel.
+	opts.RowStride = 0
+	if opts.TrimPalette {
+		indexedPixels, palette = trimPalette(indexedPixels, palette)
+	}
+	if opts.SortPalette {
+		// Sort a copy so the caller's palette is left alone.
+		palette.Colors = append([]Color(nil), palette.Colors...)
+		if palette.Alpha != nil {
+			palette.Alpha = append([]uint8(nil), palette.Alpha...)
+		}
+		indexedPixels = palette.SortForCompression(indexedPixels)
+	}
+
+	var head bytes.Buffer
+	if err := writeSignature(&head); err != nil {
+		return err
+	}
+
+	if err := writeIHDR(&head, opts.Width, opts.Height, ColorIndexed); err != nil {
+		return err
+	}
+
+	if err := writeExtraChunks(&head, opts.ExtraChunks); err != nil {
+		return err
+	}
+
+	if err := WritePLTE(&head, palette); err != nil {
+		return err
+	}
+
+	// WriteTRNS writes nothing when OptimizeTRNS finds every entry opaque.
+	alphaValues, _ := ExtractAlphaFromPixels(nil, palette)
+	if err := WriteTRNS(&head, OptimizeTRNS(alphaValues)); err != nil {
+		return err
+	}
+
+	zlibData, err := idatData(indexedPixels, opts.Width, opts.Height, ColorIndexed, opts)
+	if err != nil {
+		return err
+	}
+
+	return writePNG(buf, head.Bytes(), zlibData, opts)
+}
+
+// writePNG writes head, which holds the signature and every chunk before the
+// image data, then zlibData as IDAT chunks, then IEND.
+func writePNG(w io.Writer, head, zlibData []byte, opts Options) error {
+	if _, err := w.Write(head); err != nil {
+		return err
+	}
+	if err := writeIDATChunks(w, zlibData, opts.MaxIDATChunkSize); err != nil {
+		return err
+	}
+	if err := writeIEND(w); err != nil {
+		return err
+	}
+
+	opts.reportProgress(ProgressDone, 1)
+	return nil
+}
+
+// writeExtraChunks writes caller-supplied ancillary chunks in order. Nil and
+// critical chunks, and chunks that must follow PLTE, are rejected (see
+// Options.ExtraChunks) before anything is written.
+func writeExtraChunks(w io.Writer, chunks []*Chunk) error {
+	for i, c := range chunks {
+		switch {
+		case c == nil:
+			return fmt.Errorf("%w: ExtraChunks[%d] is nil", ErrInvalidExtraChunk, i)
+		case c.IsCritical():
+			return fmt.Errorf("%w: ExtraChunks[%d] is critical chunk %s", ErrInvalidExtraChunk, i, c.Type())
+		case chunksAfterPLTE[c.Type()]:
+			return fmt.Errorf("%w: %s cannot be an extra chunk", ErrInvalidChunkOrder, c.Type())
+		}
+	}
+	for _, c := range chunks {
+		if _, err := c.WriteTo(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func writeSignature(w io.Writer) error {
 	_, err := w.Write(Signature())
 	return err
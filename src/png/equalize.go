@@ -0,0 +1,86 @@
+package png
+
+import "math"
+
+// EqualizeGrayscale stretches the contrast of 8-bit single-channel pixels by
+// histogram equalization: each level is remapped through the normalized cumulative
+// histogram so the output spreads across 0-255. A single-level image is returned
+// unchanged (as a copy).
+func EqualizeGrayscale(pixels []byte) []byte {
+	lut := equalizationTable(pixels)
+	result := make([]byte, len(pixels))
+	for i, v := range pixels {
+		result[i] = lut[v]
+	}
+	return result
+}
+
+// EqualizeLuminance equalizes the Rec.601 luma of RGB or RGBA pixels and scales
+// each pixel's channels by the same factor, which preserves hue. Channels that
+// would exceed 255 are clipped. Alpha is kept as is; grayscale input is passed to
+// EqualizeGrayscale and other color types are returned as a copy.
+func EqualizeLuminance(pixels []byte, colorType ColorType) []byte {
+	switch colorType {
+	case ColorGrayscale:
+		return EqualizeGrayscale(pixels)
+	case ColorRGB, ColorRGBA:
+	default:
+		return append([]byte(nil), pixels...)
+	}
+
+	luma := ConvertToGrayscale(pixels, colorType, GrayscaleRec601)
+	lut := equalizationTable(luma)
+
+	bpp := BytesPerPixel(colorType)
+	result := append([]byte(nil), pixels...)
+	for i, y := range luma {
+		offset := i * bpp
+		target := lut[y]
+		if y == 0 {
+			// No hue to preserve; map black to the equalized gray level.
+			result[offset], result[offset+1], result[offset+2] = target, target, target
+			continue
+		}
+		scale := float64(target) / float64(y)
+		for c := 0; c < 3; c++ {
+			result[offset+c] = uint8(clampInt(int(math.Round(float64(pixels[offset+c]) * scale))))
+		}
+	}
+	return result
+}
+
+// equalizationTable builds the level mapping for histogram equalization of values.
+func equalizationTable(values []byte) [256]uint8 {
+	var hist [256]int
+	for _, v := range values {
+		hist[v]++
+	}
+
+	var lut [256]uint8
+	cdfMin := 0
+	for _, count := range hist {
+		if count > 0 {
+			cdfMin = count
+			break
+		}
+	}
+
+	n := len(values)
+	if n == cdfMin {
+		// Empty or single-level input: identity mapping.
+		for i := range lut {
+			lut[i] = uint8(i)
+		}
+		return lut
+	}
+
+	cdf := 0
+	for i, count := range hist {
+		cdf += count
+		if cdf < cdfMin {
+			continue
+		}
+		lut[i] = uint8(math.Round(float64(cdf-cdfMin) * 255 / float64(n-cdfMin)))
+	}
+	return lut
+}
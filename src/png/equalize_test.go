@@ -0,0 +1,102 @@
+package png
+
+import (
+	"bytes"
+	"testing"
+)
+
+func byteRange(pixels []byte, stride int) (lo, hi byte) {
+	lo, hi = 255, 0
+	for i := 0; i < len(pixels); i += stride {
+		if pixels[i] < lo {
+			lo = pixels[i]
+		}
+		if pixels[i] > hi {
+			hi = pixels[i]
+		}
+	}
+	return lo, hi
+}
+
+func TestEqualizeGrayscale(t *testing.T) {
+	pixels := make([]byte, 400)
+	for i := range pixels {
+		pixels[i] = 100 + uint8(i%41) // 100-140
+	}
+
+	got := EqualizeGrayscale(pixels)
+	lo, hi := byteRange(got, 1)
+	if lo > 10 || hi < 245 {
+		t.Errorf("EqualizeGrayscale() range = %d-%d, want close to 0-255", lo, hi)
+	}
+
+	// Monotonic: brighter input never maps darker
+	for i := 1; i < 41; i++ {
+		if got[i] < got[i-1] {
+			t.Errorf("level %d mapped to %d, below level %d (%d)", pixels[i], got[i], pixels[i-1], got[i-1])
+		}
+	}
+}
+
+func TestEqualizeGrayscaleSingleLevel(t *testing.T) {
+	pixels := []byte{77, 77, 77}
+	if got := EqualizeGrayscale(pixels); !bytes.Equal(got, pixels) {
+		t.Errorf("EqualizeGrayscale() = %v, want %v", got, pixels)
+	}
+	if got := EqualizeGrayscale(nil); len(got) != 0 {
+		t.Errorf("EqualizeGrayscale(nil) = %v, want empty", got)
+	}
+}
+
+func TestEqualizeLuminance(t *testing.T) {
+	// Dim reddish RGBA pixels with gray levels 100-140 and varying alpha
+	var pixels []byte
+	for i := 0; i < 41; i++ {
+		v := 100 + uint8(i)
+		pixels = append(pixels, v, v/2, v/4, uint8(i*6))
+	}
+
+	got := EqualizeLuminance(pixels, ColorRGBA)
+	lo, hi := byteRange(ConvertToGrayscale(got, ColorRGBA, GrayscaleRec601), 1)
+	if lo > 10 || hi < 200 {
+		t.Errorf("luma range = %d-%d, want stretched toward 0-255", lo, hi)
+	}
+
+	for i := 0; i < len(got); i += 4 {
+		if got[i+3] != pixels[i+3] {
+			t.Errorf("pixel %d alpha = %d, want %d", i/4, got[i+3], pixels[i+3])
+		}
+		if got[i] < got[i+1] || got[i+1] < got[i+2] {
+			t.Errorf("pixel %d = %v, want R >= G >= B (hue preserved)", i/4, got[i:i+3])
+		}
+	}
+}
+
+func TestEncodeEqualize(t *testing.T) {
+	width, height := 8, 8
+	pixels := make([]byte, width*height)
+	for i := range pixels {
+		pixels[i] = 100 + uint8(i%41)
+	}
+
+	opts := FastOptions(width, height)
+	opts.ColorType = ColorGrayscale
+	opts.Equalize = true
+
+	data, err := EncodeWithOptions(pixels, opts)
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+	plain := opts
+	plain.Equalize = false
+	plainData, err := EncodeWithOptions(pixels, plain)
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+	if bytes.Equal(data, plainData) {
+		t.Error("Equalize had no effect on the encoded image")
+	}
+	if pixels[0] != 100 {
+		t.Error("EncodeWithOptions() modified the input pixels")
+	}
+}
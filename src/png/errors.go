@@ -11,8 +11,18 @@ func (e *PngError) Error() string {
 }
 
 var (
-	ErrInvalidSignature  = &PngError{"invalid PNG signature"}
-	ErrUnknownChunkType  = &PngError{"unknown chunk type"}
-	ErrInvalidDimensions = &PngError{"invalid image dimensions"}
-	ErrInvalidChunkData  = &PngError{"invalid chunk data"}
+	ErrInvalidSignature   = &PngError{"invalid PNG signature"}
+	ErrUnknownChunkType   = &PngError{"unknown chunk type"}
+	ErrInvalidDimensions  = &PngError{"invalid image dimensions"}
+	ErrInvalidChunkData   = &PngError{"invalid chunk data"}
+	ErrEmptyPixels        = &PngError{"empty pixel data"}
+	ErrInvalidChunkType   = &PngError{"invalid chunk type"}
+	ErrInvalidChunkOrder  = &PngError{"invalid chunk order"}
+	ErrImageTooLarge      = &PngError{"image exceeds maximum pixel count"}
+	ErrBufferTooSmall     = &PngError{"output buffer too small"}
+	ErrMissingPalette     = &PngError{"indexed color requires a palette"}
+	ErrDecompressionLimit = &PngError{"decompressed image data exceeds limit"}
+	ErrInvalidExtraChunk  = &PngError{"extra chunks must be non-nil ancillary chunks"}
+	ErrUnsupportedPNG     = &PngError{"unsupported PNG format"}
+	ErrBudgetUnmet        = &PngError{"cannot encode within size budget"}
 )
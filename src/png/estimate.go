@@ -0,0 +1,107 @@
+package png
+
+import (
+	"math"
+
+	"github.com/mac/go-pixo/src/compress"
+)
+
+// pngOverhead is the size of a PNG apart from the IDAT payload and any extra
+// chunks: signature (8), IHDR (25), IDAT framing (12), and IEND (12).
+const pngOverhead = 8 + 25 + 12 + 12
+
+// estimateHeaderBits approximates the code-length header of one dynamic block.
+const estimateHeaderBits = 60 * 8
+
+// EstimateEncodedSize approximates the size in bytes of the PNG that encoding
+// pixels at colorType with opts would produce, much faster than encoding. It
+// filters rows with opts.FilterStrategy, runs one fast LZ77 pass, and prices
+// the resulting symbols under the fixed Huffman codes and by their entropy,
+// instead of building and writing Huffman codes.
+// Pixel transforms, quantization, and color-type or palette reduction are not
+// applied, so the estimate is for the image as given. It is only an estimate,
+// typically within about 10% of the real size for photos and looser for
+// tiny or highly repetitive images. It returns 0 if pixels does not match
+// width, height, colorType, and opts.RowStride.
+func EstimateEncodedSize(pixels []byte, width, height int, colorType ColorType, opts Options) int {
+	scanlines, _, err := BuildScanlinesWithStride(pixels, width, height, opts.RowStride, colorType, opts.FilterStrategy)
+	if err != nil {
+		return 0
+	}
+
+	lz77 := compress.NewLZ77Encoder()
+	lz77.SetCompressionLevel(1)
+
+	var litLen [286]int
+	var dist [30]int
+	extraBits := 0
+	for _, tok := range lz77.Encode(scanlines) {
+		if tok.IsLiteral {
+			litLen[tok.Literal]++
+			continue
+		}
+		lc, lExtra, _ := compress.LengthCode(int(tok.Match.Length))
+		dc, dExtra, _ := compress.DistanceCode(int(tok.Match.Distance))
+		litLen[lc]++
+		dist[dc]++
+		extraBits += lExtra + dExtra
+	}
+	litLen[256]++ // end of block
+
+	// The encoder picks the smallest of a dynamic, fixed, or stored block.
+	dynamicBits := float64(estimateHeaderBits+extraBits) + entropyBits(litLen[:]) + entropyBits(dist[:])
+	fixedBits := float64(3 + extraBits + fixedHuffmanBits(litLen[:], dist[:]))
+	deflateSize := int(math.Ceil(math.Min(dynamicBits, fixedBits) / 8))
+	if stored := compress.StoredBlocksSize(len(scanlines)); stored < deflateSize {
+		deflateSize = stored
+	}
+
+	size := pngOverhead + 2 + deflateSize + 4 // zlib header and Adler-32
+	if opts.MaxIDATChunkSize > 0 {
+		extraIDATs := (2 + deflateSize + 4 - 1) / opts.MaxIDATChunkSize
+		size += 12 * extraIDATs
+	}
+	for _, c := range opts.ExtraChunks {
+		size += 12 + len(c.Data)
+	}
+	return size
+}
+
+// fixedHuffmanBits returns the exact cost of the symbols under the fixed
+// Huffman codes (RFC 1951 section 3.2.6), excluding extra bits.
+func fixedHuffmanBits(litLen, dist []int) int {
+	bits := 0
+	for sym, f := range litLen {
+		switch {
+		case sym < 144:
+			bits += f * 8
+		case sym < 256:
+			bits += f * 9
+		case sym < 280:
+			bits += f * 7
+		default:
+			bits += f * 8
+		}
+	}
+	for _, f := range dist {
+		bits += f * 5
+	}
+	return bits
+}
+
+// entropyBits returns the Shannon entropy of freqs in bits, charging at least
+// one bit per symbol as a Huffman code must.
+func entropyBits(freqs []int) float64 {
+	total := 0
+	for _, f := range freqs {
+		total += f
+	}
+
+	var bits float64
+	for _, f := range freqs {
+		if f > 0 {
+			bits += float64(f) * math.Max(1, math.Log2(float64(total)/float64(f)))
+		}
+	}
+	return bits
+}
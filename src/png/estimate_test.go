@@ -0,0 +1,45 @@
+package png
+
+import "testing"
+
+func TestEstimateEncodedSize(t *testing.T) {
+	const size = 128
+	gradient := make([]byte, size*size*3)
+	for i := range gradient {
+		gradient[i] = byte(i / 3 % size)
+	}
+
+	tests := []struct {
+		name     string
+		pixels   []byte
+		min, max float64 // allowed estimate/actual ratio
+	}{
+		{"photo", benchPixels(size, size, 3), 0.8, 1.25},
+		{"gradient", gradient, 0.5, 2},
+		{"flat", make([]byte, size*size*3), 0.5, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := BalancedOptions(size, size)
+			opts.ColorType = ColorRGB
+			opts.ReduceColorType = false
+
+			data, err := EncodeWithOptions(tt.pixels, opts)
+			if err != nil {
+				t.Fatalf("EncodeWithOptions() error = %v", err)
+			}
+			est := EstimateEncodedSize(tt.pixels, size, size, ColorRGB, opts)
+			if ratio := float64(est) / float64(len(data)); ratio < tt.min || ratio > tt.max {
+				t.Errorf("EstimateEncodedSize() = %d, actual %d bytes (ratio %.2f), want ratio in [%.2f, %.2f]",
+					est, len(data), ratio, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestEstimateEncodedSizeInvalidInput(t *testing.T) {
+	if got := EstimateEncodedSize(make([]byte, 5), 2, 1, ColorRGB, Options{}); got != 0 {
+		t.Errorf("EstimateEncodedSize() with short pixels = %d, want 0", got)
+	}
+}
@@ -0,0 +1,37 @@
+package png
+
+// BuildExactPalette builds a palette containing every distinct color in the image,
+// without any color loss. Palette entries are assigned in order of first appearance.
+// Alpha is preserved for RGBA input so the palette can be written with a tRNS chunk.
+// Returns false if the image has more than 256 distinct colors or is empty.
+func BuildExactPalette(pixels []byte, colorType ColorType) ([]byte, Palette, bool) {
+	bpp := BytesPerPixel(colorType)
+	numPixels := len(pixels) / bpp
+	if numPixels == 0 {
+		return nil, Palette{}, false
+	}
+
+	indices := make(map[AlphaColor]uint8)
+	palette := NewPalette(256)
+	indexed := make([]byte, numPixels)
+
+	for i := 0; i < numPixels; i++ {
+		c := alphaColorAt(pixels, i*bpp, bpp)
+
+		idx, ok := indices[c]
+		if !ok {
+			newIdx := palette.AddColorWithAlpha(c.Color, c.A)
+			if newIdx < 0 {
+				return nil, Palette{}, false
+			}
+			idx = uint8(newIdx)
+			indices[c] = idx
+		}
+		indexed[i] = idx
+	}
+
+	palette.Colors = palette.Colors[:palette.NumColors]
+	palette.Alpha = palette.Alpha[:palette.NumColors]
+
+	return indexed, *palette, true
+}
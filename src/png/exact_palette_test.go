@@ -0,0 +1,133 @@
+package png
+
+import (
+	"testing"
+)
+
+func TestBuildExactPalette(t *testing.T) {
+	pixels := []byte{
+		255, 0, 0, 0, 255, 0,
+		255, 0, 0, 0, 0, 255,
+	}
+
+	indexed, palette, ok := BuildExactPalette(pixels, ColorRGB)
+	if !ok {
+		t.Fatalf("BuildExactPalette() ok = false, want true")
+	}
+
+	if palette.NumColors != 3 {
+		t.Errorf("BuildExactPalette() palette size = %v, want 3", palette.NumColors)
+	}
+
+	want := []byte{0, 1, 0, 2}
+	for i := range want {
+		if indexed[i] != want[i] {
+			t.Errorf("BuildExactPalette() indexed[%v] = %v, want %v", i, indexed[i], want[i])
+		}
+	}
+
+	if palette.HasAlpha() {
+		t.Errorf("BuildExactPalette() RGB palette HasAlpha() = true, want false")
+	}
+}
+
+func TestBuildExactPaletteTooManyColors(t *testing.T) {
+	pixels := make([]byte, 0, 257*3)
+	for i := 0; i < 257; i++ {
+		pixels = append(pixels, byte(i), byte(i>>8), 0)
+	}
+
+	if _, _, ok := BuildExactPalette(pixels, ColorRGB); ok {
+		t.Errorf("BuildExactPalette() with 257 colors ok = true, want false")
+	}
+}
+
+func TestBuildExactPaletteKeepsAlpha(t *testing.T) {
+	pixels := []byte{
+		10, 20, 30, 255,
+		10, 20, 30, 0,
+	}
+
+	indexed, palette, ok := BuildExactPalette(pixels, ColorRGBA)
+	if !ok {
+		t.Fatalf("BuildExactPalette() ok = false, want true")
+	}
+
+	if palette.NumColors != 2 || indexed[0] == indexed[1] {
+		t.Errorf("BuildExactPalette() merged colors differing only in alpha")
+	}
+	if !palette.HasAlpha() {
+		t.Errorf("BuildExactPalette() HasAlpha() = false, want true")
+	}
+}
+
+func TestEncodeAutoPaletteLossless(t *testing.T) {
+	tests := []struct {
+		name      string
+		colorType ColorType
+		alpha     bool
+	}{
+		{"rgb_16_colors", ColorRGB, false},
+		{"rgba_16_colors", ColorRGBA, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, height := 8, 8
+			bpp := BytesPerPixel(tt.colorType)
+			pixels := make([]byte, 0, width*height*bpp)
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					c := (x/2)*4 + y/2 // 16 distinct colors
+					pixels = append(pixels, byte(c*16), byte(255-c*16), byte(c*7))
+					if tt.alpha {
+						pixels = append(pixels, byte(c*17))
+					}
+				}
+			}
+
+			opts := FastOptions(width, height)
+			opts.ColorType = tt.colorType
+			opts.AutoPalette = true
+
+			data, err := EncodeWithOptions(pixels, opts)
+			if err != nil {
+				t.Fatalf("EncodeWithOptions() error = %v", err)
+			}
+
+			chunks := parsePNGChunks(t, data)
+			ihdr := findFirstChunk(t, chunks, "IHDR")
+			if ColorType(ihdr.Data[9]) != ColorIndexed {
+				t.Errorf("IHDR color type = %d, want %d", ihdr.Data[9], ColorIndexed)
+			}
+			plte := findFirstChunk(t, chunks, "PLTE")
+			if len(plte.Data) != 16*3 {
+				t.Errorf("PLTE length = %d, want %d", len(plte.Data), 16*3)
+			}
+
+			assertDecodedPixels(t, data, width, height, tt.colorType, pixels)
+		})
+	}
+}
+
+func TestEncodeAutoPaletteFallsBack(t *testing.T) {
+	width, height := 32, 32
+	pixels := make([]byte, 0, width*height*3)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			pixels = append(pixels, byte(x*8), byte(y*8), byte(x^y))
+		}
+	}
+
+	opts := FastOptions(width, height)
+	opts.ColorType = ColorRGB
+	opts.AutoPalette = true
+
+	data, err := EncodeWithOptions(pixels, opts)
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+
+	assertIHDR(t, data, width, height, ColorRGB)
+	assertDecodedPixels(t, data, width, height, ColorRGB, pixels)
+}
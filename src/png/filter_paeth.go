@@ -1,5 +1,8 @@
 package png
 
+// ApplyFilterPaeth applies the Paeth filter. bpp is the byte stride between a byte and
+// the same byte of the pixel to its left; for 16-bit samples use BytesPerPixelDepth
+// (e.g. 6 for RGB16) so each high and low byte is predicted from its own counterpart.
 func ApplyFilterPaeth(row []byte, prev []byte, bpp int) []byte {
 	result := make([]byte, len(row))
 	for i := 0; i < len(row); i++ {
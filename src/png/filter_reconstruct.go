@@ -47,6 +47,8 @@ func ReconstructAverage(filtered []byte, prev []byte, bpp int) []byte {
 	return result
 }
 
+// ReconstructPaeth reverses ApplyFilterPaeth. bpp must be the same stride used when
+// filtering (see BytesPerPixelDepth for 16-bit samples).
 func ReconstructPaeth(filtered []byte, prev []byte, bpp int) []byte {
 	result := make([]byte, len(filtered))
 	for i := 0; i < len(filtered); i++ {
@@ -96,3 +96,58 @@ func TestFilterReconstructRoundTrip(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterReconstructRoundTrip16Bit(t *testing.T) {
+	// Two RGB16 pixels per row (bpp=6); values chosen so low bytes wrap around.
+	row := []byte{0x12, 0xFF, 0x80, 0x01, 0xFE, 0x10, 0x13, 0x00, 0x7F, 0xFF, 0xFF, 0x0F}
+	prev := []byte{0x11, 0xF0, 0x81, 0x80, 0x00, 0xFF, 0x14, 0x02, 0x7E, 0x00, 0xFE, 0xEE}
+	bpp := BytesPerPixelDepth(ColorRGB, 16)
+	if bpp != 6 {
+		t.Fatalf("BytesPerPixelDepth(ColorRGB, 16) = %d, want 6", bpp)
+	}
+
+	tests := []struct {
+		name     string
+		filterFn func([]byte, []byte, int) []byte
+		reconFn  func([]byte, []byte, int) []byte
+	}{
+		{
+			name:     "Sub",
+			filterFn: func(r []byte, p []byte, b int) []byte { return ApplyFilterSub(r, b) },
+			reconFn:  func(f []byte, p []byte, b int) []byte { return ReconstructSub(f, b) },
+		},
+		{
+			name:     "Average",
+			filterFn: func(r []byte, p []byte, b int) []byte { return ApplyFilterAverage(r, p, b) },
+			reconFn:  func(f []byte, p []byte, b int) []byte { return ReconstructAverage(f, p, b) },
+		},
+		{
+			name:     "Paeth",
+			filterFn: func(r []byte, p []byte, b int) []byte { return ApplyFilterPaeth(r, p, b) },
+			reconFn:  func(f []byte, p []byte, b int) []byte { return ReconstructPaeth(f, p, b) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := tt.filterFn(row, prev, bpp)
+			reconstructed := tt.reconFn(filtered, prev, bpp)
+
+			for i := range row {
+				if reconstructed[i] != row[i] {
+					t.Errorf("position %d: reconstructed %d != original %d",
+						i, reconstructed[i], row[i])
+				}
+			}
+		})
+	}
+
+	t.Run("Paeth first pixel uses only above", func(t *testing.T) {
+		filtered := ApplyFilterPaeth(row, prev, bpp)
+		for i := 0; i < bpp; i++ {
+			if want := row[i] - prev[i]; filtered[i] != want {
+				t.Errorf("filtered[%d] = %d, want %d", i, filtered[i], want)
+			}
+		}
+	})
+}
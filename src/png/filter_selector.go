@@ -47,22 +47,32 @@ func selectPaeth(row []byte, prevRow []byte, bpp int) (FilterType, []byte) {
 	return FilterPaeth, ApplyFilterPaeth(row, prevRow, bpp)
 }
 
-func selectMinSum(row []byte, prevRow []byte, bpp int) (FilterType, []byte) {
+// SelectFilterScored selects a filter like SelectFilterWithStrategy and also returns
+// the heuristic score of the chosen row (the sum of absolute filtered values).
+func SelectFilterScored(row []byte, prevRow []byte, bpp int, strategy FilterStrategy) (FilterType, []byte, int) {
+	switch strategy {
+	case FilterStrategyMinSum, FilterStrategyAdaptive:
+		return selectMinSumScored(row, prevRow, bpp)
+	case FilterStrategyAdaptiveFast:
+		return selectAdaptiveFastScored(row, prevRow, bpp)
+	default:
+		filterType, filtered := SelectFilterWithStrategy(row, prevRow, bpp, strategy)
+		return filterType, filtered, SumAbsoluteValues(filtered)
+	}
+}
+
+// filterCandidate is one filter tried by a score-based strategy.
+type filterCandidate struct {
+	typ FilterType
+	fn  func() []byte
+}
+
+// selectLowestScore applies each candidate and keeps the one with the lowest score.
+func selectLowestScore(filters []filterCandidate) (FilterType, []byte, int) {
 	var bestFilter FilterType
 	var bestFiltered []byte
 	bestScore := -1
 
-	filters := []struct {
-		typ FilterType
-		fn  func() []byte
-	}{
-		{FilterNone, func() []byte { return ApplyFilterNone(row) }},
-		{FilterSub, func() []byte { return ApplyFilterSub(row, bpp) }},
-		{FilterUp, func() []byte { return ApplyFilterUp(row, prevRow) }},
-		{FilterAverage, func() []byte { return ApplyFilterAverage(row, prevRow, bpp) }},
-		{FilterPaeth, func() []byte { return ApplyFilterPaeth(row, prevRow, bpp) }},
-	}
-
 	for _, f := range filters {
 		filtered := f.fn()
 		score := SumAbsoluteValues(filtered)
@@ -73,7 +83,22 @@ func selectMinSum(row []byte, prevRow []byte, bpp int) (FilterType, []byte) {
 		}
 	}
 
-	return bestFilter, bestFiltered
+	return bestFilter, bestFiltered, bestScore
+}
+
+func selectMinSum(row []byte, prevRow []byte, bpp int) (FilterType, []byte) {
+	filterType, filtered, _ := selectMinSumScored(row, prevRow, bpp)
+	return filterType, filtered
+}
+
+func selectMinSumScored(row []byte, prevRow []byte, bpp int) (FilterType, []byte, int) {
+	return selectLowestScore([]filterCandidate{
+		{FilterNone, func() []byte { return ApplyFilterNone(row) }},
+		{FilterSub, func() []byte { return ApplyFilterSub(row, bpp) }},
+		{FilterUp, func() []byte { return ApplyFilterUp(row, prevRow) }},
+		{FilterAverage, func() []byte { return ApplyFilterAverage(row, prevRow, bpp) }},
+		{FilterPaeth, func() []byte { return ApplyFilterPaeth(row, prevRow, bpp) }},
+	})
 }
 
 func selectAdaptive(row []byte, prevRow []byte, bpp int) (FilterType, []byte) {
@@ -81,31 +106,17 @@ func selectAdaptive(row []byte, prevRow []byte, bpp int) (FilterType, []byte) {
 }
 
 func selectAdaptiveFast(row []byte, prevRow []byte, bpp int) (FilterType, []byte) {
-	// Try a subset of filters for speed: None, Sub, Up
-	var bestFilter FilterType
-	var bestFiltered []byte
-	bestScore := -1
+	filterType, filtered, _ := selectAdaptiveFastScored(row, prevRow, bpp)
+	return filterType, filtered
+}
 
-	filters := []struct {
-		typ FilterType
-		fn  func() []byte
-	}{
+func selectAdaptiveFastScored(row []byte, prevRow []byte, bpp int) (FilterType, []byte, int) {
+	// Try a subset of filters for speed: None, Sub, Up
+	return selectLowestScore([]filterCandidate{
 		{FilterNone, func() []byte { return ApplyFilterNone(row) }},
 		{FilterSub, func() []byte { return ApplyFilterSub(row, bpp) }},
 		{FilterUp, func() []byte { return ApplyFilterUp(row, prevRow) }},
-	}
-
-	for _, f := range filters {
-		filtered := f.fn()
-		score := SumAbsoluteValues(filtered)
-		if bestScore < 0 || score < bestScore {
-			bestScore = score
-			bestFilter = f.typ
-			bestFiltered = filtered
-		}
-	}
-
-	return bestFilter, bestFiltered
+	})
 }
 
 func SelectAll(pixels []byte, width, height, bpp int) []FilterType {
@@ -139,3 +150,18 @@ func SelectAllWithStrategy(pixels []byte, width, height, bpp int, strategy Filte
 
 	return filters
 }
+
+// AnalyzeFilters reports how many rows the given strategy assigns to each filter type,
+// using the same selection as encoding but without compressing anything.
+func AnalyzeFilters(pixels []byte, width, height int, colorType ColorType, strategy FilterStrategy) map[FilterType]int {
+	bpp := BytesPerPixel(colorType)
+	counts := make(map[FilterType]int)
+	if width <= 0 || height <= 0 || len(pixels) < width*height*bpp {
+		return counts
+	}
+
+	for _, filterType := range SelectAllWithStrategy(pixels, width, height, bpp, scanlineStrategy(colorType, strategy)) {
+		counts[filterType]++
+	}
+	return counts
+}
@@ -1,6 +1,9 @@
 package png
 
-import "testing"
+import (
+	"bytes"
+	"testing"
+)
 
 func TestSelectFilter(t *testing.T) {
 	tests := []struct {
@@ -98,3 +101,81 @@ func TestSelectAll(t *testing.T) {
 		}
 	}
 }
+
+func TestAnalyzeFilters(t *testing.T) {
+	width, height := 16, 12
+	pixels := make([]byte, width*height*3)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			offset := (y*width + x) * 3
+			// Vertical stripes: every row is identical, so Up filters to zeros.
+			pixels[offset] = byte(x * 37)
+			pixels[offset+1] = byte(x * 91)
+			pixels[offset+2] = byte(x * 13)
+		}
+	}
+
+	counts := AnalyzeFilters(pixels, width, height, ColorRGB, FilterStrategyMinSum)
+
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	if total != height {
+		t.Errorf("AnalyzeFilters() total rows = %d, want %d", total, height)
+	}
+	if counts[FilterUp] < height-1 {
+		t.Errorf("AnalyzeFilters() FilterUp rows = %d, want at least %d (counts %v)", counts[FilterUp], height-1, counts)
+	}
+
+	fixed := AnalyzeFilters(pixels, width, height, ColorRGB, FilterStrategySub)
+	if fixed[FilterSub] != height || len(fixed) != 1 {
+		t.Errorf("AnalyzeFilters() with Sub strategy = %v, want all %d rows Sub", fixed, height)
+	}
+
+	if got := AnalyzeFilters(pixels[:10], width, height, ColorRGB, FilterStrategyMinSum); len(got) != 0 {
+		t.Errorf("AnalyzeFilters() on short input = %v, want empty", got)
+	}
+}
+
+func TestSelectFilterScored(t *testing.T) {
+	row := []byte{10, 20, 30, 40, 50, 60, 200, 10, 90}
+	prev := []byte{12, 18, 33, 41, 47, 62, 190, 15, 88}
+
+	strategies := []FilterStrategy{
+		FilterStrategyNone,
+		FilterStrategySub,
+		FilterStrategyPaeth,
+		FilterStrategyMinSum,
+		FilterStrategyAdaptive,
+		FilterStrategyAdaptiveFast,
+	}
+
+	for _, strategy := range strategies {
+		t.Run(strategy.String(), func(t *testing.T) {
+			filterType, filtered, score := SelectFilterScored(row, prev, 3, strategy)
+			wantType, wantFiltered := SelectFilterWithStrategy(row, prev, 3, strategy)
+
+			if filterType != wantType || !bytes.Equal(filtered, wantFiltered) {
+				t.Errorf("SelectFilterScored() = %v %v, want %v %v", filterType, filtered, wantType, wantFiltered)
+			}
+			if want := SumAbsoluteValues(filtered); score != want {
+				t.Errorf("SelectFilterScored() score = %d, want %d", score, want)
+			}
+		})
+	}
+
+	// MinSum must report the lowest score among all five filters.
+	_, _, best := SelectFilterScored(row, prev, 3, FilterStrategyMinSum)
+	for _, filtered := range [][]byte{
+		ApplyFilterNone(row),
+		ApplyFilterSub(row, 3),
+		ApplyFilterUp(row, prev),
+		ApplyFilterAverage(row, prev, 3),
+		ApplyFilterPaeth(row, prev, 3),
+	} {
+		if score := SumAbsoluteValues(filtered); score < best {
+			t.Errorf("MinSum score = %d, but a filter scores %d", best, score)
+		}
+	}
+}
@@ -2,7 +2,12 @@ package png
 
 func ApplyFilterSub(row []byte, bpp int) []byte {
 	result := make([]byte, len(row))
-	for i := 0; i < len(row); i++ {
+	i := 0
+	if wideFilters && bpp > 0 && len(row) > bpp {
+		copy(result, row[:bpp])
+		i = bpp + subRowsWide(result[bpp:], row[bpp:], row)
+	}
+	for ; i < len(row); i++ {
 		var left byte
 		if i >= bpp {
 			left = row[i-bpp]
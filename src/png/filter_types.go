@@ -1,5 +1,7 @@
 package png
 
+import "fmt"
+
 type FilterType uint8
 
 const (
@@ -9,3 +11,21 @@ const (
 	FilterAverage FilterType = 3
 	FilterPaeth   FilterType = 4
 )
+
+// String returns the filter name, e.g. "Paeth", or "Unknown(N)".
+func (f FilterType) String() string {
+	switch f {
+	case FilterNone:
+		return "None"
+	case FilterSub:
+		return "Sub"
+	case FilterUp:
+		return "Up"
+	case FilterAverage:
+		return "Average"
+	case FilterPaeth:
+		return "Paeth"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(f))
+	}
+}
@@ -0,0 +1,25 @@
+package png
+
+import "testing"
+
+func TestFilterTypeString(t *testing.T) {
+	tests := []struct {
+		filterType FilterType
+		want       string
+	}{
+		{FilterNone, "None"},
+		{FilterSub, "Sub"},
+		{FilterUp, "Up"},
+		{FilterAverage, "Average"},
+		{FilterPaeth, "Paeth"},
+		{FilterType(7), "Unknown(7)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.filterType.String(); got != tt.want {
+				t.Errorf("FilterType(%d).String() = %q, want %q", uint8(tt.filterType), got, tt.want)
+			}
+		})
+	}
+}
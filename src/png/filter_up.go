@@ -2,7 +2,15 @@ package png
 
 func ApplyFilterUp(row []byte, prev []byte) []byte {
 	result := make([]byte, len(row))
-	for i := 0; i < len(row); i++ {
+	i := 0
+	if wideFilters {
+		n := len(row)
+		if len(prev) < n {
+			n = len(prev)
+		}
+		i = subRowsWide(result[:n], row, prev)
+	}
+	for ; i < len(row); i++ {
 		var up byte
 		if len(prev) > 0 && i < len(prev) {
 			up = prev[i]
@@ -0,0 +1,36 @@
+package png
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// wideFilters enables the Sub and Up fast paths that filter eight bytes per
+// uint64 operation. They are plain Go, but only beat the byte loop where
+// 64-bit arithmetic is native.
+var wideFilters = bits.UintSize == 64
+
+const (
+	laneHigh = 0x8080808080808080
+	laneLow  = 0x7F7F7F7F7F7F7F7F
+)
+
+// subBytes8 subtracts each byte of y from the matching byte of x modulo 256.
+// Masking off the high bits keeps borrows from crossing into the next byte;
+// the final XOR restores each byte's high bit.
+func subBytes8(x, y uint64) uint64 {
+	return ((x | laneHigh) - (y & laneLow)) ^ ((x ^ ^y) & laneHigh)
+}
+
+// subRowsWide sets dst[i] = a[i] - b[i] eight bytes at a time and returns how
+// many leading bytes it filled; the caller finishes the remaining len(dst)%8.
+// a and b must be at least as long as dst.
+func subRowsWide(dst, a, b []byte) int {
+	n := len(dst) &^ 7
+	for i := 0; i < n; i += 8 {
+		x := binary.LittleEndian.Uint64(a[i:])
+		y := binary.LittleEndian.Uint64(b[i:])
+		binary.LittleEndian.PutUint64(dst[i:], subBytes8(x, y))
+	}
+	return n
+}
@@ -0,0 +1,63 @@
+package png
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// withScalarFilters runs fn with the wide filter paths disabled.
+func withScalarFilters(fn func()) {
+	saved := wideFilters
+	wideFilters = false
+	defer func() { wideFilters = saved }()
+	fn()
+}
+
+func TestSubBytes8(t *testing.T) {
+	tests := []struct {
+		x, y, want uint64
+	}{
+		{0, 0, 0},
+		{0x0102030405060708, 0x0101010101010101, 0x0001020304050607},
+		{0x0000000000000000, 0x0101010101010101, 0xFFFFFFFFFFFFFFFF},
+		{0x80FF7F0080FF7F00, 0xFF80007FFF80007F, 0x817F7F81817F7F81},
+	}
+
+	for _, tt := range tests {
+		if got := subBytes8(tt.x, tt.y); got != tt.want {
+			t.Errorf("subBytes8(%#x, %#x) = %#x, want %#x", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestWideFiltersMatchScalar(t *testing.T) {
+	if !wideFilters {
+		t.Skip("wide filters are disabled on this platform")
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for length := 0; length <= 40; length++ {
+		row := make([]byte, length)
+		prev := make([]byte, length)
+		rng.Read(row)
+		rng.Read(prev)
+
+		for bpp := 1; bpp <= 8; bpp++ {
+			var want []byte
+			withScalarFilters(func() { want = ApplyFilterSub(row, bpp) })
+			if got := ApplyFilterSub(row, bpp); !bytes.Equal(got, want) {
+				t.Errorf("ApplyFilterSub(len %d, bpp %d) = %v, want %v", length, bpp, got, want)
+			}
+		}
+
+		// Also cover a previous row shorter than the current one.
+		for _, p := range [][]byte{prev, prev[:length/2], nil} {
+			var want []byte
+			withScalarFilters(func() { want = ApplyFilterUp(row, p) })
+			if got := ApplyFilterUp(row, p); !bytes.Equal(got, want) {
+				t.Errorf("ApplyFilterUp(len %d, prev len %d) = %v, want %v", length, len(p), got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package png
+
+import (
+	"bytes"
+	stdpng "image/png"
+	"testing"
+)
+
+// fuzzColorTypes are the input color types the encoder accepts.
+var fuzzColorTypes = []ColorType{ColorGrayscale, ColorRGB, ColorRGBA}
+
+// fuzzImage turns fuzzer input into a valid-sized image: dimensions 1-64, one of
+// the input color types, and seed repeated to fill the pixel buffer.
+func fuzzImage(width, height, colorType uint8, seed []byte) (int, int, ColorType, []byte) {
+	w := int(width)%64 + 1
+	h := int(height)%64 + 1
+	ct := fuzzColorTypes[int(colorType)%len(fuzzColorTypes)]
+
+	pixels := make([]byte, w*h*BytesPerPixel(ct))
+	if len(seed) > 0 {
+		for i := range pixels {
+			pixels[i] = seed[i%len(seed)]
+		}
+	}
+	return w, h, ct, pixels
+}
+
+// fuzzSeeds adds the images other tests use, each sized so fuzzImage
+// reproduces it exactly: width and height are one less than the image's, and
+// the color type indexes fuzzColorTypes.
+func fuzzSeeds(f *testing.F) {
+	f.Add(uint8(7), uint8(7), uint8(2), uint8(0), createTestImage(8, 8))
+	f.Add(uint8(15), uint8(15), uint8(1), uint8(1), benchPixels(16, 16, 3))
+	f.Add(uint8(11), uint8(4), uint8(0), uint8(2), benchPixels(12, 5, 1))
+	f.Add(uint8(15), uint8(3), uint8(1), uint8(3), grayGradient(16, 4))
+	f.Add(uint8(63), uint8(63), uint8(1), uint8(4), paletteBuilderTestImage())
+	f.Add(uint8(9), uint8(40), uint8(2), uint8(1), benchPixels(10, 41, 4))
+}
+
+// fuzzOptions picks a preset (or lossy quantization) for the fuzzer's choice.
+func fuzzOptions(choice uint8, width, height int, colorType ColorType) Options {
+	var opts Options
+	switch choice % 5 {
+	case 0:
+		opts = FastOptions(width, height)
+	case 1:
+		opts = BalancedOptions(width, height)
+	case 2:
+		opts = MaxOptions(width, height)
+	case 3:
+		opts = LossyOptions(width, height, 16)
+	default:
+		opts = LossyOptions(width, height, 64)
+		opts.Dithering = true
+	}
+	opts.ColorType = colorType
+	return opts
+}
+
+func FuzzEncode(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, width, height, colorType, preset uint8, seed []byte) {
+		w, h, ct, pixels := fuzzImage(width, height, colorType, seed)
+		opts := fuzzOptions(preset, w, h, ct)
+		if err := opts.Validate(); err != nil {
+			return
+		}
+
+		data, err := EncodeWithOptions(pixels, opts)
+		if err != nil {
+			t.Fatalf("EncodeWithOptions() with valid options error = %v", err)
+		}
+		img, err := stdpng.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("image/png.Decode() error = %v", err)
+		}
+		if b := img.Bounds(); b.Dx() != w || b.Dy() != h {
+			t.Fatalf("decoded bounds = %dx%d, want %dx%d", b.Dx(), b.Dy(), w, h)
+		}
+	})
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, width, height, colorType, filter uint8, seed []byte) {
+		w, h, ct, pixels := fuzzImage(width, height, colorType, seed)
+		// FastOptions is lossless and keeps the color type, so Decode must
+		// return exactly the input.
+		opts := FastOptions(w, h)
+		opts.ColorType = ct
+		opts.FilterStrategy = FilterStrategy(int(filter) % (int(FilterStrategyAdaptiveFast) + 1))
+
+		data, err := EncodeWithOptions(pixels, opts)
+		if err != nil {
+			t.Fatalf("EncodeWithOptions() error = %v", err)
+		}
+		img, err := Decode(data)
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		if img.Width != w || img.Height != h || img.ColorType != ct {
+			t.Fatalf("Decode() = %dx%d %s, want %dx%d %s", img.Width, img.Height, img.ColorType, w, h, ct)
+		}
+		if !bytes.Equal(img.Pixels, pixels) {
+			t.Fatal("decoded pixels do not match input")
+		}
+	})
+}
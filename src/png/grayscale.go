@@ -0,0 +1,84 @@
+package png
+
+import "math"
+
+// GrayscaleMethod selects how color pixels are converted to gray.
+type GrayscaleMethod int
+
+const (
+	// GrayscaleRec601 weights the gamma-encoded channels with Rec.601 coefficients.
+	// It is fast but renders saturated colors too dark.
+	GrayscaleRec601 GrayscaleMethod = iota
+	// GrayscaleLinear linearizes sRGB, computes Rec.709 luminance in linear light,
+	// and re-encodes the result to sRGB for perceptually correct gray.
+	GrayscaleLinear
+)
+
+// srgbToLinear maps each 8-bit sRGB value to linear light in [0, 1].
+var srgbToLinear = func() [256]float64 {
+	var table [256]float64
+	for i := range table {
+		v := float64(i) / 255
+		if v <= 0.04045 {
+			table[i] = v / 12.92
+		} else {
+			table[i] = math.Pow((v+0.055)/1.055, 2.4)
+		}
+	}
+	return table
+}()
+
+// linearToSRGB encodes a linear-light value in [0, 1] as an 8-bit sRGB value.
+func linearToSRGB(v float64) uint8 {
+	if v <= 0.0031308 {
+		v *= 12.92
+	} else {
+		v = 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+	return uint8(clampInt(int(math.Round(v * 255))))
+}
+
+// ConvertToGrayscale converts RGB or RGBA pixels to one gray byte per pixel using method.
+// Alpha is discarded. Grayscale input is returned as a copy; other color types return nil.
+func ConvertToGrayscale(pixels []byte, colorType ColorType, method GrayscaleMethod) []byte {
+	switch colorType {
+	case ColorGrayscale:
+		return append([]byte(nil), pixels...)
+	case ColorRGB, ColorRGBA:
+	default:
+		return nil
+	}
+
+	bpp := BytesPerPixel(colorType)
+	count := len(pixels) / bpp
+	result := make([]byte, count)
+
+	for i := 0; i < count; i++ {
+		offset := i * bpp
+		r, g, b := pixels[offset], pixels[offset+1], pixels[offset+2]
+
+		if method == GrayscaleLinear {
+			y := 0.2126*srgbToLinear[r] + 0.7152*srgbToLinear[g] + 0.0722*srgbToLinear[b]
+			result[i] = linearToSRGB(y)
+		} else {
+			y := 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+			result[i] = uint8(clampInt(int(math.Round(y))))
+		}
+	}
+
+	return result
+}
+
+// ConvertToGrayscaleLinear converts RGB or RGBA pixels to gray using linear-light luminance.
+func ConvertToGrayscaleLinear(pixels []byte, colorType ColorType) []byte {
+	return ConvertToGrayscale(pixels, colorType, GrayscaleLinear)
+}
+
+// expandGrayToRGB repeats each gray sample across R, G, and B.
+func expandGrayToRGB(pixels []byte) []byte {
+	result := make([]byte, 0, len(pixels)*3)
+	for _, v := range pixels {
+		result = append(result, v, v, v)
+	}
+	return result
+}
@@ -0,0 +1,41 @@
+package png
+
+import "testing"
+
+func TestConvertToGrayscale(t *testing.T) {
+	tests := []struct {
+		name      string
+		pixels    []byte
+		colorType ColorType
+		method    GrayscaleMethod
+		want      []byte
+	}{
+		{"rec601 black and white", []byte{0, 0, 0, 255, 255, 255}, ColorRGB, GrayscaleRec601, []byte{0, 255}},
+		{"linear black and white", []byte{0, 0, 0, 255, 255, 255}, ColorRGB, GrayscaleLinear, []byte{0, 255}},
+		{"linear neutral gray kept", []byte{128, 128, 128}, ColorRGB, GrayscaleLinear, []byte{128}},
+		{"rec601 green", []byte{0, 255, 0}, ColorRGB, GrayscaleRec601, []byte{150}},
+		{"linear green", []byte{0, 255, 0}, ColorRGB, GrayscaleLinear, []byte{220}},
+		{"rgba drops alpha", []byte{0, 255, 0, 10}, ColorRGBA, GrayscaleRec601, []byte{150}},
+		{"grayscale copied", []byte{7, 9}, ColorGrayscale, GrayscaleLinear, []byte{7, 9}},
+		{"indexed unsupported", []byte{1, 2}, ColorIndexed, GrayscaleRec601, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ConvertToGrayscale(tt.pixels, tt.colorType, tt.method)
+			if string(got) != string(tt.want) || (got == nil) != (tt.want == nil) {
+				t.Errorf("ConvertToGrayscale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertToGrayscaleLinearBrighterOnSaturatedGreen(t *testing.T) {
+	green := []byte{0, 255, 0}
+	simple := ConvertToGrayscale(green, ColorRGB, GrayscaleRec601)[0]
+	linear := ConvertToGrayscaleLinear(green, ColorRGB)[0]
+
+	if int(linear)-int(simple) < 40 {
+		t.Errorf("linear gray = %d, Rec.601 gray = %d, want linear at least 40 brighter", linear, simple)
+	}
+}
@@ -1,7 +1,9 @@
 package png
 
 import (
+	"bytes"
 	"fmt"
+	"math"
 
 	"github.com/mac/go-pixo/src/compress"
 )
@@ -11,59 +13,102 @@ import (
 //   - zlib header (CMF + FLG bytes)
 //   - DEFLATE-compressed data (fixed or dynamic Huffman blocks)
 //   - zlib footer (Adler32 checksum)
-//   - wrapped in an IDAT chunk (length + "IDAT" + data + CRC)
+//   - wrapped in a single IDAT chunk (length + "IDAT" + data + CRC)
+//
+// WriteIDATWithOptions instead splits the stream across several IDAT chunks
+// when opts.MaxIDATChunkSize is set, adding 12 bytes of framing per chunk.
 func WriteIDAT(w interface{ Write([]byte) (int, error) }, pixels []byte, width, height int, colorType ColorType) error {
 	opts := BalancedOptions(width, height)
 	opts.ColorType = colorType
 	return WriteIDATWithOptions(w, pixels, width, height, colorType, opts)
 }
 
-// WriteIDATWithOptions writes IDAT chunk with configurable options.
+// WriteIDATWithOptions writes the image data with configurable options, as
+// one IDAT chunk or, when opts.MaxIDATChunkSize is set, as consecutive IDAT
+// chunks of at most that many data bytes. Rows are read opts.RowStride bytes
+// apart when it is set.
 func WriteIDATWithOptions(w interface{ Write([]byte) (int, error) }, pixels []byte, width, height int, colorType ColorType, opts Options) error {
-	if width <= 0 || height <= 0 {
-		return ErrInvalidDimensions
-	}
-
-	bpp := BytesPerPixel(colorType)
-	expectedRawLen := width * bpp * height
-
-	if len(pixels) != expectedRawLen {
-		return fmt.Errorf("png: pixel data length %d does not match expected %d for %dx%d image",
-			len(pixels), expectedRawLen, width, height)
+	zlibData, err := idatData(pixels, width, height, colorType, opts)
+	if err != nil {
+		return err
 	}
+	return writeIDATChunks(w, zlibData, opts.MaxIDATChunkSize)
+}
 
-	// Build scanlines with filter selection based on strategy
-	scanlineData := make([]byte, 0, (1+width*bpp)*height)
-	var prevRow []byte
-	for y := 0; y < height; y++ {
-		offset := y * width * bpp
-		row := pixels[offset : offset+width*bpp]
-		filterType, filteredRow := SelectFilterWithStrategy(row, prevRow, bpp, opts.FilterStrategy)
-		scanlineData = append(scanlineData, byte(filterType))
-		scanlineData = append(scanlineData, filteredRow...)
-		prevRow = row
+// idatData filters and compresses pixels into the zlib stream that
+// WriteIDATWithOptions splits into IDAT chunks.
+func idatData(pixels []byte, width, height int, colorType ColorType, opts Options) ([]byte, error) {
+	scanlineData, _, err := buildScanlines(pixels, width, height, opts.RowStride, colorType, opts.FilterStrategy, func(y int) {
+		opts.reportRowProgress(y, height)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Build zlib-compressed data
+	opts.reportProgress(ProgressCompression, progressCompressStart)
 	zlibData, err := buildZlibData(scanlineData, width, height, colorType, opts)
 	if err != nil {
-		return fmt.Errorf("png: failed to build zlib data: %w", err)
+		return nil, fmt.Errorf("png: failed to build zlib data: %w", err)
 	}
+	return zlibData, nil
+}
 
-	// Write as IDAT chunk
-	chunk := Chunk{
-		chunkType: ChunkIDAT,
-		Data:      zlibData,
+// writeIDATChunks writes zlibData as consecutive IDAT chunks of at most
+// maxSize bytes each, or as a single chunk when maxSize is not positive.
+func writeIDATChunks(w interface{ Write([]byte) (int, error) }, zlibData []byte, maxSize int) error {
+	if maxSize <= 0 {
+		maxSize = len(zlibData)
+	}
+	for {
+		n := min(maxSize, len(zlibData))
+		chunk := Chunk{
+			chunkType: ChunkIDAT,
+			Data:      zlibData[:n],
+		}
+		if _, err := chunk.WriteTo(w); err != nil {
+			return err
+		}
+		zlibData = zlibData[n:]
+		if len(zlibData) == 0 {
+			return nil
+		}
 	}
-	_, err = chunk.WriteTo(w)
-	return err
+}
+
+// idatChunksSize returns the bytes writeIDATChunks writes for n bytes of
+// zlib data: the data plus 12 bytes of framing per chunk.
+func idatChunksSize(n, maxSize int) int {
+	chunks := 1
+	if maxSize > 0 && n > maxSize {
+		chunks = (n + maxSize - 1) / maxSize
+	}
+	return n + 12*chunks
+}
+
+// scanlineStrategy returns the filter strategy actually used for colorType.
+// For 1-byte grayscale pixels Average and Paeth rarely beat Sub or Up, so the
+// default adaptive search is narrowed to None/Sub/Up; other color types and
+// explicitly chosen strategies are left unchanged.
+func scanlineStrategy(colorType ColorType, strategy FilterStrategy) FilterStrategy {
+	if colorType == ColorGrayscale && strategy == FilterStrategyAdaptive {
+		return FilterStrategyAdaptiveFast
+	}
+	return strategy
 }
 
 // buildZlibData builds the zlib-wrapped DEFLATE data containing scanlines.
 // The pixels parameter contains all scanline data with filter bytes prepended.
 func buildZlibData(pixels []byte, width, height int, colorType ColorType, opts Options) ([]byte, error) {
-	// Write zlib header: CMF (DEFLATE, 32K window) + FLG (default compression, check bits)
-	cmf, err := compress.ZlibHeaderBytes(32768, 2)
+	// Write zlib header: CMF (DEFLATE, 32K window) + FLG (level matching opts.CompressionLevel, check bits),
+	// plus DICTID when compressing against a preset dictionary
+	var cmf []byte
+	var err error
+	if len(opts.ZlibDictionary) > 0 {
+		cmf, err = compress.ZlibDictHeaderBytes(32768, compress.ZlibLevel(opts.CompressionLevel), opts.ZlibDictionary)
+	} else {
+		cmf, err = compress.ZlibHeaderBytes(32768, compress.ZlibLevel(opts.CompressionLevel))
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -71,6 +116,7 @@ func buildZlibData(pixels []byte, width, height int, colorType ColorType, opts O
 	// Compress scanline data using DEFLATE with compression level from options
 	encoder := compress.NewDeflateEncoder()
 	encoder.SetCompressionLevel(opts.CompressionLevel)
+	encoder.SetDictionary(opts.ZlibDictionary)
 
 	var deflateData []byte
 	if opts.OptimalDeflate {
@@ -82,6 +128,12 @@ func buildZlibData(pixels []byte, width, height int, colorType ColorType, opts O
 		return nil, fmt.Errorf("failed to compress scanline data: %w", err)
 	}
 
+	// Never let compression expand the data: fall back to a stored block if smaller
+	deflateData, err = smallerOfStored(deflateData, pixels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build stored block: %w", err)
+	}
+
 	// Write Adler32 checksum of the uncompressed scanline data
 	adler := compress.Adler32(pixels)
 	adlerBuf := compress.ZlibFooterBytes(adler)
@@ -95,6 +147,20 @@ func buildZlibData(pixels []byte, width, height int, colorType ColorType, opts O
 	return result, nil
 }
 
+// smallerOfStored returns stored (uncompressed) DEFLATE blocks for data when
+// they are smaller than the compressed form, otherwise it returns compressed unchanged.
+func smallerOfStored(compressed, data []byte) ([]byte, error) {
+	if len(compressed) <= compress.StoredBlocksSize(len(data)) {
+		return compressed, nil
+	}
+
+	var buf bytes.Buffer
+	if err := compress.WriteStoredBlocks(&buf, data, true); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 // IDATDataBytes returns the raw zlib data for IDAT without the chunk wrapper.
 // This is useful for testing or when you need to write multiple IDAT chunks.
 func IDATDataBytes(pixels []byte, width, height int, colorType ColorType) ([]byte, error) {
@@ -104,27 +170,14 @@ func IDATDataBytes(pixels []byte, width, height int, colorType ColorType) ([]byt
 }
 
 // IDATDataBytesWithOptions returns the raw zlib data with configurable options.
+// Rows are filtered with opts.FilterStrategy exactly as WriteIDATWithOptions
+// does, including its opts.RowStride; the zero value, FilterStrategyNone,
+// leaves every row unfiltered.
 func IDATDataBytesWithOptions(pixels []byte, width, height int, colorType ColorType, opts Options) ([]byte, error) {
-	bpp := BytesPerPixel(colorType)
-	expectedRawLen := width * bpp * height
-
-	if len(pixels) != expectedRawLen {
-		return nil, fmt.Errorf("png: pixel data length %d does not match expected %d for %dx%d image",
-			len(pixels), expectedRawLen, width, height)
-	}
-
-	// Build scanlines with filter selection based on strategy
-	scanlineData := make([]byte, 0, (1+width*bpp)*height)
-	var prevRow []byte
-	for y := 0; y < height; y++ {
-		offset := y * width * bpp
-		row := pixels[offset : offset+width*bpp]
-		filterType, filteredRow := SelectFilterWithStrategy(row, prevRow, bpp, opts.FilterStrategy)
-		scanlineData = append(scanlineData, byte(filterType))
-		scanlineData = append(scanlineData, filteredRow...)
-		prevRow = row
+	scanlineData, _, err := BuildScanlinesWithStride(pixels, width, height, opts.RowStride, colorType, opts.FilterStrategy)
+	if err != nil {
+		return nil, err
 	}
-
 	return buildZlibData(scanlineData, width, height, colorType, opts)
 }
 
@@ -132,13 +185,28 @@ func IDATDataBytesWithOptions(pixels []byte, width, height int, colorType ColorT
 // The actual size may vary due to DEFLATE compression, so this is only an approximation.
 func ExpectedIDATSize(width, height int, colorType ColorType) int {
 	bpp := BytesPerPixel(colorType)
-	scanlineLen := 1 + width*bpp
-	uncompressedSize := scanlineLen * height
+	scanlineLen := 1 + saturatingMul(int64(width), int64(bpp))
+	uncompressedSize := saturatingMul(scanlineLen, int64(height))
 	// Estimate: zlib header (2) + compressed data (assume 50% compression) + Adler32 (4)
 	// This is a rough estimate; actual compression ratio depends on image content
 	estimatedCompressed := uncompressedSize / 2
 	if estimatedCompressed < 10 {
 		estimatedCompressed = 10
 	}
-	return 2 + estimatedCompressed + 4
+	return clampToInt(2 + estimatedCompressed + 4)
+}
+
+// MaxIDATSize returns an upper bound on the IDAT chunk data for an image encoded
+// at its own color type. The encoder falls back to stored blocks whenever DEFLATE
+// would be larger, so the output never exceeds the stored form at any level.
+// The bound covers the data only: each IDAT chunk adds 12 bytes of framing,
+// once per chunk when Options.MaxIDATChunkSize splits the data.
+func MaxIDATSize(width, height int, colorType ColorType) int {
+	raw := saturatingMul(int64(ScanlineLength(width, colorType)), int64(height))
+	if raw > math.MaxInt32 {
+		// Far beyond any image checkImageSize allows; avoid overflow below.
+		return math.MaxInt
+	}
+	// zlib header (2) + stored blocks + Adler32 (4)
+	return 2 + compress.StoredBlocksSize(int(raw)) + 4
 }
@@ -5,6 +5,8 @@ import (
 	"compress/zlib"
 	"encoding/binary"
 	"io"
+	"math"
+	"math/rand"
 	"testing"
 
 	"github.com/mac/go-pixo/src/compress"
@@ -306,6 +308,92 @@ func TestWriteIDAT_Grayscale(t *testing.T) {
 	}
 }
 
+func TestIDATDataBytes_GrayscaleFastPathRoundTrip(t *testing.T) {
+	width, height := 64, 32
+	pixels := make([]byte, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			pixels[y*width+x] = uint8(x*3 + y*5)
+		}
+	}
+
+	data, err := IDATDataBytes(pixels, width, height, ColorGrayscale)
+	if err != nil {
+		t.Fatalf("IDATDataBytes() error = %v", err)
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("zlib.NewReader() error = %v", err)
+	}
+	defer zr.Close()
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("zlib decompression error = %v", err)
+	}
+
+	for y := 0; y < height; y++ {
+		if f := FilterType(raw[y*(1+width)]); f != FilterNone && f != FilterSub && f != FilterUp {
+			t.Errorf("row %d filter = %v, want None, Sub or Up", y, f)
+		}
+	}
+	if got := unfilterScanlines(t, raw, width, height, 1); !bytes.Equal(got, pixels) {
+		t.Error("unfiltered pixels do not match input")
+	}
+}
+
+func BenchmarkWriteIDAT_Grayscale(b *testing.B) {
+	width, height := 1024, 1024
+	rng := rand.New(rand.NewSource(1))
+	pixels := make([]byte, width*height)
+	for i := range pixels {
+		pixels[i] = uint8(i%width/4) + uint8(rng.Intn(8))
+	}
+
+	b.SetBytes(int64(len(pixels)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := WriteIDAT(io.Discard, pixels, width, height, ColorGrayscale); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// unfilterScanlines reverses per-row filtering of raw scanlines (filter byte + row data).
+func unfilterScanlines(t *testing.T, raw []byte, width, height, bpp int) []byte {
+	t.Helper()
+
+	rowLen := width * bpp
+	if len(raw) != (1+rowLen)*height {
+		t.Fatalf("scanline data length = %d, want %d", len(raw), (1+rowLen)*height)
+	}
+
+	pixels := make([]byte, 0, rowLen*height)
+	var prev []byte
+	for y := 0; y < height; y++ {
+		start := y * (1 + rowLen)
+		filtered := raw[start+1 : start+1+rowLen]
+		var row []byte
+		switch FilterType(raw[start]) {
+		case FilterNone:
+			row = ReconstructNone(filtered)
+		case FilterSub:
+			row = ReconstructSub(filtered, bpp)
+		case FilterUp:
+			row = ReconstructUp(filtered, prev)
+		case FilterAverage:
+			row = ReconstructAverage(filtered, prev, bpp)
+		case FilterPaeth:
+			row = ReconstructPaeth(filtered, prev, bpp)
+		default:
+			t.Fatalf("row %d: invalid filter type %d", y, raw[start])
+		}
+		pixels = append(pixels, row...)
+		prev = row
+	}
+	return pixels
+}
+
 func TestIDATDataBytes_matchesWriteIDAT(t *testing.T) {
 	pixels := []byte{
 		0xFF, 0x00, 0x00, 0x00, 0xFF, 0x00, // row 0: 2 RGB pixels
@@ -330,3 +418,273 @@ func TestIDATDataBytes_matchesWriteIDAT(t *testing.T) {
 		t.Errorf("IDATDataBytes() = %v, WriteIDAT() data = %v", dataBytes, writeData)
 	}
 }
+
+func TestIDATDataBytesWithOptions_ZlibLevel(t *testing.T) {
+	pixels := []byte{0xFF, 0x00, 0x00}
+
+	tests := []struct {
+		name       string
+		level      int
+		wantFLevel byte
+	}{
+		{"level_1", 1, 0},
+		{"level_6", 6, 2},
+		{"level_9", 9, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := FastOptions(1, 1)
+			opts.CompressionLevel = tt.level
+
+			data, err := IDATDataBytesWithOptions(pixels, 1, 1, ColorRGB, opts)
+			if err != nil {
+				t.Fatalf("IDATDataBytesWithOptions() error = %v", err)
+			}
+
+			if flevel := data[1] >> 6; flevel != tt.wantFLevel {
+				t.Errorf("FLEVEL = %d, want %d", flevel, tt.wantFLevel)
+			}
+			if (int(data[0])*256+int(data[1]))%31 != 0 {
+				t.Errorf("zlib header 0x%02X%02X not divisible by 31", data[0], data[1])
+			}
+
+			zr, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("zlib.NewReader() error = %v", err)
+			}
+			defer zr.Close()
+			if _, err := io.ReadAll(zr); err != nil {
+				t.Fatalf("zlib decompression error = %v", err)
+			}
+		})
+	}
+}
+
+func TestIDATDataBytesWithOptions_FilterStrategy(t *testing.T) {
+	width, height := 64, 64
+	pixels := make([]byte, width*height*3)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := (y*width + x) * 3
+			pixels[i], pixels[i+1], pixels[i+2] = byte(x*3+y), byte(y*4), byte(x*x/16+y)
+		}
+	}
+
+	encode := func(strategy FilterStrategy) []byte {
+		t.Helper()
+		opts := BalancedOptions(width, height)
+		opts.FilterStrategy = strategy
+		data, err := IDATDataBytesWithOptions(pixels, width, height, ColorRGB, opts)
+		if err != nil {
+			t.Fatalf("IDATDataBytesWithOptions(%v) error = %v", strategy, err)
+		}
+		return data
+	}
+
+	unfiltered, minSum := encode(FilterStrategyNone), encode(FilterStrategyMinSum)
+	if len(minSum) >= len(unfiltered) {
+		t.Errorf("MinSum size = %d, want smaller than unfiltered size %d", len(minSum), len(unfiltered))
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(unfiltered))
+	if err != nil {
+		t.Fatalf("zlib.NewReader() error = %v", err)
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("zlib decompression error = %v", err)
+	}
+	for y := 0; y < height; y++ {
+		if f := raw[y*(1+width*3)]; f != byte(FilterNone) {
+			t.Fatalf("FilterStrategyNone row %d filter byte = %d, want 0", y, f)
+		}
+	}
+}
+
+func TestIDATDataBytes_StoredFallbackForRandomData(t *testing.T) {
+	width, height := 16, 16
+	rng := rand.New(rand.NewSource(1))
+	pixels := make([]byte, width*height*3)
+	rng.Read(pixels)
+
+	opts := FastOptions(width, height)
+	opts.FilterStrategy = FilterStrategyNone
+	data, err := IDATDataBytesWithOptions(pixels, width, height, ColorRGB, opts)
+	if err != nil {
+		t.Fatalf("IDATDataBytesWithOptions() error = %v", err)
+	}
+
+	rawLen := (1 + width*3) * height
+	// zlib header (2) + stored block header (5) + raw scanlines + Adler32 (4)
+	if want := 2 + 5 + rawLen + 4; len(data) != want {
+		t.Errorf("IDAT data length = %d, want %d (stored)", len(data), want)
+	}
+	if blockHeader := data[2] & 0x07; blockHeader != 0x01 {
+		t.Errorf("block header bits = %03b, want 001 (final stored block)", blockHeader)
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("zlib.NewReader() error = %v", err)
+	}
+	defer zr.Close()
+
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("zlib decompression error = %v", err)
+	}
+	if len(raw) != rawLen {
+		t.Errorf("decompressed length = %d, want %d", len(raw), rawLen)
+	}
+}
+
+func TestIDATDataBytes_CompressibleDataNotStored(t *testing.T) {
+	width, height := 16, 16
+	pixels := make([]byte, width*height*3)
+
+	data, err := IDATDataBytes(pixels, width, height, ColorRGB)
+	if err != nil {
+		t.Fatalf("IDATDataBytes() error = %v", err)
+	}
+
+	if blockType := (data[2] >> 1) & 0x03; blockType == compress.BlockTypeStored {
+		t.Errorf("compressible data was written as a stored block")
+	}
+}
+
+func TestIDATDataBytes_StoredFallbackLargeImage(t *testing.T) {
+	// 256x100 RGB random image: over 65535 bytes of scanlines
+	width, height := 256, 100
+	rng := rand.New(rand.NewSource(2))
+	pixels := make([]byte, width*height*3)
+	rng.Read(pixels)
+
+	opts := FastOptions(width, height)
+	opts.FilterStrategy = FilterStrategyNone
+	data, err := IDATDataBytesWithOptions(pixels, width, height, ColorRGB, opts)
+	if err != nil {
+		t.Fatalf("IDATDataBytesWithOptions() error = %v", err)
+	}
+
+	rawLen := (1 + width*3) * height
+	if limit := 2 + compress.StoredBlocksSize(rawLen) + 4; len(data) > limit {
+		t.Errorf("IDAT data length = %d, want <= %d", len(data), limit)
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("zlib.NewReader() error = %v", err)
+	}
+	defer zr.Close()
+
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("zlib decompression error = %v", err)
+	}
+	if len(raw) != rawLen {
+		t.Errorf("decompressed length = %d, want %d", len(raw), rawLen)
+	}
+}
+
+func TestMaxIDATSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		width     int
+		height    int
+		colorType ColorType
+	}{
+		{"1x1 RGB", 1, 1, ColorRGB},
+		{"16x16 RGBA", 16, 16, ColorRGBA},
+		{"300x300 grayscale", 300, 300, ColorGrayscale},
+	}
+
+	rng := rand.New(rand.NewSource(7))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Random pixels do not compress, so this is close to the worst case.
+			pixels := make([]byte, tt.width*tt.height*BytesPerPixel(tt.colorType))
+			rng.Read(pixels)
+
+			for _, level := range []int{1, 6, 9} {
+				opts := BalancedOptions(tt.width, tt.height)
+				opts.CompressionLevel = level
+				data, err := IDATDataBytesWithOptions(pixels, tt.width, tt.height, tt.colorType, opts)
+				if err != nil {
+					t.Fatalf("IDATDataBytesWithOptions() error = %v", err)
+				}
+				if bound := MaxIDATSize(tt.width, tt.height, tt.colorType); len(data) > bound {
+					t.Errorf("level %d: IDAT data = %d bytes, exceeds MaxIDATSize %d", level, len(data), bound)
+				}
+			}
+		})
+	}
+
+	rawLen := (1 + 16*4) * 16
+	if got, want := MaxIDATSize(16, 16, ColorRGBA), 2+5+rawLen+4; got != want {
+		t.Errorf("MaxIDATSize(16, 16, RGBA) = %d, want %d", got, want)
+	}
+	if got := MaxIDATSize(1<<30, 1<<30, ColorRGBA); got != math.MaxInt {
+		t.Errorf("MaxIDATSize(huge) = %d, want math.MaxInt", got)
+	}
+}
+
+func TestExpectedIDATSizeLargeDimensions(t *testing.T) {
+	side := 1<<31 - 1
+	got := ExpectedIDATSize(side, side, ColorRGBA)
+	if got < 1<<61 {
+		t.Errorf("ExpectedIDATSize(2^31-1, 2^31-1) = %d, want a saturated size of at least 2^61", got)
+	}
+
+	// 65536x65536 RGBA is ~16 GiB uncompressed and must not wrap.
+	if got := ExpectedIDATSize(65536, 65536, ColorRGBA); got < 1<<33 {
+		t.Errorf("ExpectedIDATSize(65536, 65536) = %d, want at least %d", got, 1<<33)
+	}
+}
+
+func TestIDATDataBytes_ZlibDictionary(t *testing.T) {
+	width, height := 16, 4
+	pixels := make([]byte, width*height*3)
+	rng := rand.New(rand.NewSource(7))
+	rng.Read(pixels)
+
+	// A previous, nearly identical sprite serves as the dictionary.
+	opts := FastOptions(width, height)
+	opts.ColorType = ColorRGB
+	opts.FilterStrategy = FilterStrategyNone
+	var dict []byte
+	for y := 0; y < height; y++ {
+		dict = append(dict, 0)
+		dict = append(dict, pixels[y*width*3:(y+1)*width*3]...)
+	}
+	pixels[0] ^= 0xFF
+
+	plain, err := IDATDataBytesWithOptions(pixels, width, height, ColorRGB, opts)
+	if err != nil {
+		t.Fatalf("IDATDataBytesWithOptions() error = %v", err)
+	}
+	opts.ZlibDictionary = dict
+	data, err := IDATDataBytesWithOptions(pixels, width, height, ColorRGB, opts)
+	if err != nil {
+		t.Fatalf("IDATDataBytesWithOptions() with dictionary error = %v", err)
+	}
+
+	if data[1]&0x20 == 0 {
+		t.Errorf("FLG = 0x%02X, want FDICT bit set", data[1])
+	}
+	if len(data) >= len(plain) {
+		t.Errorf("size with dictionary = %d, want < %d", len(data), len(plain))
+	}
+
+	r, err := zlib.NewReaderDict(bytes.NewReader(data), dict)
+	if err != nil {
+		t.Fatalf("zlib.NewReaderDict() error = %v", err)
+	}
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("zlib decompression error = %v", err)
+	}
+	if got := unfilterScanlines(t, raw, width, height, 3); !bytes.Equal(got, pixels) {
+		t.Error("decoded pixels do not match input")
+	}
+}
@@ -16,7 +16,18 @@ type IHDRData struct {
 	Interlace   uint8
 }
 
+// maxIHDRDimension is the largest width or height PNG allows (2^31-1).
+const maxIHDRDimension = 1<<31 - 1
+
 func NewIHDRData(width, height int, bitDepth, colorType uint8) (*IHDRData, error) {
+	// Check before the uint32 conversion, which would wrap negative or oversized values.
+	if width <= 0 || height <= 0 {
+		return nil, ErrInvalidDimensions
+	}
+	if width > maxIHDRDimension || height > maxIHDRDimension {
+		return nil, fmt.Errorf("png: dimensions %dx%d exceed maximum (2^31-1)", width, height)
+	}
+
 	ihdr := &IHDRData{
 		Width:       uint32(width),
 		Height:      uint32(height),
@@ -46,35 +57,38 @@ func (i *IHDRData) Bytes() []byte {
 	return result
 }
 
+// validBitDepths lists the bit depths the PNG spec allows for each color type.
+var validBitDepths = map[ColorType][]uint8{
+	ColorGrayscale: {1, 2, 4, 8, 16},
+	ColorRGB:       {8, 16},
+	ColorIndexed:   {1, 2, 4, 8},
+	ColorRGBA:      {8, 16},
+}
+
+// isValidBitDepth reports whether depth is allowed for colorType.
+func isValidBitDepth(colorType ColorType, depth uint8) bool {
+	for _, d := range validBitDepths[colorType] {
+		if d == depth {
+			return true
+		}
+	}
+	return false
+}
+
 func (i *IHDRData) Validate() error {
 	if i.Width == 0 || i.Height == 0 {
 		return ErrInvalidDimensions
 	}
 
-	if i.Width > 0x7FFFFFFF || i.Height > 0x7FFFFFFF {
+	if i.Width > maxIHDRDimension || i.Height > maxIHDRDimension {
 		return fmt.Errorf("png: dimensions exceed maximum (2^31-1)")
 	}
 
-	validBitDepths := map[ColorType][]uint8{
-		ColorGrayscale: {1, 2, 4, 8, 16},
-		ColorRGB:       {8, 16},
-		ColorRGBA:      {8, 16},
-	}
-
-	allowedDepths, ok := validBitDepths[i.ColorType]
-	if !ok {
+	if _, ok := validBitDepths[i.ColorType]; !ok {
 		return fmt.Errorf("png: invalid color type %d", i.ColorType)
 	}
 
-	validDepth := false
-	for _, depth := range allowedDepths {
-		if i.BitDepth == depth {
-			validDepth = true
-			break
-		}
-	}
-
-	if !validDepth {
+	if !isValidBitDepth(i.ColorType, i.BitDepth) {
 		return fmt.Errorf("png: bit depth %d not valid for color type %d", i.BitDepth, i.ColorType)
 	}
 
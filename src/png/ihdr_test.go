@@ -127,6 +127,22 @@ func TestIHDRValidate(t *testing.T) {
 			colorType: 2,
 			wantErr:   true,
 		},
+		{
+			name:      "valid indexed 4-bit",
+			width:     10,
+			height:    10,
+			bitDepth:  4,
+			colorType: 3,
+			wantErr:   false,
+		},
+		{
+			name:      "invalid bit depth 16 for indexed",
+			width:     10,
+			height:    10,
+			bitDepth:  16,
+			colorType: 3,
+			wantErr:   true,
+		},
 		{
 			name:      "invalid color type",
 			width:     100,
@@ -245,3 +261,32 @@ func TestWriteIHDRLargeImage(t *testing.T) {
 		t.Errorf("chunk type = %q, want %q", typeStr, "IHDR")
 	}
 }
+
+func TestNewIHDRDataDimensionLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		width   int
+		height  int
+		wantErr bool
+	}{
+		{"max width", 1<<31 - 1, 1, false},
+		{"max height", 1, 1<<31 - 1, false},
+		{"width 2^31", 1 << 31, 1, true},
+		{"height 2^31", 1, 1 << 31, true},
+		{"width wraps to small uint32", 1<<32 + 5, 1, true},
+		{"negative width", -1, 1, true},
+		{"zero height", 1, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ihdr, err := NewIHDRData(tt.width, tt.height, 8, 2)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIHDRData(%d, %d) error = %v, wantErr %v", tt.width, tt.height, err, tt.wantErr)
+			}
+			if err == nil && (int(ihdr.Width) != tt.width || int(ihdr.Height) != tt.height) {
+				t.Errorf("NewIHDRData() = %dx%d, want %dx%d", ihdr.Width, ihdr.Height, tt.width, tt.height)
+			}
+		})
+	}
+}
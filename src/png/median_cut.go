@@ -59,7 +59,10 @@ func MedianCut(colorsWithCount []ColorWithCount, maxColors int) []Color {
 	return result
 }
 
-// splitBucket splits a bucket into two at the median.
+// splitBucket splits a bucket into two at the median of the channel with the
+// widest range. Ties between equal ranges go to R, then G, then B, and colors
+// equal on that channel are ordered by R, G, B and then count, so the split
+// does not depend on the input order.
 func splitBucket(colors []ColorWithCount) ([]ColorWithCount, []ColorWithCount) {
 	if len(colors) < 2 {
 		return colors, nil
@@ -108,15 +111,25 @@ func splitBucket(colors []ColorWithCount) ([]ColorWithCount, []ColorWithCount) {
 	sorted := make([]ColorWithCount, len(colors))
 	copy(sorted, colors)
 
-	sort.Slice(sorted, func(i, j int) bool {
+	channel := func(c ColorWithCount) uint8 {
 		switch sortBy {
 		case 0:
-			return sorted[i].R < sorted[j].R
+			return c.R
 		case 1:
-			return sorted[i].G < sorted[j].G
+			return c.G
 		default:
-			return sorted[i].B < sorted[j].B
+			return c.B
 		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if ca, cb := channel(a), channel(b); ca != cb {
+			return ca < cb
+		}
+		if a.Color != b.Color {
+			return colorLess(a.Color, b.Color)
+		}
+		return a.Count < b.Count
 	})
 
 	mid := len(sorted) / 2
@@ -124,20 +137,30 @@ func splitBucket(colors []ColorWithCount) ([]ColorWithCount, []ColorWithCount) {
 	return sorted[:mid], sorted[mid:]
 }
 
-// averageColors calculates the average color of all colors in the bucket.
+// averageColors calculates the count-weighted average color of the bucket.
+// Sums are kept in int64 so large counts cannot overflow on 32-bit platforms.
+// If every count is zero the colors are averaged unweighted.
 func averageColors(colors []ColorWithCount) Color {
-	var totalR, totalG, totalB int
-	var totalCount int
+	if len(colors) == 0 {
+		return Color{}
+	}
 
+	var totalR, totalG, totalB, totalCount int64
 	for _, c := range colors {
-		totalR += int(c.Color.R) * c.Count
-		totalG += int(c.Color.G) * c.Count
-		totalB += int(c.Color.B) * c.Count
-		totalCount += c.Count
+		n := int64(c.Count)
+		totalR += int64(c.R) * n
+		totalG += int64(c.G) * n
+		totalB += int64(c.B) * n
+		totalCount += n
 	}
 
 	if totalCount == 0 {
-		totalCount = len(colors)
+		for _, c := range colors {
+			totalR += int64(c.R)
+			totalG += int64(c.G)
+			totalB += int64(c.B)
+		}
+		totalCount = int64(len(colors))
 	}
 
 	return Color{
@@ -147,21 +170,28 @@ func averageColors(colors []ColorWithCount) Color {
 	}
 }
 
+// alphaBucket represents a collection of colors with alpha for median cut.
+type alphaBucket struct {
+	colors []AlphaColorWithCount
+}
+
 // MedianCutWithAlpha performs median cut including alpha channel.
-func MedianCutWithAlpha(colorsWithCount []ColorWithCount, maxColors int) []Color {
+// Alpha is treated as a fourth axis, so colors that differ only in
+// transparency can end up in separate palette entries.
+func MedianCutWithAlpha(colorsWithCount []AlphaColorWithCount, maxColors int) []AlphaColor {
 	if len(colorsWithCount) == 0 {
-		return []Color{}
+		return []AlphaColor{}
 	}
 
 	if len(colorsWithCount) <= maxColors {
-		result := make([]Color, len(colorsWithCount))
+		result := make([]AlphaColor, len(colorsWithCount))
 		for i, cwc := range colorsWithCount {
-			result[i] = cwc.Color
+			result[i] = cwc.AlphaColor
 		}
 		return result
 	}
 
-	buckets := []bucket{{colors: colorsWithCount}}
+	buckets := []alphaBucket{{colors: colorsWithCount}}
 
 	for len(buckets) < maxColors {
 		largestIdx := -1
@@ -177,20 +207,111 @@ func MedianCutWithAlpha(colorsWithCount []ColorWithCount, maxColors int) []Color
 			break
 		}
 
-		left, right := splitBucket(buckets[largestIdx].colors)
+		left, right := splitAlphaBucket(buckets[largestIdx].colors)
 
 		buckets[largestIdx].colors = left
 		if len(right) > 0 {
-			buckets = append(buckets, bucket{colors: right})
+			buckets = append(buckets, alphaBucket{colors: right})
 		}
 	}
 
-	result := make([]Color, 0, maxColors)
+	result := make([]AlphaColor, 0, maxColors)
 	for _, b := range buckets {
 		if len(b.colors) > 0 {
-			result = append(result, averageColors(b.colors))
+			result = append(result, averageAlphaColors(b.colors))
 		}
 	}
 
 	return result
 }
+
+// splitAlphaBucket splits a bucket into two at the median of its widest R/G/B/A axis.
+// Like splitBucket, ties between axes go to the earlier channel and equal values
+// are ordered by R, G, B, A and then count.
+func splitAlphaBucket(colors []AlphaColorWithCount) ([]AlphaColorWithCount, []AlphaColorWithCount) {
+	if len(colors) < 2 {
+		return colors, nil
+	}
+
+	minC := [4]uint8{255, 255, 255, 255}
+	maxC := [4]uint8{}
+
+	for _, c := range colors {
+		channels := [4]uint8{c.R, c.G, c.B, c.A}
+		for ch, v := range channels {
+			if v < minC[ch] {
+				minC[ch] = v
+			}
+			if v > maxC[ch] {
+				maxC[ch] = v
+			}
+		}
+	}
+
+	sortBy := 0
+	maxRange := int(maxC[0]) - int(minC[0])
+	for ch := 1; ch < 4; ch++ {
+		if r := int(maxC[ch]) - int(minC[ch]); r > maxRange {
+			maxRange = r
+			sortBy = ch
+		}
+	}
+
+	sorted := make([]AlphaColorWithCount, len(colors))
+	copy(sorted, colors)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a := [5]int{int(sorted[i].R), int(sorted[i].G), int(sorted[i].B), int(sorted[i].A), sorted[i].Count}
+		b := [5]int{int(sorted[j].R), int(sorted[j].G), int(sorted[j].B), int(sorted[j].A), sorted[j].Count}
+		if a[sortBy] != b[sortBy] {
+			return a[sortBy] < b[sortBy]
+		}
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+
+	mid := len(sorted) / 2
+
+	return sorted[:mid], sorted[mid:]
+}
+
+// averageAlphaColors calculates the count-weighted average color and alpha of
+// the bucket, with the same int64 sums and zero-count fallback as averageColors.
+func averageAlphaColors(colors []AlphaColorWithCount) AlphaColor {
+	if len(colors) == 0 {
+		return AlphaColor{}
+	}
+
+	var totalR, totalG, totalB, totalA, totalCount int64
+	for _, c := range colors {
+		n := int64(c.Count)
+		totalR += int64(c.R) * n
+		totalG += int64(c.G) * n
+		totalB += int64(c.B) * n
+		totalA += int64(c.A) * n
+		totalCount += n
+	}
+
+	if totalCount == 0 {
+		for _, c := range colors {
+			totalR += int64(c.R)
+			totalG += int64(c.G)
+			totalB += int64(c.B)
+			totalA += int64(c.A)
+		}
+		totalCount = int64(len(colors))
+	}
+
+	return AlphaColor{
+		Color: Color{
+			R: uint8(totalR / totalCount),
+			G: uint8(totalG / totalCount),
+			B: uint8(totalB / totalCount),
+		},
+		A: uint8(totalA / totalCount),
+	}
+}
@@ -1,6 +1,9 @@
 package png
 
 import (
+	"math"
+	"math/rand"
+	"reflect"
 	"testing"
 )
 
@@ -235,3 +238,98 @@ func TestAverageColorsSingle(t *testing.T) {
 		t.Errorf("averageColors() single = %v, want (100, 150, 200)", avg)
 	}
 }
+
+func TestAverageColorsLargeCounts(t *testing.T) {
+	// The counts sum past 2^31 and each weighted sum overflows int32.
+	colors := []ColorWithCount{
+		{Color{10, 200, 255}, math.MaxInt32},
+		{Color{30, 100, 255}, math.MaxInt32},
+	}
+
+	if got, want := averageColors(colors), (Color{20, 150, 255}); got != want {
+		t.Errorf("averageColors() = %v, want %v", got, want)
+	}
+}
+
+func TestAverageColorsZeroCounts(t *testing.T) {
+	tests := []struct {
+		name   string
+		colors []ColorWithCount
+		want   Color
+	}{
+		{"all counts zero", []ColorWithCount{{Color{10, 20, 30}, 0}, {Color{30, 40, 50}, 0}}, Color{20, 30, 40}},
+		{"empty", nil, Color{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := averageColors(tt.colors); got != tt.want {
+				t.Errorf("averageColors() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMedianCutWithAlphaSplitsOnAlpha(t *testing.T) {
+	// RGB differs by only 5, alpha by 255: the split must happen on alpha.
+	colors := []AlphaColorWithCount{
+		{AlphaColor{Color{255, 0, 0}, 255}, 10},
+		{AlphaColor{Color{255, 0, 0}, 0}, 10},
+		{AlphaColor{Color{250, 0, 0}, 255}, 10},
+		{AlphaColor{Color{250, 0, 0}, 0}, 10},
+	}
+
+	result := MedianCutWithAlpha(colors, 2)
+
+	if len(result) != 2 {
+		t.Fatalf("MedianCutWithAlpha() = %v colors, want 2", len(result))
+	}
+
+	alphas := map[uint8]bool{}
+	for _, c := range result {
+		alphas[c.A] = true
+	}
+	if !alphas[0] || !alphas[255] {
+		t.Errorf("MedianCutWithAlpha() alphas = %v, want both 0 and 255", result)
+	}
+}
+
+func TestAverageAlphaColorsWeighted(t *testing.T) {
+	colors := []AlphaColorWithCount{
+		{AlphaColor{Color{0, 0, 0}, 0}, 3},
+		{AlphaColor{Color{200, 200, 200}, 200}, 1},
+	}
+
+	avg := averageAlphaColors(colors)
+
+	if avg.R != 50 || avg.A != 50 {
+		t.Errorf("averageAlphaColors() = %v, want R=50 A=50", avg)
+	}
+}
+
+func TestMedianCutEqualRangesDeterministic(t *testing.T) {
+	// Every channel spans 0..200, so the first split must fall back to the
+	// R>G>B tie-break, and several colors share each channel value.
+	colors := []ColorWithCount{
+		{Color{0, 200, 100}, 5},
+		{Color{0, 0, 200}, 5},
+		{Color{100, 100, 0}, 5},
+		{Color{100, 0, 100}, 5},
+		{Color{200, 200, 200}, 5},
+		{Color{200, 0, 0}, 5},
+		{Color{0, 100, 0}, 5},
+		{Color{200, 100, 100}, 5},
+	}
+	want := []Color{{50, 0, 150}, {175, 100, 75}, {0, 150, 50}}
+
+	rng := rand.New(rand.NewSource(1))
+	for run := 0; run < 20; run++ {
+		shuffled := append([]ColorWithCount(nil), colors...)
+		rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+		got := MedianCut(shuffled, 3)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("run %d: MedianCut() = %v, want %v", run, got, want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package png
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// metersPerInch converts pHYs pixels-per-meter to dots per inch.
+const metersPerInch = 0.0254
+
+// Metadata is the descriptive information ReadMetadata finds before the image data.
+type Metadata struct {
+	Width     int
+	Height    int
+	ColorType ColorType
+	// Text holds tEXt keywords and their values, converted from Latin-1 to UTF-8.
+	Text map[string]string
+	// ModTime is the tIME last-modification time in UTC, or the zero time.
+	ModTime time.Time
+	// DPIX and DPIY are the pHYs resolution in dots per inch, or zero when the
+	// file has no pHYs chunk or gives only an aspect ratio.
+	DPIX float64
+	DPIY float64
+}
+
+// ReadMetadata reads chunks from r up to the first IDAT and returns the IHDR
+// fields, tEXt entries, tIME, and pHYs resolution, without reading or
+// decompressing image data. Chunks after the image data, such as a trailing
+// tEXt, are not seen. Chunk CRCs are verified.
+func ReadMetadata(r io.Reader) (Metadata, error) {
+	if err := readSignature(r); err != nil {
+		return Metadata{}, err
+	}
+
+	meta := Metadata{Text: make(map[string]string)}
+	sawIHDR := false
+	for {
+		length, chunkType, err := readChunkHeader(r)
+		if err != nil {
+			return Metadata{}, err
+		}
+		if chunkType == "IDAT" || chunkType == "IEND" {
+			break
+		}
+		chunk, err := readChunkBody(r, length, chunkType)
+		if err != nil {
+			return Metadata{}, err
+		}
+
+		if !sawIHDR && chunkType != "IHDR" {
+			return Metadata{}, fmt.Errorf("%w: first chunk must be IHDR", ErrInvalidChunkOrder)
+		}
+		switch chunkType {
+		case "IHDR":
+			ihdr, err := parseIHDR(chunk.Data)
+			if err != nil {
+				return Metadata{}, err
+			}
+			meta.Width, meta.Height, meta.ColorType = int(ihdr.Width), int(ihdr.Height), ihdr.ColorType
+			sawIHDR = true
+		case "tEXt":
+			keyword, text, ok := bytes.Cut(chunk.Data, []byte{0})
+			if !ok || len(keyword) == 0 {
+				return Metadata{}, fmt.Errorf("png: tEXt chunk has no keyword")
+			}
+			meta.Text[latin1ToUTF8(keyword)] = latin1ToUTF8(text)
+		case "tIME":
+			if len(chunk.Data) != 7 {
+				return Metadata{}, fmt.Errorf("png: tIME chunk is %d bytes, want 7", len(chunk.Data))
+			}
+			d := chunk.Data
+			meta.ModTime = time.Date(int(binary.BigEndian.Uint16(d[0:2])), time.Month(d[2]), int(d[3]),
+				int(d[4]), int(d[5]), int(d[6]), 0, time.UTC)
+		case "pHYs":
+			if len(chunk.Data) != 9 {
+				return Metadata{}, fmt.Errorf("png: pHYs chunk is %d bytes, want 9", len(chunk.Data))
+			}
+			if chunk.Data[8] == 1 {
+				meta.DPIX = float64(binary.BigEndian.Uint32(chunk.Data[0:4])) * metersPerInch
+				meta.DPIY = float64(binary.BigEndian.Uint32(chunk.Data[4:8])) * metersPerInch
+			}
+		}
+	}
+	if !sawIHDR {
+		return Metadata{}, fmt.Errorf("%w: first chunk must be IHDR", ErrInvalidChunkOrder)
+	}
+	return meta, nil
+}
+
+// latin1ToUTF8 converts ISO 8859-1 bytes, the encoding of tEXt, to a Go string.
+func latin1ToUTF8(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
@@ -0,0 +1,85 @@
+package png
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestReadMetadata(t *testing.T) {
+	mustChunk := func(typ string, data []byte) *Chunk {
+		t.Helper()
+		c, err := NewChunk(typ, data)
+		if err != nil {
+			t.Fatalf("NewChunk(%q) error = %v", typ, err)
+		}
+		return c
+	}
+	phys := make([]byte, 9)
+	binary.BigEndian.PutUint32(phys[0:4], 11811) // 300 DPI
+	binary.BigEndian.PutUint32(phys[4:8], 5906)  // 150 DPI
+	phys[8] = 1
+
+	opts := FastOptions(2, 2)
+	opts.ColorType = ColorRGB
+	opts.ExtraChunks = []*Chunk{
+		mustChunk("tEXt", []byte("Title\x00Caf\xe9")),
+		mustChunk("tEXt", []byte("Author\x00pixo")),
+		mustChunk("tIME", []byte{0x07, 0xEA, 10, 15, 13, 45, 30}),
+		mustChunk("pHYs", phys),
+	}
+	data, err := EncodeWithOptions(make([]byte, 2*2*3), opts)
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+
+	// Cut the file just after the IDAT header: nothing past it should be read.
+	idat := bytes.Index(data, []byte("IDAT"))
+	meta, err := ReadMetadata(bytes.NewReader(data[:idat+4]))
+	if err != nil {
+		t.Fatalf("ReadMetadata() error = %v", err)
+	}
+
+	if meta.Width != 2 || meta.Height != 2 || meta.ColorType != ColorRGB {
+		t.Errorf("ReadMetadata() header = %dx%d %s, want 2x2 RGB", meta.Width, meta.Height, meta.ColorType)
+	}
+	if meta.Text["Title"] != "Café" || meta.Text["Author"] != "pixo" || len(meta.Text) != 2 {
+		t.Errorf("ReadMetadata() Text = %v, want Title=Café Author=pixo", meta.Text)
+	}
+	if want := time.Date(2026, 10, 15, 13, 45, 30, 0, time.UTC); !meta.ModTime.Equal(want) {
+		t.Errorf("ReadMetadata() ModTime = %v, want %v", meta.ModTime, want)
+	}
+	if math.Abs(meta.DPIX-300) > 0.01 || math.Abs(meta.DPIY-150) > 0.02 {
+		t.Errorf("ReadMetadata() DPI = %.2f x %.2f, want 300 x 150", meta.DPIX, meta.DPIY)
+	}
+}
+
+func TestReadMetadataErrors(t *testing.T) {
+	valid, err := EncodeWithOptions(make([]byte, 4), Options{Width: 2, Height: 2, ColorType: ColorGrayscale, CompressionLevel: 6})
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+	badCRC := append([]byte(nil), valid...)
+	badCRC[8+8+13] ^= 0xFF // IHDR CRC
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"bad signature", []byte("not a png at all"), ErrInvalidSignature},
+		{"bad crc", badCRC, ErrInvalidChunkData},
+		{"truncated ihdr", valid[:20], ErrInvalidChunkData},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadMetadata(bytes.NewReader(tt.data)); !errors.Is(err, tt.want) {
+				t.Errorf("ReadMetadata() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package png
+
+// renderingChunks are the ancillary chunks that change how pixels are displayed,
+// so Optimize keeps them even with StripMetadata.
+var renderingChunks = map[string]bool{
+	"cHRM": true, "gAMA": true, "iCCP": true, "sRGB": true,
+}
+
+// Optimize decodes an existing PNG and re-encodes it with opts, returning
+// whichever of data and the re-encoded file is smaller. Width, Height, and
+// ColorType in opts are taken from the image; indexed input turns on
+// AutoPalette unless MaxColors is set. Ancillary chunks are carried over after
+// any opts.ExtraChunks, except that StripMetadata keeps only the color-space chunks
+// (cHRM, gAMA, iCCP, sRGB). Chunks that must follow PLTE, such as bKGD and
+// hIST, are dropped because ExtraChunks are written before it.
+func Optimize(data []byte, opts Options) ([]byte, error) {
+	img, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Width = img.Width
+	opts.Height = img.Height
+	opts.ColorType = img.ColorType
+	if img.Indexed && opts.MaxColors == 0 {
+		opts.AutoPalette = true
+	}
+
+	opts.ExtraChunks = append([]*Chunk(nil), opts.ExtraChunks...)
+	for _, c := range img.Chunks {
+		if chunksAfterPLTE[c.Type()] {
+			continue
+		}
+		if opts.StripMetadata && !renderingChunks[c.Type()] {
+			continue
+		}
+		opts.ExtraChunks = append(opts.ExtraChunks, c)
+	}
+
+	encoder, err := NewEncoderWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	optimized, err := encoder.Encode(img.Pixels)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(optimized) < len(data) {
+		return optimized, nil
+	}
+	return data, nil
+}
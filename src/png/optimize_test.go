@@ -0,0 +1,77 @@
+package png
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestOptimizeStripsMetadata(t *testing.T) {
+	width, height := 32, 32
+	pixels := make([]byte, width*height*3)
+	for i := range pixels {
+		pixels[i] = byte(i / 3 % width * 8)
+	}
+
+	text, err := NewChunk("tEXt", []byte("Comment\x00bogus metadata"))
+	if err != nil {
+		t.Fatalf("NewChunk() error = %v", err)
+	}
+	gamma, err := NewChunk("gAMA", []byte{0, 0, 0xB1, 0x8F})
+	if err != nil {
+		t.Fatalf("NewChunk() error = %v", err)
+	}
+	opts := FastOptions(width, height)
+	opts.ColorType = ColorRGB
+	opts.CompressionLevel = 1
+	opts.FilterStrategy = FilterStrategyNone
+	opts.ExtraChunks = []*Chunk{text, gamma}
+	original, err := EncodeWithOptions(pixels, opts)
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+
+	optimized, err := Optimize(original, MaxOptions(0, 0))
+	if err != nil {
+		t.Fatalf("Optimize() error = %v", err)
+	}
+	if len(optimized) >= len(original) {
+		t.Fatalf("Optimize() size = %d, want < %d", len(optimized), len(original))
+	}
+
+	var types []string
+	if err := IterateChunks(optimized, func(c *Chunk) error {
+		types = append(types, c.Type())
+		return nil
+	}); err != nil {
+		t.Fatalf("IterateChunks() error = %v", err)
+	}
+	for _, typ := range types {
+		if typ == "tEXt" {
+			t.Errorf("chunks = %v, want no tEXt", types)
+		}
+	}
+	findFirstChunk(t, parsePNGChunks(t, optimized), "gAMA")
+	assertDecodedPixels(t, optimized, width, height, ColorRGB, pixels)
+}
+
+func TestOptimizeKeepsSmallerOriginal(t *testing.T) {
+	original, err := EncodeWithOptions([]byte{10, 20, 30}, Options{Width: 1, Height: 1, ColorType: ColorRGB, CompressionLevel: 9})
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+
+	// The caller's own chunk makes the re-encoded file the larger one.
+	text, err := NewChunk("tEXt", []byte("Comment\x00added by the caller"))
+	if err != nil {
+		t.Fatalf("NewChunk() error = %v", err)
+	}
+	opts := MaxOptions(0, 0)
+	opts.ExtraChunks = []*Chunk{text}
+	got, err := Optimize(original, opts)
+	if err != nil {
+		t.Fatalf("Optimize() error = %v", err)
+	}
+	if !bytes.Equal(got, original) {
+		t.Errorf("Optimize() = %d bytes, want the %d-byte original", len(got), len(original))
+	}
+}
@@ -1,5 +1,11 @@
 package png
 
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
 type Preset int
 
 const (
@@ -11,6 +17,9 @@ const (
 type FilterStrategy int
 
 const (
+	// FilterStrategyNone writes every scanline with filter type 0 and performs no
+	// per-row selection. Output compresses worse, but decoders never have to reverse
+	// Sub/Up/Average/Paeth, which helps slow or constrained decoders.
 	FilterStrategyNone FilterStrategy = iota
 	FilterStrategySub
 	FilterStrategyUp
@@ -21,18 +30,220 @@ const (
 	FilterStrategyAdaptiveFast
 )
 
+// String returns the strategy name, e.g. "MinSum", or "Unknown(N)".
+func (f FilterStrategy) String() string {
+	switch f {
+	case FilterStrategyNone:
+		return "None"
+	case FilterStrategySub:
+		return "Sub"
+	case FilterStrategyUp:
+		return "Up"
+	case FilterStrategyAverage:
+		return "Average"
+	case FilterStrategyPaeth:
+		return "Paeth"
+	case FilterStrategyMinSum:
+		return "MinSum"
+	case FilterStrategyAdaptive:
+		return "Adaptive"
+	case FilterStrategyAdaptiveFast:
+		return "AdaptiveFast"
+	default:
+		return fmt.Sprintf("Unknown(%d)", int(f))
+	}
+}
+
+// Options configures encoding. It marshals to JSON with camelCase keys and
+// string names for ColorType and FilterStrategy. Unmarshal into a preset
+// (e.g. BalancedOptions) to keep its values for keys a config omits.
 type Options struct {
-	Width            int
-	Height           int
-	ColorType        ColorType
-	CompressionLevel int
-	FilterStrategy   FilterStrategy
-	OptimizeAlpha    bool
-	ReduceColorType  bool
-	StripMetadata    bool
-	OptimalDeflate   bool
-	MaxColors        int
-	Dithering        bool
+	Width            int            `json:"width"`
+	Height           int            `json:"height"`
+	ColorType        ColorType      `json:"colorType"`
+	BitDepth         int            `json:"bitDepth,omitempty"` // 0 means 8
+	CompressionLevel int            `json:"compressionLevel"`
+	FilterStrategy   FilterStrategy `json:"filterStrategy"`
+	OptimizeAlpha    bool           `json:"optimizeAlpha"`
+	ReduceColorType  bool           `json:"reduceColorType"`
+	StripMetadata    bool           `json:"stripMetadata"`
+	OptimalDeflate   bool           `json:"optimalDeflate"`
+	MaxColors        int            `json:"maxColors"`
+	Dithering        bool           `json:"dithering"`
+	// AutoPalette writes an indexed PNG with an exact palette when the image
+	// has at most 256 distinct colors. Unlike MaxColors this is lossless.
+	AutoPalette bool `json:"autoPalette"`
+	// TrimPalette drops palette entries no pixel uses from indexed output and
+	// renumbers the rest, shrinking PLTE and tRNS.
+	TrimPalette bool `json:"trimPalette,omitempty"`
+	// SortPalette reorders indexed output's palette by luminance before PLTE
+	// is written (see Palette.SortForCompression), which usually helps the
+	// filters and DEFLATE on images with smooth tones.
+	SortPalette bool `json:"sortPalette,omitempty"`
+	// Parallel maps pixels to the MaxColors palette on several goroutines
+	// (QuantizeToPaletteParallel) when Dithering is off. The output is the same.
+	Parallel bool `json:"parallel,omitempty"`
+	// ExtraChunks are ancillary chunks written right after IHDR, in order.
+	// tRNS, bKGD, and hIST are rejected: they must follow PLTE, and their
+	// contents depend on the color type and palette the encoder chooses.
+	ExtraChunks []*Chunk `json:"-"`
+	// InputIsBGRA means RGB or RGBA input has its red and blue channels swapped
+	// (BGR/BGRA, as produced by Windows and many GPU APIs); see SwapRB.
+	InputIsBGRA bool `json:"inputIsBGRA,omitempty"`
+	// FlattenBackground, when set, composites RGBA input over this color and
+	// encodes the result as RGB, before any palette or color-type reduction.
+	FlattenBackground *Color `json:"flattenBackground,omitempty"`
+	// TransparentColor, when set, writes a tRNS chunk marking this color as
+	// fully transparent in RGB or grayscale input (grayscale input uses its R
+	// value as the gray level). If ReduceColorType turns RGB into grayscale the
+	// key is kept only when R, G and B are equal. It has no effect on RGBA input
+	// or indexed output.
+	TransparentColor *Color `json:"transparentColor,omitempty"`
+	// Equalize applies histogram equalization before encoding (EqualizeLuminance),
+	// stretching low-contrast images across the full 0-255 range. Lossy.
+	Equalize bool `json:"equalize,omitempty"`
+	// Posterize, when 1-7, snaps color channels to 2^Posterize levels before
+	// encoding (see Posterize). Zero disables it. Lossy.
+	Posterize int `json:"posterize,omitempty"`
+	// PreBlurRadius, when positive, box-blurs the image with this radius before
+	// any other adjustment or quantization (see BoxBlur). Lossy.
+	PreBlurRadius int `json:"preBlurRadius,omitempty"`
+	// Sharpen, when positive, applies an unsharp mask with this amount (radius 1)
+	// after any blur (see UnsharpMask). Typical values are 0.5-2. Lossy.
+	Sharpen float64 `json:"sharpen,omitempty"`
+	// RowStride is the number of bytes between the starts of consecutive rows
+	// in the input, for encoding a sub-image of a larger buffer; only the first
+	// Width*BytesPerPixel bytes of each row are used. Zero means rows are tightly
+	// packed. The last row may be short, so a sub-image slice need not be copied.
+	RowStride int `json:"rowStride,omitempty"`
+	// InputBitDepth is the sample depth of the input pixels: 0 or 8, or 16 for
+	// big-endian 16-bit samples (48-bit RGB, 64-bit RGBA), which are rounded to
+	// 8 bits with Downsample16to8 before anything else. RowStride counts input
+	// bytes.
+	InputBitDepth int `json:"inputBitDepth,omitempty"`
+	// ZlibDictionary, when set, is a preset dictionary the IDAT stream is
+	// compressed against (FDICT in the zlib header), which shrinks many small,
+	// similar images. Standard PNG decoders reject such files; only a decoder
+	// given the same dictionary can read them.
+	ZlibDictionary []byte `json:"-"`
+	// MaxIDATChunkSize, when positive, splits the compressed image data across
+	// consecutive IDAT chunks of at most this many bytes, as some streaming
+	// decoders and tools prefer. Each extra chunk adds 12 bytes. Zero writes a
+	// single IDAT.
+	MaxIDATChunkSize int `json:"maxIDATChunkSize,omitempty"`
+	// MaxPixels caps Width*Height to guard against huge allocations.
+	// Zero means DefaultMaxPixels.
+	MaxPixels int `json:"maxPixels,omitempty"`
+	// Progress, if set, is called at coarse milestones (see the Progress* stage
+	// names) with a fraction from 0 to 1 that never decreases; the last call is
+	// ProgressDone with 1. It runs synchronously on the encoding goroutine, so it
+	// must be cheap and must not block.
+	Progress func(stage string, fraction float64) `json:"-"`
+}
+
+// inputRowSize returns the length in bytes of one packed row of input pixels.
+func (o Options) inputRowSize() int {
+	size := o.Width * BytesPerPixel(o.ColorType)
+	if o.InputBitDepth == 16 {
+		size *= 2
+	}
+	return size
+}
+
+// DefaultMaxPixels is the pixel limit used when Options.MaxPixels is zero.
+const DefaultMaxPixels = 1 << 28
+
+// checkImageSize returns ErrImageTooLarge if width*height exceeds maxPixels
+// (DefaultMaxPixels when zero). It divides rather than multiplies, so huge
+// dimensions cannot overflow int.
+func checkImageSize(width, height, maxPixels int) error {
+	if maxPixels <= 0 {
+		maxPixels = DefaultMaxPixels
+	}
+	if width > 0 && height > maxPixels/width {
+		return ErrImageTooLarge
+	}
+	return nil
+}
+
+// Validate reports every invalid or contradictory setting in o as a single error,
+// or returns nil if the options can be used to encode.
+func (o Options) Validate() error {
+	var problems []string
+
+	if o.Width <= 0 || o.Height <= 0 {
+		problems = append(problems, fmt.Sprintf("dimensions %dx%d must be positive", o.Width, o.Height))
+	}
+	switch o.ColorType {
+	case ColorGrayscale, ColorRGB, ColorRGBA:
+	case ColorIndexed:
+		problems = append(problems, "ColorType Indexed is not an input format; pass RGB or RGBA pixels with MaxColors or AutoPalette to write an indexed PNG")
+	default:
+		problems = append(problems, fmt.Sprintf("unsupported ColorType %d", o.ColorType))
+	}
+	if o.InputIsBGRA && o.ColorType != ColorRGB && o.ColorType != ColorRGBA {
+		problems = append(problems, fmt.Sprintf("InputIsBGRA requires ColorType RGB or RGBA, got %s", o.ColorType))
+	}
+	if o.BitDepth != 0 && o.BitDepth != 8 {
+		if o.BitDepth < 0 || o.BitDepth > 16 || !isValidBitDepth(o.ColorType, uint8(o.BitDepth)) {
+			problems = append(problems, fmt.Sprintf("BitDepth %d is not valid for ColorType %s", o.BitDepth, o.ColorType))
+		} else {
+			problems = append(problems, fmt.Sprintf("BitDepth %d is not supported by the encoder; only 8-bit samples are written", o.BitDepth))
+		}
+	}
+	if o.CompressionLevel < 0 || o.CompressionLevel > 9 {
+		problems = append(problems, fmt.Sprintf("CompressionLevel %d must be between 0 and 9", o.CompressionLevel))
+	}
+	if o.FilterStrategy < FilterStrategyNone || o.FilterStrategy > FilterStrategyAdaptiveFast {
+		problems = append(problems, fmt.Sprintf("unknown FilterStrategy %d", o.FilterStrategy))
+	}
+	if o.MaxColors < 0 || o.MaxColors > 256 {
+		problems = append(problems, fmt.Sprintf("MaxColors %d must be between 0 and 256", o.MaxColors))
+	}
+	if o.MaxColors > 0 && o.ReduceColorType {
+		problems = append(problems, "MaxColors quantizes to a palette, so ReduceColorType has no effect; disable one of them")
+	}
+	if o.MaxColors > 0 && o.AutoPalette {
+		problems = append(problems, "MaxColors and AutoPalette both select the palette; disable one of them")
+	}
+	if o.Dithering && o.MaxColors == 0 {
+		problems = append(problems, "Dithering requires MaxColors to be set")
+	}
+	if o.Posterize < 0 || o.Posterize > 7 {
+		problems = append(problems, fmt.Sprintf("Posterize %d out of range 0-7", o.Posterize))
+	}
+	if o.PreBlurRadius < 0 {
+		problems = append(problems, fmt.Sprintf("PreBlurRadius %d must not be negative", o.PreBlurRadius))
+	}
+	if o.Sharpen < 0 || math.IsNaN(o.Sharpen) || math.IsInf(o.Sharpen, 0) {
+		problems = append(problems, fmt.Sprintf("Sharpen %v must be a non-negative number", o.Sharpen))
+	}
+	if o.InputBitDepth != 0 && o.InputBitDepth != 8 && o.InputBitDepth != 16 {
+		problems = append(problems, fmt.Sprintf("InputBitDepth %d must be 8 or 16", o.InputBitDepth))
+	}
+	if o.RowStride != 0 && o.RowStride < o.inputRowSize() {
+		problems = append(problems, fmt.Sprintf("RowStride %d is less than the row size %d", o.RowStride, o.inputRowSize()))
+	} else if o.Height > 1 && o.RowStride > (math.MaxInt-o.inputRowSize())/(o.Height-1) {
+		problems = append(problems, fmt.Sprintf("RowStride %d is too large: %d rows would overflow the buffer size", o.RowStride, o.Height))
+	}
+	if o.MaxIDATChunkSize < 0 {
+		problems = append(problems, fmt.Sprintf("MaxIDATChunkSize %d must not be negative", o.MaxIDATChunkSize))
+	}
+	if o.MaxPixels < 0 {
+		problems = append(problems, fmt.Sprintf("MaxPixels %d must not be negative", o.MaxPixels))
+	}
+	for i, c := range o.ExtraChunks {
+		if c == nil || c.IsCritical() {
+			problems = append(problems, fmt.Sprintf("ExtraChunks[%d] must be a non-nil ancillary chunk", i))
+		} else if chunksAfterPLTE[c.Type()] {
+			problems = append(problems, fmt.Sprintf("ExtraChunks[%d] is %s, which depends on the encoder's color type and palette", i, c.Type()))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return &PngError{"invalid options: " + strings.Join(problems, "; ")}
 }
 
 func FastOptions(width, height int) Options {
@@ -104,3 +315,65 @@ func LossyOptions(width, height int, maxColors int) Options {
 		Dithering:        false,
 	}
 }
+
+// CanonicalOptions returns settings whose output is byte-stable across
+// versions, for golden-file comparisons: compression level 6 with the MinSum
+// filter, no ancillary chunks, a single IDAT, and, for images with at most 256
+// colors, an exact palette sorted with SortPalette so its order does not depend
+// on where colors first appear. Changing the bytes these options produce is a
+// breaking change; TestCanonicalOptionsGolden guards it.
+func CanonicalOptions(width, height int) Options {
+	return Options{
+		Width:            width,
+		Height:           height,
+		ColorType:        ColorRGBA,
+		CompressionLevel: 6,
+		FilterStrategy:   FilterStrategyMinSum,
+		OptimizeAlpha:    true,
+		ReduceColorType:  true,
+		StripMetadata:    true,
+		OptimalDeflate:   false,
+		MaxColors:        0,
+		Dithering:        false,
+		AutoPalette:      true,
+		SortPalette:      true,
+	}
+}
+
+// AutoOptions inspects the pixels and picks settings for them: lossless color
+// reduction for gray or opaque images, an exact palette for images with at most
+// 256 colors, the adaptive filter for photographic content, and a compression
+// level that trades speed for size as the image grows.
+func AutoOptions(pixels []byte, width, height int, colorType ColorType) Options {
+	opts := BalancedOptions(width, height)
+	opts.ColorType = colorType
+
+	opaque := colorType != ColorRGBA || CanReduceToRGB(pixels, width, height)
+	gray := IsGrayscale(pixels, colorType)
+	_, manyColors := CountUniqueColorsUpTo(pixels, int(colorType), 256)
+
+	switch {
+	case gray:
+		// Gray samples are already one byte per pixel; a palette would only add a PLTE.
+		opts.ReduceColorType = opaque
+		opts.FilterStrategy = FilterStrategyAdaptive
+	case !manyColors:
+		opts.AutoPalette = true
+		opts.ReduceColorType = opaque
+		opts.FilterStrategy = FilterStrategyNone
+	default:
+		opts.ReduceColorType = opaque && colorType == ColorRGBA
+		opts.FilterStrategy = FilterStrategyAdaptive
+	}
+
+	switch pixelCount := width * height; {
+	case pixelCount <= 512*512:
+		opts.CompressionLevel = 9
+	case pixelCount <= 2048*2048:
+		opts.CompressionLevel = 6
+	default:
+		opts.CompressionLevel = 4
+	}
+
+	return opts
+}
@@ -20,6 +20,10 @@ func NewOptionsBuilder(width, height int) *OptionsBuilder {
 	}
 }
 
+// Fast applies the fast preset. Like Balanced and Max it overwrites every
+// setting it names, so apply a preset before the setters that adjust it; the
+// one exception is that a preset never turns ReduceColorType back on while
+// MaxColors selects a palette.
 func (b *OptionsBuilder) Fast() *OptionsBuilder {
 	b.opts.CompressionLevel = 2
 	b.opts.FilterStrategy = FilterStrategyAdaptiveFast
@@ -30,21 +34,25 @@ func (b *OptionsBuilder) Fast() *OptionsBuilder {
 	return b
 }
 
+// Balanced applies the balanced preset; see Fast for how presets combine with
+// other setters.
 func (b *OptionsBuilder) Balanced() *OptionsBuilder {
 	b.opts.CompressionLevel = 6
 	b.opts.FilterStrategy = FilterStrategyAdaptive
 	b.opts.OptimizeAlpha = true
-	b.opts.ReduceColorType = true
+	b.opts.ReduceColorType = b.opts.MaxColors == 0
 	b.opts.StripMetadata = true
 	b.opts.OptimalDeflate = false
 	return b
 }
 
+// Max applies the max preset; see Fast for how presets combine with other
+// setters.
 func (b *OptionsBuilder) Max() *OptionsBuilder {
 	b.opts.CompressionLevel = 9
 	b.opts.FilterStrategy = FilterStrategyMinSum
 	b.opts.OptimizeAlpha = true
-	b.opts.ReduceColorType = true
+	b.opts.ReduceColorType = b.opts.MaxColors == 0
 	b.opts.StripMetadata = true
 	b.opts.OptimalDeflate = true
 	return b
@@ -85,6 +93,39 @@ func (b *OptionsBuilder) OptimalDeflate(enabled bool) *OptionsBuilder {
 	return b
 }
 
+func (b *OptionsBuilder) ColorType(colorType ColorType) *OptionsBuilder {
+	b.opts.ColorType = colorType
+	return b
+}
+
+// BitDepth sets the output bit depth. The encoder only writes 8-bit samples,
+// so Validate and NewEncoderWithOptions reject any other depth.
+func (b *OptionsBuilder) BitDepth(depth int) *OptionsBuilder {
+	b.opts.BitDepth = depth
+	return b
+}
+
+// MaxColors sets the palette size, clamped to 0..256. A nonzero value selects
+// a palette, so it turns off ReduceColorType and AutoPalette.
+func (b *OptionsBuilder) MaxColors(maxColors int) *OptionsBuilder {
+	if maxColors < 0 {
+		maxColors = 0
+	} else if maxColors > 256 {
+		maxColors = 256
+	}
+	b.opts.MaxColors = maxColors
+	if maxColors > 0 {
+		b.opts.ReduceColorType = false
+		b.opts.AutoPalette = false
+	}
+	return b
+}
+
+func (b *OptionsBuilder) Dithering(enabled bool) *OptionsBuilder {
+	b.opts.Dithering = enabled
+	return b
+}
+
 func (b *OptionsBuilder) Build() Options {
 	return b.opts
 }
@@ -167,3 +167,130 @@ func TestOptionsBuilderPresetMethods(t *testing.T) {
 		}
 	})
 }
+
+func TestOptionsBuilderColorAndPaletteSetters(t *testing.T) {
+	opts := NewOptionsBuilder(64, 64).
+		ColorType(ColorRGB).
+		BitDepth(8).
+		ReduceColorType(false).
+		MaxColors(32).
+		Dithering(true).
+		Build()
+
+	if opts.ColorType != ColorRGB {
+		t.Errorf("expected color type RGB, got %v", opts.ColorType)
+	}
+	if opts.BitDepth != 8 {
+		t.Errorf("expected bit depth 8, got %d", opts.BitDepth)
+	}
+	if opts.MaxColors != 32 {
+		t.Errorf("expected max colors 32, got %d", opts.MaxColors)
+	}
+	if !opts.Dithering {
+		t.Error("expected Dithering to be true")
+	}
+	if err := opts.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+}
+
+func TestOptionsBuilderMaxColorsClamping(t *testing.T) {
+	tests := []struct {
+		name string
+		in   int
+		want int
+	}{
+		{"negative", -5, 0},
+		{"in range", 16, 16},
+		{"above maximum", 1000, 256},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := NewOptionsBuilder(10, 10).MaxColors(tt.in).Build()
+			if opts.MaxColors != tt.want {
+				t.Errorf("expected max colors %d, got %d", tt.want, opts.MaxColors)
+			}
+		})
+	}
+}
+
+func TestOptionsBuilderMaxColorsEncodes(t *testing.T) {
+	width, height := 16, 16
+	opts := NewOptionsBuilder(width, height).ColorType(ColorRGB).MaxColors(8).Build()
+	if opts.ReduceColorType || opts.AutoPalette {
+		t.Errorf("MaxColors(8) left ReduceColorType = %v, AutoPalette = %v; want both false", opts.ReduceColorType, opts.AutoPalette)
+	}
+
+	encoder, err := NewEncoderWithOptions(opts)
+	if err != nil {
+		t.Fatalf("NewEncoderWithOptions() error = %v", err)
+	}
+	data, err := encoder.Encode(benchPixels(width, height, 3))
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	ihdr, err := parseIHDR(findFirstChunk(t, parsePNGChunks(t, data), "IHDR").Data)
+	if err != nil {
+		t.Fatalf("parseIHDR() error = %v", err)
+	}
+	if ihdr.ColorType != ColorIndexed {
+		t.Errorf("IHDR color type = %v, want %v", ihdr.ColorType, ColorIndexed)
+	}
+}
+
+func TestOptionsBuilderPresetAfterMaxColors(t *testing.T) {
+	presets := []struct {
+		name  string
+		apply func(*OptionsBuilder) *OptionsBuilder
+		level int
+	}{
+		{"fast", (*OptionsBuilder).Fast, 2},
+		{"balanced", (*OptionsBuilder).Balanced, 6},
+		{"max", (*OptionsBuilder).Max, 9},
+	}
+
+	for _, p := range presets {
+		t.Run(p.name, func(t *testing.T) {
+			opts := p.apply(NewOptionsBuilder(10, 10).CompressionLevel(4).MaxColors(8)).Build()
+			if opts.ReduceColorType {
+				t.Error("preset after MaxColors(8) turned ReduceColorType back on")
+			}
+			if opts.MaxColors != 8 {
+				t.Errorf("expected max colors 8, got %d", opts.MaxColors)
+			}
+			// Other preset fields overwrite earlier setters
+			if opts.CompressionLevel != p.level {
+				t.Errorf("expected compression level %d, got %d", p.level, opts.CompressionLevel)
+			}
+			if err := opts.Validate(); err != nil {
+				t.Errorf("Validate() error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestOptionsBuilderBitDepthRejectsUnsupportedDepths(t *testing.T) {
+	tests := []struct {
+		name    string
+		depth   int
+		wantErr bool
+	}{
+		{"8-bit", 8, false},
+		{"16-bit", 16, true},
+		{"4-bit", 4, true},
+		{"out of range", 300, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := NewOptionsBuilder(10, 10).BitDepth(tt.depth).Build()
+			if opts.BitDepth != tt.depth {
+				t.Errorf("expected bit depth %d, got %d", tt.depth, opts.BitDepth)
+			}
+			if err := opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
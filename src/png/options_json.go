@@ -0,0 +1,66 @@
+package png
+
+import (
+	"fmt"
+	"strings"
+)
+
+var colorTypeNames = map[ColorType]string{
+	ColorGrayscale: "grayscale",
+	ColorRGB:       "rgb",
+	ColorRGBA:      "rgba",
+	ColorIndexed:   "indexed",
+}
+
+var filterStrategyNames = map[FilterStrategy]string{
+	FilterStrategyNone:         "none",
+	FilterStrategySub:          "sub",
+	FilterStrategyUp:           "up",
+	FilterStrategyAverage:      "average",
+	FilterStrategyPaeth:        "paeth",
+	FilterStrategyMinSum:       "minsum",
+	FilterStrategyAdaptive:     "adaptive",
+	FilterStrategyAdaptiveFast: "adaptivefast",
+}
+
+// MarshalText encodes the color type by name, e.g. "rgba".
+func (c ColorType) MarshalText() ([]byte, error) {
+	name, ok := colorTypeNames[c]
+	if !ok {
+		return nil, &PngError{fmt.Sprintf("unknown color type %d", c)}
+	}
+	return []byte(name), nil
+}
+
+// UnmarshalText decodes a color type name, ignoring case.
+func (c *ColorType) UnmarshalText(text []byte) error {
+	name := strings.ToLower(string(text))
+	for value, n := range colorTypeNames {
+		if n == name {
+			*c = value
+			return nil
+		}
+	}
+	return &PngError{fmt.Sprintf("unknown color type %q", text)}
+}
+
+// MarshalText encodes the filter strategy by name, e.g. "minsum".
+func (f FilterStrategy) MarshalText() ([]byte, error) {
+	name, ok := filterStrategyNames[f]
+	if !ok {
+		return nil, &PngError{fmt.Sprintf("unknown filter strategy %d", f)}
+	}
+	return []byte(name), nil
+}
+
+// UnmarshalText decodes a filter strategy name, ignoring case.
+func (f *FilterStrategy) UnmarshalText(text []byte) error {
+	name := strings.ToLower(string(text))
+	for value, n := range filterStrategyNames {
+		if n == name {
+			*f = value
+			return nil
+		}
+	}
+	return &PngError{fmt.Sprintf("unknown filter strategy %q", text)}
+}
@@ -0,0 +1,74 @@
+package png
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOptionsJSONRoundTrip(t *testing.T) {
+	opts := NewOptionsBuilder(64, 32).
+		Max().
+		FilterStrategy(FilterStrategyPaeth).
+		Build()
+	opts.ColorType = ColorRGB
+	opts.AutoPalette = true
+
+	data, err := json.Marshal(opts)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	for _, want := range []string{`"colorType":"rgb"`, `"filterStrategy":"paeth"`, `"compressionLevel":9`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("json.Marshal() = %s, want it to contain %s", data, want)
+		}
+	}
+
+	var got Options
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, opts) {
+		t.Errorf("round trip = %+v, want %+v", got, opts)
+	}
+}
+
+func TestOptionsJSONConfigOverPreset(t *testing.T) {
+	opts := BalancedOptions(10, 10)
+	config := `{"compressionLevel":9,"filterStrategy":"MinSum"}`
+
+	if err := json.Unmarshal([]byte(config), &opts); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	want := BalancedOptions(10, 10)
+	want.CompressionLevel = 9
+	want.FilterStrategy = FilterStrategyMinSum
+	if !reflect.DeepEqual(opts, want) {
+		t.Errorf("json.Unmarshal() = %+v, want %+v", opts, want)
+	}
+}
+
+func TestOptionsJSONUnknownNames(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{"unknown filter strategy", `{"filterStrategy":"zigzag"}`},
+		{"unknown color type", `{"colorType":"cmyk"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts Options
+			if err := json.Unmarshal([]byte(tt.config), &opts); err == nil {
+				t.Errorf("json.Unmarshal(%s) error = nil, want error", tt.config)
+			}
+		})
+	}
+
+	if _, err := json.Marshal(Options{FilterStrategy: 42}); err == nil {
+		t.Error("json.Marshal() with unknown FilterStrategy error = nil, want error")
+	}
+}
@@ -0,0 +1,184 @@
+package png
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(o *Options)
+		wantErr []string
+	}{
+		{"balanced preset", func(o *Options) {}, nil},
+		{"lossy preset", func(o *Options) { *o = LossyOptions(8, 8, 16) }, nil},
+		{"zero width", func(o *Options) { o.Width = 0 }, []string{"dimensions 0x8"}},
+		{"indexed input", func(o *Options) { o.ColorType = ColorIndexed }, []string{"not an input format"}},
+		{"unknown color type", func(o *Options) { o.ColorType = 99 }, []string{"unsupported ColorType 99"}},
+		{"compression level too high", func(o *Options) { o.CompressionLevel = 12 }, []string{"CompressionLevel 12"}},
+		{"unknown filter strategy", func(o *Options) { o.FilterStrategy = 42 }, []string{"unknown FilterStrategy 42"}},
+		{"max colors too high", func(o *Options) { o.MaxColors = 300; o.ReduceColorType = false }, []string{"MaxColors 300"}},
+		{"quantize with color reduction", func(o *Options) { o.MaxColors = 16 }, []string{"ReduceColorType has no effect"}},
+		{"quantize with auto palette", func(o *Options) {
+			o.MaxColors = 16
+			o.ReduceColorType = false
+			o.AutoPalette = true
+		}, []string{"MaxColors and AutoPalette"}},
+		{"dithering without quantize", func(o *Options) { o.Dithering = true }, []string{"Dithering requires MaxColors"}},
+		{"explicit 8-bit depth", func(o *Options) { o.BitDepth = 8 }, nil},
+		{"bit depth invalid for color type", func(o *Options) { o.BitDepth = 4 }, []string{"BitDepth 4 is not valid for ColorType RGBA"}},
+		{"16-bit depth unsupported", func(o *Options) { o.BitDepth = 16 }, []string{"BitDepth 16 is not supported"}},
+		{"posterize out of range", func(o *Options) { o.Posterize = 8 }, []string{"Posterize 8 out of range"}},
+		{"negative blur radius", func(o *Options) { o.PreBlurRadius = -1 }, []string{"PreBlurRadius -1"}},
+		{"negative sharpen", func(o *Options) { o.Sharpen = -0.5 }, []string{"Sharpen -0.5"}},
+		{"row stride shorter than a row", func(o *Options) { o.RowStride = 1 }, []string{"RowStride 1 is less than the row size"}},
+		{"row stride with padding", func(o *Options) { o.RowStride = o.Width*4 + 8 }, nil},
+		{"row stride overflows", func(o *Options) { o.RowStride = math.MaxInt / 2 }, []string{"is too large"}},
+		{"BGRA input with grayscale", func(o *Options) {
+			o.ColorType = ColorGrayscale
+			o.InputIsBGRA = true
+		}, []string{"InputIsBGRA requires ColorType RGB or RGBA"}},
+		{"unsupported input bit depth", func(o *Options) { o.InputBitDepth = 12 }, []string{"InputBitDepth 12 must be 8 or 16"}},
+		{"row stride too short for 16-bit input", func(o *Options) {
+			o.InputBitDepth = 16
+			o.RowStride = o.Width * 4
+		}, []string{"RowStride 32 is less than the row size 64"}},
+		{"negative IDAT chunk size", func(o *Options) { o.MaxIDATChunkSize = -1 }, []string{"MaxIDATChunkSize -1 must not be negative"}},
+		{"extra chunk that follows PLTE", func(o *Options) {
+			o.ExtraChunks = []*Chunk{{chunkType: "bKGD", Data: []byte{0, 0, 0, 0, 0, 0}}}
+		}, []string{"ExtraChunks[0] is bKGD"}},
+		{"several conflicts reported together", func(o *Options) {
+			o.ColorType = ColorIndexed
+			o.MaxColors = 16
+			o.Dithering = true
+		}, []string{"not an input format", "ReduceColorType has no effect"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := BalancedOptions(8, 8)
+			tt.modify(&opts)
+
+			err := opts.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() error = nil, want %q", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() error = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewEncoderWithOptionsValidates(t *testing.T) {
+	opts := BalancedOptions(8, 8)
+	opts.Dithering = true
+
+	if _, err := NewEncoderWithOptions(opts); err == nil {
+		t.Error("NewEncoderWithOptions() error = nil, want validation error")
+	}
+}
+
+func TestFilterStrategyString(t *testing.T) {
+	tests := []struct {
+		strategy FilterStrategy
+		want     string
+	}{
+		{FilterStrategyNone, "None"},
+		{FilterStrategySub, "Sub"},
+		{FilterStrategyUp, "Up"},
+		{FilterStrategyAverage, "Average"},
+		{FilterStrategyPaeth, "Paeth"},
+		{FilterStrategyMinSum, "MinSum"},
+		{FilterStrategyAdaptive, "Adaptive"},
+		{FilterStrategyAdaptiveFast, "AdaptiveFast"},
+		{FilterStrategy(-1), "Unknown(-1)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.strategy.String(); got != tt.want {
+				t.Errorf("FilterStrategy(%d).String() = %q, want %q", int(tt.strategy), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoOptions(t *testing.T) {
+	const width, height = 32, 32
+
+	gray := make([]byte, width*height*3)
+	for i := 0; i < width*height; i++ {
+		v := byte(i)
+		gray[i*3], gray[i*3+1], gray[i*3+2] = v, v, v
+	}
+
+	logo := make([]byte, width*height*4)
+	for i := 0; i < width*height; i++ {
+		c := byte(i % 20)
+		logo[i*4], logo[i*4+1], logo[i*4+2], logo[i*4+3] = c*12, 255-c*12, c*7, 255
+	}
+
+	photo := make([]byte, width*height*3)
+	rng := rand.New(rand.NewSource(1))
+	rng.Read(photo)
+
+	tests := []struct {
+		name        string
+		pixels      []byte
+		colorType   ColorType
+		wantReduce  bool
+		wantPalette bool
+		wantFilter  FilterStrategy
+	}{
+		{"grayscale image", gray, ColorRGB, true, false, FilterStrategyAdaptive},
+		{"20-color logo", logo, ColorRGBA, true, true, FilterStrategyNone},
+		{"noisy photo", photo, ColorRGB, false, false, FilterStrategyAdaptive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := AutoOptions(tt.pixels, width, height, tt.colorType)
+
+			if opts.ReduceColorType != tt.wantReduce {
+				t.Errorf("AutoOptions() ReduceColorType = %v, want %v", opts.ReduceColorType, tt.wantReduce)
+			}
+			if opts.AutoPalette != tt.wantPalette {
+				t.Errorf("AutoOptions() AutoPalette = %v, want %v", opts.AutoPalette, tt.wantPalette)
+			}
+			if opts.FilterStrategy != tt.wantFilter {
+				t.Errorf("AutoOptions() FilterStrategy = %v, want %v", opts.FilterStrategy, tt.wantFilter)
+			}
+			if opts.CompressionLevel != 9 {
+				t.Errorf("AutoOptions() CompressionLevel = %v, want 9 for a small image", opts.CompressionLevel)
+			}
+			if err := opts.Validate(); err != nil {
+				t.Errorf("AutoOptions() Validate() = %v", err)
+			}
+
+			if _, err := EncodeWithOptions(tt.pixels, opts); err != nil {
+				t.Errorf("EncodeWithOptions() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestAutoOptionsKeepsTransparency(t *testing.T) {
+	pixels := []byte{10, 10, 10, 0, 200, 200, 200, 255}
+	opts := AutoOptions(pixels, 2, 1, ColorRGBA)
+
+	if opts.ReduceColorType {
+		t.Error("AutoOptions() ReduceColorType = true for a transparent image, want false")
+	}
+}
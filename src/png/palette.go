@@ -13,9 +13,23 @@ type ColorWithCount struct {
 	Count int
 }
 
+// AlphaColor represents an RGB color with an alpha channel.
+type AlphaColor struct {
+	Color
+	A uint8
+}
+
+// AlphaColorWithCount extends AlphaColor with frequency information.
+type AlphaColorWithCount struct {
+	AlphaColor
+	Count int
+}
+
 // Palette represents an indexed color palette.
+// Alpha is optional; when non-nil it holds one alpha value per palette entry.
 type Palette struct {
 	Colors    []Color
+	Alpha     []uint8
 	NumColors int
 }
 
@@ -27,6 +41,18 @@ func NewPalette(maxColors int) *Palette {
 	}
 }
 
+// Len returns the number of usable palette entries. It never exceeds the
+// capacity of Colors, even if NumColors has been set past it.
+func (p *Palette) Len() int {
+	if p.NumColors > len(p.Colors) {
+		return len(p.Colors)
+	}
+	if p.NumColors < 0 {
+		return 0
+	}
+	return p.NumColors
+}
+
 // AddColor adds a color to the palette and returns its index.
 // If the palette is full, it returns -1.
 func (p *Palette) AddColor(c Color) int {
@@ -38,17 +64,35 @@ func (p *Palette) AddColor(c Color) int {
 	return p.NumColors - 1
 }
 
+// AddColorWithAlpha adds a color with an alpha value to the palette and returns its index.
+// Entries added without alpha are treated as fully opaque.
+// If the palette is full, it returns -1.
+func (p *Palette) AddColorWithAlpha(c Color, a uint8) int {
+	if p.NumColors >= len(p.Colors) {
+		return -1
+	}
+	if p.Alpha == nil {
+		p.Alpha = make([]uint8, len(p.Colors))
+		for i := range p.Alpha {
+			p.Alpha[i] = 255
+		}
+	}
+	p.Alpha[p.NumColors] = a
+	return p.AddColor(c)
+}
+
 // FindNearest finds the index of the nearest color in the palette to the given color.
 // Uses Euclidean distance in RGB space.
 func (p *Palette) FindNearest(c Color) int {
-	if p.NumColors == 0 {
+	n := p.Len()
+	if n == 0 {
 		return 0
 	}
 
 	bestIdx := 0
 	bestDist := uint64(math.MaxUint64)
 
-	for i := 0; i < p.NumColors; i++ {
+	for i := 0; i < n; i++ {
 		dr := int64(c.R) - int64(p.Colors[i].R)
 		dg := int64(c.G) - int64(p.Colors[i].G)
 		db := int64(c.B) - int64(p.Colors[i].B)
@@ -63,27 +107,24 @@ func (p *Palette) FindNearest(c Color) int {
 	return bestIdx
 }
 
-// FindNearestWithAlpha finds the nearest color considering alpha if palette has it.
+// FindNearestWithAlpha finds the nearest color considering alpha.
+// Uses Euclidean distance in RGBA space; entries without alpha are treated as opaque.
 func (p *Palette) FindNearestWithAlpha(c Color, alpha uint8) int {
-	if p.NumColors == 0 {
+	n := p.Len()
+	if n == 0 {
 		return 0
 	}
 
 	bestIdx := 0
 	bestDist := uint64(math.MaxUint64)
 
-	for i := 0; i < p.NumColors; i++ {
-		paletteAlpha := p.Colors[i].R
-
-		if alpha != paletteAlpha {
-			continue
-		}
-
-		dr := int64(c.R) - int64(p.Colors[i].G)
-		dg := int64(c.G) - int64(p.Colors[i].B)
-		db := int64(c.B) - int64(p.Colors[i].R)
+	for i := 0; i < n; i++ {
+		dr := int64(c.R) - int64(p.Colors[i].R)
+		dg := int64(c.G) - int64(p.Colors[i].G)
+		db := int64(c.B) - int64(p.Colors[i].B)
+		da := int64(alpha) - int64(p.GetAlpha(i))
 
-		dist := uint64(dr*dr + dg*dg + db*db)
+		dist := uint64(dr*dr + dg*dg + db*db + da*da)
 		if dist < bestDist {
 			bestDist = dist
 			bestIdx = i
@@ -93,14 +134,27 @@ func (p *Palette) FindNearestWithAlpha(c Color, alpha uint8) int {
 	return bestIdx
 }
 
-// HasAlpha returns true if the palette has colors with alpha information.
+// HasAlpha returns true if any palette entry is not fully opaque.
 func (p *Palette) HasAlpha() bool {
+	for i := 0; i < p.NumColors && i < len(p.Alpha); i++ {
+		if p.Alpha[i] != 255 {
+			return true
+		}
+	}
 	return false
 }
 
+// GetAlpha returns the alpha value at the specified index (255 if the palette has no alpha).
+func (p *Palette) GetAlpha(idx int) uint8 {
+	if idx >= 0 && idx < p.NumColors && idx < len(p.Alpha) {
+		return p.Alpha[idx]
+	}
+	return 255
+}
+
 // GetColor returns the color at the specified index.
 func (p *Palette) GetColor(idx int) Color {
-	if idx >= 0 && idx < p.NumColors {
+	if idx >= 0 && idx < p.Len() {
 		return p.Colors[idx]
 	}
 	return Color{}
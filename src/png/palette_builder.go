@@ -0,0 +1,98 @@
+package png
+
+// paletteBuilderBits is the number of bits kept per channel when bucketing colors,
+// which bounds the histogram at 2^(3*paletteBuilderBits) entries.
+const paletteBuilderBits = 5
+
+// PaletteBuilder accumulates a bounded color histogram over many calls so a palette
+// can be derived in one pass over a large image, then applied in a second.
+// Colors are bucketed by their top 5 bits per channel (at most 32768 buckets);
+// each bucket remembers its pixel count and the mean of the colors that fell in it.
+type PaletteBuilder struct {
+	colorType int
+	buckets   map[uint16]*paletteBucket
+	partial   []byte // trailing bytes of an incomplete pixel from the last AddPixels
+}
+
+type paletteBucket struct {
+	count            int
+	sumR, sumG, sumB int64
+}
+
+// NewPaletteBuilder creates a PaletteBuilder for pixels of colorType
+// (0 grayscale, 2 RGB or 6 RGBA; alpha is ignored).
+func NewPaletteBuilder(colorType int) *PaletteBuilder {
+	return &PaletteBuilder{
+		colorType: colorType,
+		buckets:   make(map[uint16]*paletteBucket),
+	}
+}
+
+// AddPixels adds pixels to the histogram. Chunks need not end on a pixel
+// boundary; leftover bytes are carried over to the next call.
+func (b *PaletteBuilder) AddPixels(pixels []byte) {
+	bpp := BytesPerPixel(ColorType(b.colorType))
+
+	if len(b.partial) > 0 {
+		need := bpp - len(b.partial)
+		if len(pixels) < need {
+			b.partial = append(b.partial, pixels...)
+			return
+		}
+		b.addPixel(alphaColorAt(append(b.partial, pixels[:need]...), 0, bpp).Color)
+		pixels = pixels[need:]
+		b.partial = b.partial[:0]
+	}
+
+	n := len(pixels) / bpp
+	for i := 0; i < n; i++ {
+		b.addPixel(alphaColorAt(pixels, i*bpp, bpp).Color)
+	}
+	b.partial = append(b.partial, pixels[n*bpp:]...)
+}
+
+func (b *PaletteBuilder) addPixel(c Color) {
+	const shift = 8 - paletteBuilderBits
+	key := uint16(c.R>>shift)<<(2*paletteBuilderBits) | uint16(c.G>>shift)<<paletteBuilderBits | uint16(c.B>>shift)
+
+	bucket := b.buckets[key]
+	if bucket == nil {
+		bucket = &paletteBucket{}
+		b.buckets[key] = bucket
+	}
+	bucket.count++
+	bucket.sumR += int64(c.R)
+	bucket.sumG += int64(c.G)
+	bucket.sumB += int64(c.B)
+}
+
+// Buckets returns the number of non-empty histogram buckets.
+func (b *PaletteBuilder) Buckets() int {
+	return len(b.buckets)
+}
+
+// Build runs median cut over the accumulated buckets and returns up to maxColors
+// colors (maxColors outside 1-256 means 256). It can be called more than once.
+func (b *PaletteBuilder) Build(maxColors int) Palette {
+	if maxColors <= 0 || maxColors > 256 {
+		maxColors = 256
+	}
+
+	colorMap := make(map[Color]int, len(b.buckets))
+	for _, bucket := range b.buckets {
+		n := int64(bucket.count)
+		c := Color{
+			R: uint8(bucket.sumR / n),
+			G: uint8(bucket.sumG / n),
+			B: uint8(bucket.sumB / n),
+		}
+		colorMap[c] += bucket.count
+	}
+
+	paletteColors := MedianCut(ToColorWithCountSlice(colorMap), maxColors)
+	palette := NewPalette(len(paletteColors))
+	for _, c := range paletteColors {
+		palette.AddColor(c)
+	}
+	return *palette
+}
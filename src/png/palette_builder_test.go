@@ -0,0 +1,73 @@
+package png
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func paletteBuilderTestImage() []byte {
+	width, height := 64, 64
+	rng := rand.New(rand.NewSource(5))
+	pixels := make([]byte, width*height*3)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := (y*width + x) * 3
+			pixels[i] = uint8(x*4) + uint8(rng.Intn(6))
+			pixels[i+1] = uint8(y*4) + uint8(rng.Intn(6))
+			pixels[i+2] = uint8((x + y) * 2)
+		}
+	}
+	return pixels
+}
+
+func TestPaletteBuilderChunked(t *testing.T) {
+	pixels := paletteBuilderTestImage()
+
+	whole := NewPaletteBuilder(2)
+	whole.AddPixels(pixels)
+
+	chunked := NewPaletteBuilder(2)
+	for start := 0; start < len(pixels); start += 1000 { // 1000 is not a multiple of 3
+		end := start + 1000
+		if end > len(pixels) {
+			end = len(pixels)
+		}
+		chunked.AddPixels(pixels[start:end])
+	}
+
+	if whole.Buckets() > 1<<15 {
+		t.Errorf("Buckets() = %d, want <= %d", whole.Buckets(), 1<<15)
+	}
+	if got, want := chunked.Build(16), whole.Build(16); !reflect.DeepEqual(got, want) {
+		t.Errorf("chunked Build() = %v, want %v", got, want)
+	}
+}
+
+func TestPaletteBuilderCloseToExtractPalette(t *testing.T) {
+	pixels := paletteBuilderTestImage()
+
+	builder := NewPaletteBuilder(2)
+	for start := 0; start < len(pixels); start += 3 * 256 {
+		builder.AddPixels(pixels[start : start+3*256])
+	}
+	built := builder.Build(16)
+	exact := ExtractPalette(pixels, 2, 16)
+
+	if built.NumColors != exact.NumColors {
+		t.Fatalf("Build() NumColors = %d, want %d", built.NumColors, exact.NumColors)
+	}
+
+	_, builtMSE := QuantizationError(pixels, 2, built)
+	_, exactMSE := QuantizationError(pixels, 2, exact)
+	t.Logf("bucketed MSE %.1f, exact MSE %.1f", builtMSE, exactMSE)
+	if builtMSE > exactMSE*1.25+4 {
+		t.Errorf("bucketed palette MSE %.1f is not close to all-at-once MSE %.1f", builtMSE, exactMSE)
+	}
+}
+
+func TestPaletteBuilderEmpty(t *testing.T) {
+	if palette := NewPaletteBuilder(6).Build(8); palette.NumColors != 0 {
+		t.Errorf("Build() on empty builder NumColors = %d, want 0", palette.NumColors)
+	}
+}
@@ -0,0 +1,43 @@
+package png
+
+import "image/color"
+
+// ToColorPalette converts p to an image/color palette, e.g. for image.Paletted.
+// Opaque entries become color.RGBA; entries with alpha below 255 become
+// color.NRGBA, since color.RGBA holds premultiplied values.
+func (p *Palette) ToColorPalette() color.Palette {
+	n := p.Len()
+	result := make(color.Palette, n)
+	for i := 0; i < n; i++ {
+		c := p.Colors[i]
+		if a := p.GetAlpha(i); a != 255 {
+			result[i] = color.NRGBA{R: c.R, G: c.G, B: c.B, A: a}
+		} else {
+			result[i] = color.RGBA{R: c.R, G: c.G, B: c.B, A: 255}
+		}
+	}
+	return result
+}
+
+// PaletteFromColorPalette converts an image/color palette to a Palette,
+// un-premultiplying each entry. Alpha is only set when some entry is not opaque.
+func PaletteFromColorPalette(cp color.Palette) Palette {
+	palette := NewPalette(len(cp))
+	colors := make([]color.NRGBA, len(cp))
+	opaque := true
+	for i, c := range cp {
+		colors[i] = color.NRGBAModel.Convert(c).(color.NRGBA)
+		if colors[i].A != 255 {
+			opaque = false
+		}
+	}
+
+	for _, c := range colors {
+		if opaque {
+			palette.AddColor(Color{c.R, c.G, c.B})
+		} else {
+			palette.AddColorWithAlpha(Color{c.R, c.G, c.B}, c.A)
+		}
+	}
+	return *palette
+}
@@ -0,0 +1,62 @@
+package png
+
+import (
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestPaletteToColorPalette(t *testing.T) {
+	palette := NewPalette(3)
+	palette.AddColorWithAlpha(Color{255, 0, 0}, 255)
+	palette.AddColorWithAlpha(Color{0, 200, 100}, 128)
+	palette.AddColorWithAlpha(Color{1, 2, 3}, 0)
+
+	got := palette.ToColorPalette()
+	want := color.Palette{
+		color.RGBA{255, 0, 0, 255},
+		color.NRGBA{0, 200, 100, 128},
+		color.NRGBA{1, 2, 3, 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ToColorPalette() = %v, want %v", got, want)
+	}
+
+	back := PaletteFromColorPalette(got)
+	if back.Len() != 3 {
+		t.Fatalf("PaletteFromColorPalette() has %d colors, want 3", back.Len())
+	}
+	for i := 0; i < 3; i++ {
+		if back.GetColor(i) != palette.GetColor(i) || back.GetAlpha(i) != palette.GetAlpha(i) {
+			t.Errorf("entry %d = %v/%d, want %v/%d", i, back.GetColor(i), back.GetAlpha(i), palette.GetColor(i), palette.GetAlpha(i))
+		}
+	}
+}
+
+func TestPaletteFromColorPalette(t *testing.T) {
+	tests := []struct {
+		name      string
+		cp        color.Palette
+		want      []Color
+		wantAlpha []uint8
+	}{
+		{"opaque", color.Palette{color.Black, color.RGBA{10, 20, 30, 255}}, []Color{{0, 0, 0}, {10, 20, 30}}, nil},
+		{"gray", color.Palette{color.Gray{Y: 77}}, []Color{{77, 77, 77}}, nil},
+		{"premultiplied", color.Palette{color.RGBA{50, 0, 0, 128}, color.Transparent}, []Color{{99, 0, 0}, {0, 0, 0}}, []uint8{128, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PaletteFromColorPalette(tt.cp)
+			if !reflect.DeepEqual(got.Colors[:got.Len()], tt.want) {
+				t.Errorf("PaletteFromColorPalette() colors = %v, want %v", got.Colors[:got.Len()], tt.want)
+			}
+			if tt.wantAlpha == nil && got.Alpha != nil {
+				t.Errorf("PaletteFromColorPalette() alpha = %v, want nil", got.Alpha)
+			}
+			if tt.wantAlpha != nil && !reflect.DeepEqual(got.Alpha, tt.wantAlpha) {
+				t.Errorf("PaletteFromColorPalette() alpha = %v, want %v", got.Alpha, tt.wantAlpha)
+			}
+		})
+	}
+}
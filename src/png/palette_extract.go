@@ -0,0 +1,63 @@
+package png
+
+import "sort"
+
+// ExtractPalette returns up to maxColors representative colors of an image using
+// median cut, without encoding anything. colorType is 0 (grayscale), 2 (RGB) or
+// 6 (RGBA; alpha is ignored). maxColors outside 1-256 means 256.
+func ExtractPalette(pixels []byte, colorType int, maxColors int) Palette {
+	if maxColors <= 0 || maxColors > 256 {
+		maxColors = 256
+	}
+
+	colorsWithCount := ToColorWithCountSlice(countPaletteColors(pixels, colorType))
+	paletteColors := MedianCut(colorsWithCount, maxColors)
+
+	palette := NewPalette(len(paletteColors))
+	for _, c := range paletteColors {
+		palette.AddColor(c)
+	}
+	return *palette
+}
+
+// ExtractPaletteSorted is like ExtractPalette but orders the colors by how many
+// pixels map to each, most common first. Ties are ordered by R, G, then B.
+func ExtractPaletteSorted(pixels []byte, colorType int, maxColors int) Palette {
+	palette := ExtractPalette(pixels, colorType, maxColors)
+
+	bpp := BytesPerPixel(ColorType(colorType))
+	counts := make([]int, palette.NumColors)
+	for i := 0; i+bpp <= len(pixels); i += bpp {
+		counts[palette.FindNearest(alphaColorAt(pixels, i, bpp).Color)]++
+	}
+
+	entries := make([]ColorWithCount, palette.NumColors)
+	for i := range entries {
+		entries[i] = ColorWithCount{Color: palette.Colors[i], Count: counts[i]}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return colorLess(entries[i].Color, entries[j].Color)
+	})
+
+	for i, e := range entries {
+		palette.Colors[i] = e.Color
+	}
+	return palette
+}
+
+// countPaletteColors counts colors like CountColors, also accepting grayscale.
+func countPaletteColors(pixels []byte, colorType int) map[Color]int {
+	bpp := BytesPerPixel(ColorType(colorType))
+	if bpp >= 3 {
+		return CountColors(pixels, colorType)
+	}
+
+	colorMap := make(map[Color]int)
+	for _, v := range pixels {
+		colorMap[Color{R: v, G: v, B: v}]++
+	}
+	return colorMap
+}
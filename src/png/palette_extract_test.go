@@ -0,0 +1,83 @@
+package png
+
+import "testing"
+
+func TestExtractPalette(t *testing.T) {
+	// 2x2 RGB image: red, green, blue, yellow
+	pixels := []byte{
+		255, 0, 0, // red
+		0, 255, 0, // green
+		0, 0, 255, // blue
+		255, 255, 0, // yellow
+	}
+
+	palette := ExtractPalette(pixels, 2, 4)
+	if palette.NumColors != 4 {
+		t.Fatalf("ExtractPalette() NumColors = %v, want 4", palette.NumColors)
+	}
+
+	want := map[Color]bool{
+		{255, 0, 0}:   true,
+		{0, 255, 0}:   true,
+		{0, 0, 255}:   true,
+		{255, 255, 0}: true,
+	}
+	for i := 0; i < palette.NumColors; i++ {
+		if !want[palette.Colors[i]] {
+			t.Errorf("ExtractPalette() color %d = %v, not in source image", i, palette.Colors[i])
+		}
+		delete(want, palette.Colors[i])
+	}
+	if len(want) != 0 {
+		t.Errorf("ExtractPalette() missing colors %v", want)
+	}
+}
+
+func TestExtractPaletteReducesColors(t *testing.T) {
+	pixels := []byte{
+		255, 0, 0, 250, 0, 0,
+		0, 0, 255, 0, 0, 250,
+	}
+
+	palette := ExtractPalette(pixels, 2, 2)
+	if palette.NumColors != 2 {
+		t.Errorf("ExtractPalette() NumColors = %v, want 2", palette.NumColors)
+	}
+}
+
+func TestExtractPaletteSorted(t *testing.T) {
+	// RGBA: three blue pixels, two red, one green, one yellow
+	pixels := []byte{
+		0, 0, 255, 255, 255, 0, 0, 255, 0, 0, 255, 255, 0, 255, 0, 255,
+		255, 255, 0, 255, 0, 0, 255, 255, 255, 0, 0, 255,
+	}
+
+	palette := ExtractPaletteSorted(pixels, 6, 4)
+	want := []Color{{0, 0, 255}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}}
+	if palette.NumColors != len(want) {
+		t.Fatalf("ExtractPaletteSorted() NumColors = %v, want %v", palette.NumColors, len(want))
+	}
+	for i, c := range want {
+		if palette.Colors[i] != c {
+			t.Errorf("ExtractPaletteSorted() color %d = %v, want %v", i, palette.Colors[i], c)
+		}
+	}
+}
+
+func TestExtractPaletteGrayscale(t *testing.T) {
+	pixels := []byte{0, 0, 128, 255}
+
+	palette := ExtractPaletteSorted(pixels, 0, 256)
+	if palette.NumColors != 3 {
+		t.Fatalf("ExtractPaletteSorted() NumColors = %v, want 3", palette.NumColors)
+	}
+	if palette.Colors[0] != (Color{0, 0, 0}) {
+		t.Errorf("ExtractPaletteSorted() first color = %v, want black", palette.Colors[0])
+	}
+}
+
+func TestExtractPaletteEmpty(t *testing.T) {
+	if palette := ExtractPalette(nil, 2, 4); palette.NumColors != 0 {
+		t.Errorf("ExtractPalette(nil) NumColors = %v, want 0", palette.NumColors)
+	}
+}
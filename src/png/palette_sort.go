@@ -0,0 +1,56 @@
+package png
+
+import "sort"
+
+// SortForCompression reorders the palette along a luminance curve and returns
+// indexed remapped to the new order. Transparent entries come first, by
+// ascending alpha, then entries of equal alpha by Rec.601 luma, so neighboring
+// pixels of similar color get neighboring indices and filter residuals stay
+// small. Ties keep their original order. Indices past the end of the palette
+// are copied unchanged. The palette is modified in place; indexed is not.
+func (p *Palette) SortForCompression(indexed []byte) []byte {
+	n := p.Len()
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ia, ib := order[a], order[b]
+		if aa, ab := p.GetAlpha(ia), p.GetAlpha(ib); aa != ab {
+			return aa < ab
+		}
+		return paletteLuma(p.Colors[ia]) < paletteLuma(p.Colors[ib])
+	})
+
+	var remap [256]byte
+	for i := range remap {
+		remap[i] = byte(i)
+	}
+	colors := make([]Color, n)
+	var alpha []uint8
+	if p.Alpha != nil {
+		alpha = make([]uint8, n)
+	}
+	for newIdx, oldIdx := range order {
+		remap[oldIdx] = byte(newIdx)
+		colors[newIdx] = p.Colors[oldIdx]
+		if alpha != nil {
+			alpha[newIdx] = p.GetAlpha(oldIdx)
+		}
+	}
+	copy(p.Colors, colors)
+	if alpha != nil {
+		copy(p.Alpha, alpha)
+	}
+
+	result := make([]byte, len(indexed))
+	for i, idx := range indexed {
+		result[i] = remap[idx]
+	}
+	return result
+}
+
+// paletteLuma returns the Rec.601 luma of c scaled by 1000.
+func paletteLuma(c Color) int {
+	return 299*int(c.R) + 587*int(c.G) + 114*int(c.B)
+}
@@ -0,0 +1,85 @@
+package png
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestPaletteSortForCompression(t *testing.T) {
+	palette := NewPalette(4)
+	palette.AddColorWithAlpha(Color{200, 200, 200}, 255)
+	palette.AddColorWithAlpha(Color{10, 10, 10}, 255)
+	palette.AddColorWithAlpha(Color{90, 90, 90}, 0)
+	palette.AddColorWithAlpha(Color{100, 100, 100}, 255)
+
+	indexed := []byte{0, 1, 2, 3, 3, 7}
+	got := palette.SortForCompression(indexed)
+	if want := []byte{3, 1, 0, 2, 2, 7}; !bytes.Equal(got, want) {
+		t.Errorf("SortForCompression() = %v, want %v", got, want)
+	}
+	if want := []byte{0, 1, 2, 3, 3, 7}; !bytes.Equal(indexed, want) {
+		t.Errorf("SortForCompression() modified its input: %v", indexed)
+	}
+
+	wantColors := []Color{{90, 90, 90}, {10, 10, 10}, {100, 100, 100}, {200, 200, 200}}
+	wantAlpha := []uint8{0, 255, 255, 255}
+	for i := range wantColors {
+		if palette.GetColor(i) != wantColors[i] || palette.GetAlpha(i) != wantAlpha[i] {
+			t.Errorf("entry %d = %v/%d, want %v/%d", i, palette.GetColor(i), palette.GetAlpha(i), wantColors[i], wantAlpha[i])
+		}
+	}
+}
+
+func TestEncodeIndexedSortPalette(t *testing.T) {
+	width, height := 64, 64
+	palette := NewPalette(64)
+	for _, v := range rand.New(rand.NewSource(7)).Perm(64) {
+		palette.AddColor(Color{uint8(v * 4), uint8(v * 4), uint8(v * 4)})
+	}
+	// A horizontal gray ramp whose indices are scattered by the shuffled palette.
+	lookup := make(map[Color]byte)
+	for i := 0; i < palette.Len(); i++ {
+		lookup[palette.GetColor(i)] = byte(i)
+	}
+	indexed := make([]byte, width*height)
+	want := make([]byte, 0, width*height*3)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := Color{uint8(x * 4), uint8(x * 4), uint8(x * 4)}
+			indexed[y*width+x] = lookup[c]
+			want = append(want, c.R, c.G, c.B)
+		}
+	}
+
+	original := append([]Color(nil), palette.Colors...)
+	encode := func(sort bool) []byte {
+		t.Helper()
+		opts := BalancedOptions(width, height)
+		opts.SortPalette = sort
+		data, err := EncodeIndexed(indexed, width, height, *palette, opts)
+		if err != nil {
+			t.Fatalf("EncodeIndexed() error = %v", err)
+		}
+		return findFirstChunk(t, parsePNGChunks(t, data), "IDAT").Data
+	}
+
+	unsorted := encode(false)
+	sorted := encode(true)
+	if len(sorted) > len(unsorted) {
+		t.Errorf("sorted IDAT = %d bytes, want <= unsorted %d", len(sorted), len(unsorted))
+	}
+	if !reflect.DeepEqual(palette.Colors, original) {
+		t.Error("EncodeIndexed() with SortPalette reordered the caller's palette")
+	}
+
+	opts := BalancedOptions(width, height)
+	opts.SortPalette = true
+	data, err := EncodeIndexed(indexed, width, height, *palette, opts)
+	if err != nil {
+		t.Fatalf("EncodeIndexed() error = %v", err)
+	}
+	assertDecodedPixels(t, data, width, height, ColorRGB, want)
+	t.Logf("IDAT: unsorted %d bytes, sorted %d bytes", len(unsorted), len(sorted))
+}
@@ -123,3 +123,65 @@ func TestPaletteGetColor(t *testing.T) {
 		})
 	}
 }
+
+func TestPaletteAddColorWithAlpha(t *testing.T) {
+	palette := NewPalette(3)
+	palette.AddColor(Color{255, 0, 0})
+	palette.AddColorWithAlpha(Color{0, 255, 0}, 128)
+
+	if palette.GetAlpha(0) != 255 {
+		t.Errorf("GetAlpha(0) = %v, want 255", palette.GetAlpha(0))
+	}
+	if palette.GetAlpha(1) != 128 {
+		t.Errorf("GetAlpha(1) = %v, want 128", palette.GetAlpha(1))
+	}
+	if !palette.HasAlpha() {
+		t.Errorf("HasAlpha() = false, want true")
+	}
+	if idx := palette.FindNearestWithAlpha(Color{0, 250, 0}, 120); idx != 1 {
+		t.Errorf("FindNearestWithAlpha() = %v, want 1", idx)
+	}
+}
+
+func TestPaletteAddColorPastCapacity(t *testing.T) {
+	p := NewPalette(2)
+	p.AddColor(Color{255, 0, 0})
+	p.AddColorWithAlpha(Color{0, 255, 0}, 128)
+
+	if idx := p.AddColor(Color{0, 0, 255}); idx != -1 {
+		t.Errorf("AddColor() past capacity = %v, want -1", idx)
+	}
+	if idx := p.AddColorWithAlpha(Color{0, 0, 255}, 0); idx != -1 {
+		t.Errorf("AddColorWithAlpha() past capacity = %v, want -1", idx)
+	}
+
+	if p.NumColors != 2 || p.Len() != 2 {
+		t.Errorf("NumColors = %v, Len() = %v, want 2, 2", p.NumColors, p.Len())
+	}
+	if len(p.Colors) != 2 || len(p.Alpha) != 2 {
+		t.Errorf("len(Colors) = %v, len(Alpha) = %v, want 2, 2", len(p.Colors), len(p.Alpha))
+	}
+	if p.GetColor(2) != (Color{}) {
+		t.Errorf("GetColor(2) = %v, want zero Color", p.GetColor(2))
+	}
+}
+
+func TestPaletteLenBoundsFindNearest(t *testing.T) {
+	p := NewPalette(2)
+	p.AddColor(Color{0, 0, 0})
+	p.AddColor(Color{255, 255, 255})
+	p.NumColors = 5 // corrupt count beyond the backing slice
+
+	if p.Len() != 2 {
+		t.Errorf("Len() = %v, want 2", p.Len())
+	}
+	if idx := p.FindNearest(Color{250, 250, 250}); idx != 1 {
+		t.Errorf("FindNearest() = %v, want 1", idx)
+	}
+	if idx := p.FindNearestWithAlpha(Color{5, 5, 5}, 255); idx != 0 {
+		t.Errorf("FindNearestWithAlpha() = %v, want 0", idx)
+	}
+	if p.GetColor(3) != (Color{}) {
+		t.Errorf("GetColor(3) = %v, want zero Color", p.GetColor(3))
+	}
+}
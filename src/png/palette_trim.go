@@ -0,0 +1,38 @@
+package png
+
+// trimPalette drops palette entries no pixel uses and remaps indexed to the
+// compacted palette. Used entries keep their relative order. If every entry is
+// used, indexed and palette are returned unchanged.
+func trimPalette(indexed []byte, palette Palette) ([]byte, Palette) {
+	var used [256]bool
+	for _, idx := range indexed {
+		used[idx] = true
+	}
+
+	n := palette.Len()
+	var remap [256]byte
+	trimmed := NewPalette(n)
+	for i := 0; i < n; i++ {
+		if !used[i] {
+			continue
+		}
+		if palette.Alpha != nil {
+			remap[i] = byte(trimmed.AddColorWithAlpha(palette.Colors[i], palette.GetAlpha(i)))
+		} else {
+			remap[i] = byte(trimmed.AddColor(palette.Colors[i]))
+		}
+	}
+	if trimmed.NumColors == n {
+		return indexed, palette
+	}
+
+	result := make([]byte, len(indexed))
+	for i, idx := range indexed {
+		result[i] = remap[idx]
+	}
+	trimmed.Colors = trimmed.Colors[:trimmed.NumColors]
+	if trimmed.Alpha != nil {
+		trimmed.Alpha = trimmed.Alpha[:trimmed.NumColors]
+	}
+	return result, *trimmed
+}
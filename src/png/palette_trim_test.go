@@ -0,0 +1,58 @@
+package png
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTrimPalette(t *testing.T) {
+	palette := NewPalette(4)
+	palette.AddColorWithAlpha(Color{1, 1, 1}, 255)
+	palette.AddColorWithAlpha(Color{2, 2, 2}, 10)
+	palette.AddColorWithAlpha(Color{3, 3, 3}, 255)
+	palette.AddColorWithAlpha(Color{4, 4, 4}, 20)
+
+	indexed, trimmed := trimPalette([]byte{3, 1, 3, 1}, *palette)
+	if want := []byte{1, 0, 1, 0}; !bytes.Equal(indexed, want) {
+		t.Errorf("trimPalette() indices = %v, want %v", indexed, want)
+	}
+	if trimmed.Len() != 2 || trimmed.GetColor(0) != (Color{2, 2, 2}) || trimmed.GetColor(1) != (Color{4, 4, 4}) {
+		t.Errorf("trimPalette() colors = %v, want [{2 2 2} {4 4 4}]", trimmed.Colors)
+	}
+	if trimmed.GetAlpha(0) != 10 || trimmed.GetAlpha(1) != 20 {
+		t.Errorf("trimPalette() alpha = %v, want [10 20]", trimmed.Alpha)
+	}
+
+	all := []byte{0, 1, 2, 3}
+	if got, same := trimPalette(all, *palette); &got[0] != &all[0] || same.Len() != 4 {
+		t.Error("trimPalette() with every entry used should return its inputs")
+	}
+}
+
+func TestEncodeIndexedTrimPalette(t *testing.T) {
+	palette := NewPalette(256)
+	for i := 0; i < 256; i++ {
+		palette.AddColor(Color{uint8(i), uint8(255 - i), uint8(i * 3)})
+	}
+	width, height := 12, 4
+	indexed := make([]byte, width*height)
+	want := make([]byte, 0, width*height*3)
+	for i := range indexed {
+		// 12 scattered entries.
+		indexed[i] = uint8(i%width*21 + 3)
+		c := palette.GetColor(int(indexed[i]))
+		want = append(want, c.R, c.G, c.B)
+	}
+
+	opts := FastOptions(width, height)
+	opts.TrimPalette = true
+	data, err := EncodeIndexed(indexed, width, height, *palette, opts)
+	if err != nil {
+		t.Fatalf("EncodeIndexed() error = %v", err)
+	}
+
+	if plte := findFirstChunk(t, parsePNGChunks(t, data), "PLTE"); len(plte.Data) != 12*3 {
+		t.Errorf("PLTE has %d colors, want 12", len(plte.Data)/3)
+	}
+	assertDecodedPixels(t, data, width, height, ColorRGB, want)
+}
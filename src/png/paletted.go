@@ -0,0 +1,32 @@
+package png
+
+import (
+	"fmt"
+	"image"
+)
+
+// EncodePaletted writes img as an indexed PNG, using its palette and indices
+// as-is with no re-quantization (see EncodeIndexed). The palette must have 1 to
+// 256 colors; width and height come from img.Bounds().
+func EncodePaletted(img *image.Paletted, opts Options) ([]byte, error) {
+	if len(img.Palette) == 0 || len(img.Palette) > 256 {
+		return nil, fmt.Errorf("png: palette must have 1 to 256 colors, got %d", len(img.Palette))
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= 0 || height <= 0 {
+		return nil, ErrInvalidDimensions
+	}
+	if err := checkImageSize(width, height, opts.MaxPixels); err != nil {
+		return nil, err
+	}
+
+	indexed := make([]byte, 0, width*height)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		start := img.PixOffset(bounds.Min.X, y)
+		indexed = append(indexed, img.Pix[start:start+width]...)
+	}
+
+	return EncodeIndexed(indexed, width, height, PaletteFromColorPalette(img.Palette), opts)
+}
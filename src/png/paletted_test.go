@@ -0,0 +1,68 @@
+package png
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	stdpng "image/png"
+	"testing"
+)
+
+func TestEncodePaletted(t *testing.T) {
+	cp := color.Palette{
+		color.RGBA{255, 0, 0, 255},
+		color.RGBA{0, 0, 255, 255},
+		color.NRGBA{0, 255, 0, 100},
+	}
+	// A sub-image, so rows are not contiguous in Pix.
+	full := image.NewPaletted(image.Rect(0, 0, 4, 3), cp)
+	for i := range full.Pix {
+		full.Pix[i] = uint8(i % 3)
+	}
+	img := full.SubImage(image.Rect(1, 1, 4, 3)).(*image.Paletted)
+
+	data, err := EncodePaletted(img, FastOptions(0, 0))
+	if err != nil {
+		t.Fatalf("EncodePaletted() error = %v", err)
+	}
+	if ihdr := findFirstChunk(t, parsePNGChunks(t, data), "IHDR"); ColorType(ihdr.Data[9]) != ColorIndexed {
+		t.Errorf("IHDR color type = %v, want %v", ColorType(ihdr.Data[9]), ColorIndexed)
+	}
+
+	decoded, err := stdpng.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("image/png.Decode() error = %v", err)
+	}
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			want := color.NRGBAModel.Convert(img.At(x+1, y+1))
+			got := color.NRGBAModel.Convert(decoded.At(x, y))
+			if got != want {
+				t.Errorf("pixel(%d,%d) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestEncodePalettedValidation(t *testing.T) {
+	big := make(color.Palette, 257)
+	for i := range big {
+		big[i] = color.Gray{Y: uint8(i)}
+	}
+	tests := []struct {
+		name string
+		img  *image.Paletted
+	}{
+		{"empty palette", image.NewPaletted(image.Rect(0, 0, 2, 2), nil)},
+		{"too many colors", image.NewPaletted(image.Rect(0, 0, 2, 2), big)},
+		{"empty image", image.NewPaletted(image.Rect(0, 0, 0, 0), color.Palette{color.Black})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := EncodePaletted(tt.img, FastOptions(0, 0)); err == nil {
+				t.Error("EncodePaletted() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package png
+
+import "fmt"
+
+// EncodePlanar encodes an image given as one plane per channel (for example R, G, B
+// and A as separate slices of width*height bytes). The planes are interleaved into
+// the packed layout EncodeWithOptions expects, so the plane count must match
+// colorType: 1 for grayscale, 3 for RGB, 4 for RGBA. Width, height and colorType
+// override opts.
+func EncodePlanar(planes [][]byte, width, height int, colorType ColorType, opts Options) ([]byte, error) {
+	if width <= 0 || height <= 0 {
+		return nil, ErrInvalidDimensions
+	}
+	if err := checkImageSize(width, height, opts.MaxPixels); err != nil {
+		return nil, err
+	}
+
+	var channels int
+	switch colorType {
+	case ColorGrayscale, ColorRGB, ColorRGBA:
+		channels = BytesPerPixel(colorType)
+	default:
+		return nil, fmt.Errorf("png: planar input does not support color type %v", colorType)
+	}
+	if len(planes) != channels {
+		return nil, fmt.Errorf("png: %v needs %d planes, got %d", colorType, channels, len(planes))
+	}
+
+	numPixels := width * height
+	for i, plane := range planes {
+		if len(plane) != numPixels {
+			return nil, fmt.Errorf("png: plane %d has %d bytes, want %d", i, len(plane), numPixels)
+		}
+	}
+
+	pixels := make([]byte, numPixels*channels)
+	for c, plane := range planes {
+		for i, v := range plane {
+			pixels[i*channels+c] = v
+		}
+	}
+
+	opts.Width = width
+	opts.Height = height
+	opts.ColorType = colorType
+	encoder, err := NewEncoderWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return encoder.Encode(pixels)
+}
@@ -0,0 +1,65 @@
+package png
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncodePlanarRGB(t *testing.T) {
+	width, height := 4, 3
+	numPixels := width * height
+	r, g, b := make([]byte, numPixels), make([]byte, numPixels), make([]byte, numPixels)
+	interleaved := make([]byte, 0, numPixels*3)
+	for i := 0; i < numPixels; i++ {
+		r[i], g[i], b[i] = uint8(i*20), uint8(255-i*10), uint8(i*i)
+		interleaved = append(interleaved, r[i], g[i], b[i])
+	}
+
+	data, err := EncodePlanar([][]byte{r, g, b}, width, height, ColorRGB, FastOptions(0, 0))
+	if err != nil {
+		t.Fatalf("EncodePlanar() error = %v", err)
+	}
+	assertDecodedPixels(t, data, width, height, ColorRGB, interleaved)
+}
+
+func TestEncodePlanarRGBA(t *testing.T) {
+	r := []byte{255, 0}
+	g := []byte{0, 255}
+	b := []byte{0, 0}
+	a := []byte{255, 128}
+
+	data, err := EncodePlanar([][]byte{r, g, b, a}, 2, 1, ColorRGBA, BalancedOptions(0, 0))
+	if err != nil {
+		t.Fatalf("EncodePlanar() error = %v", err)
+	}
+	assertDecodedPixels(t, data, 2, 1, ColorRGBA, []byte{255, 0, 0, 255, 0, 255, 0, 128})
+}
+
+func TestEncodePlanarErrors(t *testing.T) {
+	plane := make([]byte, 4)
+
+	tests := []struct {
+		name      string
+		planes    [][]byte
+		colorType ColorType
+		wantErr   string
+	}{
+		{"too few planes", [][]byte{plane, plane}, ColorRGB, "needs 3 planes, got 2"},
+		{"too many planes", [][]byte{plane, plane}, ColorGrayscale, "needs 1 planes, got 2"},
+		{"short plane", [][]byte{plane, plane, plane[:3]}, ColorRGB, "plane 2 has 3 bytes, want 4"},
+		{"indexed", [][]byte{plane}, ColorIndexed, "does not support color type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := EncodePlanar(tt.planes, 2, 2, tt.colorType, FastOptions(0, 0))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("EncodePlanar() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := EncodePlanar([][]byte{plane}, 0, 2, ColorGrayscale, FastOptions(0, 0)); err != ErrInvalidDimensions {
+		t.Errorf("EncodePlanar() zero width error = %v, want %v", err, ErrInvalidDimensions)
+	}
+}
@@ -1,11 +1,6 @@
 package png
 
-import (
-	"encoding/binary"
-	"io"
-
-	"github.com/mac/go-pixo/src/compress"
-)
+import "io"
 
 // WritePLTE writes palette as PLTE chunk.
 // Palette must have 1-256 colors for valid PNG.
@@ -24,25 +19,9 @@ func WritePLTE(w io.Writer, palette Palette) error {
 		data[i*3+2] = palette.Colors[i].B
 	}
 
-	length := uint32(len(data))
-	if err := binary.Write(w, binary.BigEndian, length); err != nil {
-		return err
-	}
-
-	if err := binary.Write(w, nil, []byte("PLTE")); err != nil {
-		return err
-	}
-
-	if _, err := w.Write(data); err != nil {
-		return err
-	}
-
-	crc := compress.CRC32(append([]byte("PLTE"), data...))
-	if err := binary.Write(w, binary.BigEndian, crc); err != nil {
-		return err
-	}
-
-	return nil
+	chunk := Chunk{chunkType: "PLTE", Data: data}
+	_, err := chunk.WriteTo(w)
+	return err
 }
 
 // PLTEChunkData returns the raw PLTE chunk data without chunk wrapper.
@@ -0,0 +1,33 @@
+package png
+
+import "math"
+
+// Posterize reduces each color channel to 2^bitsPerChannel evenly spaced levels
+// (including 0 and 255), snapping every sample to the nearest level. This cuts the
+// number of distinct colors sharply, which makes later palette building cheaper.
+// Alpha is left unchanged. bitsPerChannel outside 1-7 returns a copy of pixels.
+func Posterize(pixels []byte, colorType ColorType, bitsPerChannel int) []byte {
+	result := append([]byte(nil), pixels...)
+	if bitsPerChannel < 1 || bitsPerChannel > 7 {
+		return result
+	}
+
+	maxLevel := float64(int(1)<<bitsPerChannel - 1)
+	var lut [256]uint8
+	for v := range lut {
+		level := math.Round(float64(v) * maxLevel / 255)
+		lut[v] = uint8(math.Round(level * 255 / maxLevel))
+	}
+
+	bpp := BytesPerPixel(colorType)
+	channels := bpp
+	if colorType == ColorRGBA {
+		channels = 3
+	}
+	for i := 0; i+bpp <= len(result); i += bpp {
+		for c := 0; c < channels; c++ {
+			result[i+c] = lut[result[i+c]]
+		}
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+package png
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPosterizeLevels(t *testing.T) {
+	pixels := make([]byte, 256*4)
+	for i := 0; i < 256; i++ {
+		pixels[i*4] = uint8(i)
+		pixels[i*4+1] = uint8(255 - i)
+		pixels[i*4+2] = uint8(i * 7)
+		pixels[i*4+3] = uint8(i)
+	}
+
+	got := Posterize(pixels, ColorRGBA, 2)
+
+	for c := 0; c < 3; c++ {
+		levels := make(map[byte]bool)
+		for i := c; i < len(got); i += 4 {
+			levels[got[i]] = true
+		}
+		if len(levels) > 4 {
+			t.Errorf("channel %d has %d levels, want at most 4", c, len(levels))
+		}
+		for v := range levels {
+			if v != 0 && v != 85 && v != 170 && v != 255 {
+				t.Errorf("channel %d level %d, want one of 0, 85, 170, 255", c, v)
+			}
+		}
+	}
+	for i := 3; i < len(got); i += 4 {
+		if got[i] != pixels[i] {
+			t.Fatalf("alpha at pixel %d = %d, want %d unchanged", i/4, got[i], pixels[i])
+		}
+	}
+}
+
+func TestPosterizeNearestLevel(t *testing.T) {
+	tests := []struct {
+		in, want byte
+	}{
+		{0, 0}, {42, 0}, {43, 85}, {100, 85}, {128, 170}, {212, 170}, {213, 255}, {255, 255},
+	}
+
+	for _, tt := range tests {
+		if got := Posterize([]byte{tt.in}, ColorGrayscale, 2); got[0] != tt.want {
+			t.Errorf("Posterize(%d, 2 bits) = %d, want %d", tt.in, got[0], tt.want)
+		}
+	}
+}
+
+func TestPosterizeOutOfRangeBitsCopies(t *testing.T) {
+	pixels := []byte{1, 2, 3}
+	for _, bits := range []int{0, 8, -1} {
+		got := Posterize(pixels, ColorRGB, bits)
+		if !bytes.Equal(got, pixels) {
+			t.Errorf("Posterize(bits=%d) = %v, want %v", bits, got, pixels)
+		}
+		got[0] = 99
+		if pixels[0] != 1 {
+			t.Fatal("Posterize() returned the input slice instead of a copy")
+		}
+	}
+}
+
+func TestEncodePosterizeReducesColors(t *testing.T) {
+	width, height := 32, 32
+	pixels := make([]byte, width*height*3)
+	for i := range pixels {
+		pixels[i] = uint8(i * 31)
+	}
+
+	opts := BalancedOptions(width, height)
+	opts.ColorType = ColorRGB
+	opts.Posterize = 1
+	opts.AutoPalette = true
+	opts.ReduceColorType = false
+
+	data, err := EncodeWithOptions(pixels, opts)
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+	if ihdr := findFirstChunk(t, parsePNGChunks(t, data), "IHDR"); ColorType(ihdr.Data[9]) != ColorIndexed {
+		t.Errorf("IHDR color type = %v, want %v (8 colors fit a palette)", ColorType(ihdr.Data[9]), ColorIndexed)
+	}
+}
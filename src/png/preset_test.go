@@ -2,6 +2,7 @@ package png
 
 import (
 	"bytes"
+	"fmt"
 	"image"
 	_ "image/png"
 	"testing"
@@ -149,3 +150,43 @@ func TestColorReductionEffect(t *testing.T) {
 		t.Errorf("Color reduction should result in smaller size. Got %d vs %d", len(dataRed), len(dataNoRed))
 	}
 }
+
+func TestPresetsSolidColor(t *testing.T) {
+	width, height := 256, 256
+	// optimized is the decoded color under presets that optimize alpha, which
+	// zero the color hidden behind full transparency.
+	colors := []struct {
+		color, optimized [4]byte
+	}{
+		{[4]byte{0, 0, 0, 255}, [4]byte{0, 0, 0, 255}},
+		{[4]byte{12, 200, 99, 255}, [4]byte{12, 200, 99, 255}},
+		{[4]byte{255, 255, 255, 0}, [4]byte{0, 0, 0, 0}},
+		{[4]byte{40, 80, 120, 77}, [4]byte{40, 80, 120, 77}},
+	}
+	presets := []struct {
+		name           string
+		opts           func(w, h int) Options
+		optimizesAlpha bool
+	}{
+		{"fast", FastOptions, false},
+		{"balanced", BalancedOptions, true},
+		{"max", MaxOptions, true},
+	}
+
+	for _, c := range colors {
+		pixels := bytes.Repeat(c.color[:], width*height)
+		for _, p := range presets {
+			t.Run(fmt.Sprintf("%s/%v", p.name, c.color), func(t *testing.T) {
+				want := c.color
+				if p.optimizesAlpha {
+					want = c.optimized
+				}
+				data, err := EncodeWithOptions(pixels, p.opts(width, height))
+				if err != nil {
+					t.Fatalf("EncodeWithOptions() error = %v", err)
+				}
+				assertDecodedPixels(t, data, width, height, ColorRGBA, bytes.Repeat(want[:], width*height))
+			})
+		}
+	}
+}
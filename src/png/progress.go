@@ -0,0 +1,44 @@
+package png
+
+// Progress stages reported through Options.Progress.
+const (
+	ProgressAnalysis    = "analysis"
+	ProgressFiltering   = "filtering"
+	ProgressCompression = "compression"
+	ProgressDone        = "done"
+)
+
+// Fractions at which each stage starts. Filtering advances from
+// progressFilterStart to progressCompressStart as rows are processed.
+const (
+	progressFilterStart   = 0.1
+	progressCompressStart = 0.6
+)
+
+// progressRowSteps is roughly how many filtering updates an image produces.
+const progressRowSteps = 16
+
+// reportProgress calls opts.Progress if it is set.
+func (o Options) reportProgress(stage string, fraction float64) {
+	if o.Progress != nil {
+		o.Progress(stage, fraction)
+	}
+}
+
+// reportRowProgress reports filtering progress every height/progressRowSteps rows
+// and after the last row.
+func (o Options) reportRowProgress(row, height int) {
+	if o.Progress == nil {
+		return
+	}
+	step := height / progressRowSteps
+	if step < 1 {
+		step = 1
+	}
+	done := row + 1
+	if done%step != 0 && done != height {
+		return
+	}
+	fraction := progressFilterStart + (progressCompressStart-progressFilterStart)*float64(done)/float64(height)
+	o.Progress(ProgressFiltering, fraction)
+}
@@ -0,0 +1,63 @@
+package png
+
+import "testing"
+
+func TestEncodeProgress(t *testing.T) {
+	width, height := 64, 100
+	pixels := make([]byte, width*height*4)
+	for i := range pixels {
+		pixels[i] = uint8(i * 13)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Options)
+	}{
+		{"true color", nil},
+		{"quantized", func(o *Options) { o.MaxColors = 16; o.ReduceColorType = false }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			type call struct {
+				stage    string
+				fraction float64
+			}
+			var calls []call
+
+			opts := MaxOptions(width, height)
+			if tt.modify != nil {
+				tt.modify(&opts)
+			}
+			opts.Progress = func(stage string, fraction float64) {
+				calls = append(calls, call{stage, fraction})
+			}
+
+			if _, err := EncodeWithOptions(pixels, opts); err != nil {
+				t.Fatalf("EncodeWithOptions() error = %v", err)
+			}
+
+			if len(calls) < 4 {
+				t.Fatalf("got %d progress calls, want at least 4: %v", len(calls), calls)
+			}
+			if calls[0].stage != ProgressAnalysis {
+				t.Errorf("first stage = %q, want %q", calls[0].stage, ProgressAnalysis)
+			}
+			stages := make(map[string]bool)
+			for i, c := range calls {
+				stages[c.stage] = true
+				if i > 0 && c.fraction < calls[i-1].fraction {
+					t.Errorf("call %d fraction %v < previous %v", i, c.fraction, calls[i-1].fraction)
+				}
+			}
+			for _, stage := range []string{ProgressFiltering, ProgressCompression} {
+				if !stages[stage] {
+					t.Errorf("stage %q was never reported", stage)
+				}
+			}
+			if last := calls[len(calls)-1]; last.stage != ProgressDone || last.fraction != 1 {
+				t.Errorf("last call = %v, want {%q 1}", last, ProgressDone)
+			}
+		})
+	}
+}
@@ -1,8 +1,16 @@
 package png
 
+import "sort"
+
 // Quantize converts true-color pixels to indexed palette.
 // Returns indexed pixels (1 byte per pixel) and palette.
 func Quantize(pixels []byte, colorType int, maxColors int) ([]byte, Palette) {
+	palette := quantizePalette(pixels, colorType, maxColors)
+	return QuantizeToPalette(pixels, colorType, palette), palette
+}
+
+// quantizePalette builds Quantize's median-cut palette.
+func quantizePalette(pixels []byte, colorType int, maxColors int) Palette {
 	if maxColors <= 0 {
 		maxColors = 256
 	}
@@ -19,28 +27,36 @@ func Quantize(pixels []byte, colorType int, maxColors int) ([]byte, Palette) {
 	for _, c := range paletteColors {
 		palette.AddColor(c)
 	}
+	return *palette
+}
 
-	bpp := BytesPerPixel(ColorType(colorType))
-	width := len(pixels) / bpp
-
-	indexed := make([]byte, width)
+// QuantizeWithHistogram converts true-color pixels to indexed palette like Quantize,
+// additionally returning the number of pixels mapped to each palette index.
+func QuantizeWithHistogram(pixels []byte, colorType int, maxColors int) ([]byte, Palette, []int) {
+	indexed, palette := Quantize(pixels, colorType, maxColors)
 
-	for i := 0; i < width; i++ {
-		offset := i * bpp
-		c := Color{
-			R: pixels[offset],
-			G: pixels[offset+1],
-			B: pixels[offset+2],
-		}
-		indexed[i] = uint8(palette.FindNearest(c))
+	histogram := make([]int, palette.NumColors)
+	for _, idx := range indexed {
+		histogram[idx]++
 	}
 
-	return indexed, *palette
+	return indexed, palette, histogram
 }
 
 // QuantizeWithAlpha converts true-color pixels with alpha to indexed palette.
 // Returns indexed pixels (1 byte per pixel) and palette with alpha.
+// Colors that differ only in alpha are kept apart, so the palette's Alpha
+// values can be written as a tRNS chunk. RGB input is treated as opaque.
 func QuantizeWithAlpha(pixels []byte, colorType int, maxColors int) ([]byte, Palette) {
+	palette := quantizePaletteWithAlpha(pixels, colorType, maxColors)
+	bpp := BytesPerPixel(ColorType(colorType))
+	indexed := make([]byte, len(pixels)/bpp)
+	mapToPaletteWithAlpha(indexed, pixels, bpp, &palette)
+	return indexed, palette
+}
+
+// quantizePaletteWithAlpha builds QuantizeWithAlpha's median-cut palette.
+func quantizePaletteWithAlpha(pixels []byte, colorType int, maxColors int) Palette {
 	if maxColors <= 0 {
 		maxColors = 256
 	}
@@ -51,56 +67,68 @@ func QuantizeWithAlpha(pixels []byte, colorType int, maxColors int) ([]byte, Pal
 	bpp := BytesPerPixel(ColorType(colorType))
 	width := len(pixels) / bpp
 
-	colorMap := make(map[ColorWithCount]int)
+	colorMap := make(map[AlphaColor]int)
 	for i := 0; i < width; i++ {
-		offset := i * bpp
-		cwc := ColorWithCount{
-			Color: Color{
-				R: pixels[offset],
-				G: pixels[offset+1],
-				B: pixels[offset+2],
-			},
-			Count: 1,
-		}
-		colorMap[cwc]++
+		colorMap[alphaColorAt(pixels, i*bpp, bpp)]++
 	}
 
-	colorsWithCount := make([]ColorWithCount, 0, len(colorMap))
+	colorsWithCount := make([]AlphaColorWithCount, 0, len(colorMap))
 	for c, count := range colorMap {
-		c.Count = count
-		colorsWithCount = append(colorsWithCount, c)
+		colorsWithCount = append(colorsWithCount, AlphaColorWithCount{AlphaColor: c, Count: count})
 	}
+	sort.Slice(colorsWithCount, func(i, j int) bool {
+		a, b := colorsWithCount[i], colorsWithCount[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Color != b.Color {
+			return colorLess(a.Color, b.Color)
+		}
+		return a.A < b.A
+	})
 
 	paletteColors := MedianCutWithAlpha(colorsWithCount, maxColors)
 
 	palette := NewPalette(len(paletteColors))
 	for _, c := range paletteColors {
-		palette.AddColor(c)
+		palette.AddColorWithAlpha(c.Color, c.A)
 	}
+	return *palette
+}
 
-	indexed := make([]byte, width)
+// alphaColorAt reads the pixel at offset, treating pixels without an alpha channel as opaque.
+// Single-byte pixels are read as grayscale.
+func alphaColorAt(pixels []byte, offset, bpp int) AlphaColor {
+	if bpp < 3 {
+		v := pixels[offset]
+		return AlphaColor{Color: Color{R: v, G: v, B: v}, A: 255}
+	}
 
-	for i := 0; i < width; i++ {
-		offset := i * bpp
-		c := Color{
+	c := AlphaColor{
+		Color: Color{
 			R: pixels[offset],
 			G: pixels[offset+1],
 			B: pixels[offset+2],
-		}
-		indexed[i] = uint8(palette.FindNearest(c))
+		},
+		A: 255,
 	}
-
-	return indexed, *palette
+	if bpp == 4 {
+		c.A = pixels[offset+3]
+	}
+	return c
 }
 
 // QuantizeToPalette quantizes pixels to a pre-defined palette.
 func QuantizeToPalette(pixels []byte, colorType int, palette Palette) []byte {
 	bpp := BytesPerPixel(ColorType(colorType))
-	width := len(pixels) / bpp
-
-	indexed := make([]byte, width)
+	indexed := make([]byte, len(pixels)/bpp)
+	mapToPalette(indexed, pixels, bpp, &palette)
+	return indexed
+}
 
-	for i := 0; i < width; i++ {
+// mapToPalette writes the nearest palette index of each pixel into indexed.
+func mapToPalette(indexed, pixels []byte, bpp int, palette *Palette) {
+	for i := range indexed {
 		offset := i * bpp
 		c := Color{
 			R: pixels[offset],
@@ -109,8 +137,39 @@ func QuantizeToPalette(pixels []byte, colorType int, palette Palette) []byte {
 		}
 		indexed[i] = uint8(palette.FindNearest(c))
 	}
+}
 
-	return indexed
+// mapToPaletteWithAlpha is mapToPalette matching each pixel's alpha as well
+// as its color.
+func mapToPaletteWithAlpha(indexed, pixels []byte, bpp int, palette *Palette) {
+	for i := range indexed {
+		c := alphaColorAt(pixels, i*bpp, bpp)
+		indexed[i] = uint8(palette.FindNearestWithAlpha(c.Color, c.A))
+	}
+}
+
+// QuantizationError measures how well palette represents an image: each pixel is
+// mapped to its nearest palette color and the squared R, G and B differences are
+// summed into total. mse is total divided by the number of channel samples
+// (pixels × 3). Alpha is ignored. An empty palette or image yields zero error.
+func QuantizationError(pixels []byte, colorType int, palette Palette) (total int64, mse float64) {
+	bpp := BytesPerPixel(ColorType(colorType))
+	numPixels := len(pixels) / bpp
+	if numPixels == 0 || palette.Len() == 0 {
+		return 0, 0
+	}
+
+	for i := 0; i < numPixels; i++ {
+		c := alphaColorAt(pixels, i*bpp, bpp).Color
+		nearest := palette.Colors[palette.FindNearest(c)]
+
+		dr := int64(c.R) - int64(nearest.R)
+		dg := int64(c.G) - int64(nearest.G)
+		db := int64(c.B) - int64(nearest.B)
+		total += dr*dr + dg*dg + db*db
+	}
+
+	return total, float64(total) / float64(numPixels*3)
 }
 
 // QuantizeWithDithering applies quantization with Floyd-Steinberg dithering.
@@ -0,0 +1,53 @@
+package png
+
+import (
+	"runtime"
+	"sync"
+)
+
+// QuantizeToPaletteParallel is QuantizeToPalette split across workers
+// goroutines, each mapping its own band of rows into the shared output. The
+// result is identical to QuantizeToPalette. width is the image width in
+// pixels; workers <= 0 means runtime.GOMAXPROCS(0).
+func QuantizeToPaletteParallel(pixels []byte, width int, colorType int, palette Palette, workers int) []byte {
+	return mapToPaletteParallel(pixels, width, BytesPerPixel(ColorType(colorType)), &palette, workers, mapToPalette)
+}
+
+// mapToPaletteParallel runs mapRows over bands of rows on workers goroutines.
+func mapToPaletteParallel(pixels []byte, width, bpp int, palette *Palette, workers int,
+	mapRows func(indexed, pixels []byte, bpp int, palette *Palette)) []byte {
+	numPixels := len(pixels) / bpp
+	indexed := make([]byte, numPixels)
+	if width <= 0 || numPixels == 0 {
+		return indexed
+	}
+
+	rows := (numPixels + width - 1) / width
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > rows {
+		workers = rows
+	}
+	if workers <= 1 {
+		mapRows(indexed, pixels, bpp, palette)
+		return indexed
+	}
+
+	bandPixels := (rows + workers - 1) / workers * width
+	var wg sync.WaitGroup
+	for start := 0; start < numPixels; start += bandPixels {
+		end := start + bandPixels
+		if end > numPixels {
+			end = numPixels
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			mapRows(indexed[start:end], pixels[start*bpp:end*bpp], bpp, palette)
+		}(start, end)
+	}
+	wg.Wait()
+
+	return indexed
+}
@@ -0,0 +1,53 @@
+package png
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestQuantizeToPaletteParallel(t *testing.T) {
+	width, height := 37, 23
+	for _, colorType := range []ColorType{ColorRGB, ColorRGBA} {
+		pixels := benchPixels(width, height, BytesPerPixel(colorType))
+		palette := ExtractPalette(pixels, int(colorType), 64)
+		want := QuantizeToPalette(pixels, int(colorType), palette)
+
+		for _, workers := range []int{0, 1, 2, 3, 8, 100} {
+			t.Run(fmt.Sprintf("%s/%d workers", colorType, workers), func(t *testing.T) {
+				got := QuantizeToPaletteParallel(pixels, width, int(colorType), palette, workers)
+				if !bytes.Equal(got, want) {
+					t.Error("QuantizeToPaletteParallel() differs from QuantizeToPalette()")
+				}
+			})
+		}
+	}
+}
+
+func TestQuantizeToPaletteParallelEmpty(t *testing.T) {
+	palette := ExtractPalette([]byte{1, 2, 3}, int(ColorRGB), 4)
+	if got := QuantizeToPaletteParallel(nil, 4, int(ColorRGB), palette, 4); len(got) != 0 {
+		t.Errorf("QuantizeToPaletteParallel(nil) = %v, want empty", got)
+	}
+}
+
+func TestEncodeParallelQuantization(t *testing.T) {
+	width, height := 64, 48
+	pixels := benchPixels(width, height, 3)
+	opts := FastOptions(width, height)
+	opts.ColorType = ColorRGB
+	opts.MaxColors = 32
+
+	serial, err := EncodeWithOptions(pixels, opts)
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+	opts.Parallel = true
+	parallel, err := EncodeWithOptions(pixels, opts)
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() with Parallel error = %v", err)
+	}
+	if !bytes.Equal(parallel, serial) {
+		t.Error("EncodeWithOptions() with Parallel differs from the serial encode")
+	}
+}
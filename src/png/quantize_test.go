@@ -276,3 +276,157 @@ func TestQuantize1x1Image(t *testing.T) {
 		t.Errorf("Quantize() 1x1 palette size = %v, want 1", palette.NumColors)
 	}
 }
+
+func TestQuantizeWithHistogram(t *testing.T) {
+	// 3x2 RGB image: 3 red, 2 green, 1 blue
+	pixels := []byte{
+		255, 0, 0, 255, 0, 0, 255, 0, 0,
+		0, 255, 0, 0, 255, 0, 0, 0, 255,
+	}
+
+	indexed, palette, histogram := QuantizeWithHistogram(pixels, 2, 256)
+
+	if len(histogram) != palette.NumColors {
+		t.Fatalf("QuantizeWithHistogram() histogram length = %v, want %v", len(histogram), palette.NumColors)
+	}
+
+	total := 0
+	for _, count := range histogram {
+		total += count
+	}
+	if total != len(indexed) {
+		t.Errorf("QuantizeWithHistogram() histogram sum = %v, want %v", total, len(indexed))
+	}
+
+	want := map[Color]int{
+		{255, 0, 0}: 3,
+		{0, 255, 0}: 2,
+		{0, 0, 255}: 1,
+	}
+	for i := 0; i < palette.NumColors; i++ {
+		c := palette.Colors[i]
+		if histogram[i] != want[c] {
+			t.Errorf("QuantizeWithHistogram() count for %v = %v, want %v", c, histogram[i], want[c])
+		}
+	}
+}
+
+func TestQuantizeWithHistogramSingleColor(t *testing.T) {
+	pixels := []byte{
+		10, 20, 30, 10, 20, 30,
+		10, 20, 30, 10, 20, 30,
+	}
+
+	_, _, histogram := QuantizeWithHistogram(pixels, 2, 256)
+
+	nonZero := 0
+	for _, count := range histogram {
+		if count > 0 {
+			nonZero++
+			if count != 4 {
+				t.Errorf("QuantizeWithHistogram() single bin count = %v, want 4", count)
+			}
+		}
+	}
+	if nonZero != 1 {
+		t.Errorf("QuantizeWithHistogram() non-zero bins = %v, want 1", nonZero)
+	}
+}
+
+func TestQuantizeWithAlphaPreservesDistinctAlpha(t *testing.T) {
+	// 2x2 RGBA image: same RGB, two alpha levels, plus two near colors
+	pixels := []byte{
+		255, 0, 0, 255, 255, 0, 0, 0,
+		250, 0, 0, 255, 250, 0, 0, 0,
+	}
+
+	indexed, palette := QuantizeWithAlpha(pixels, 6, 2)
+
+	if palette.NumColors != 2 {
+		t.Fatalf("QuantizeWithAlpha() palette size = %v, want 2", palette.NumColors)
+	}
+	if !palette.HasAlpha() {
+		t.Fatalf("QuantizeWithAlpha() palette has no alpha")
+	}
+	if indexed[0] == indexed[1] {
+		t.Errorf("QuantizeWithAlpha() opaque and transparent red share index %v", indexed[0])
+	}
+	if palette.GetAlpha(int(indexed[0])) != 255 || palette.GetAlpha(int(indexed[1])) != 0 {
+		t.Errorf("QuantizeWithAlpha() alphas = %v, %v, want 255, 0",
+			palette.GetAlpha(int(indexed[0])), palette.GetAlpha(int(indexed[1])))
+	}
+
+	alphaValues, hasTransparency := ExtractAlphaFromPixels(pixels, palette)
+	if !hasTransparency || len(alphaValues) != 2 {
+		t.Errorf("ExtractAlphaFromPixels() = %v, %v, want 2 values with transparency", alphaValues, hasTransparency)
+	}
+}
+
+func TestQuantizeWithAlphaRGBIsOpaque(t *testing.T) {
+	pixels := []byte{255, 0, 0, 0, 255, 0}
+
+	_, palette := QuantizeWithAlpha(pixels, 2, 256)
+
+	if palette.HasAlpha() {
+		t.Errorf("QuantizeWithAlpha() RGB palette HasAlpha() = true, want false")
+	}
+}
+
+func TestQuantizeVariantsEmptyPixels(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantize func() ([]byte, Palette)
+	}{
+		{"QuantizeWithDithering", func() ([]byte, Palette) { return QuantizeWithDithering(nil, int(ColorRGB), 16) }},
+		{"QuantizeWithAlpha", func() ([]byte, Palette) { return QuantizeWithAlpha(nil, int(ColorRGBA), 16) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexed, palette := tt.quantize()
+			if len(indexed) != 0 || palette.NumColors != 0 {
+				t.Errorf("%s() = %d pixels, %d colors, want 0, 0", tt.name, len(indexed), palette.NumColors)
+			}
+		})
+	}
+}
+
+func TestQuantizationErrorExactPalette(t *testing.T) {
+	pixels := []byte{
+		255, 0, 0, 0, 255, 0,
+		0, 0, 255, 255, 255, 0,
+		255, 0, 0, 12, 34, 56,
+	}
+
+	_, palette, ok := BuildExactPalette(pixels, ColorRGB)
+	if !ok {
+		t.Fatal("BuildExactPalette() failed")
+	}
+
+	total, mse := QuantizationError(pixels, 2, palette)
+	if total != 0 || mse != 0 {
+		t.Errorf("QuantizationError() = (%v, %v), want (0, 0)", total, mse)
+	}
+}
+
+func TestQuantizationError(t *testing.T) {
+	// Two pixels mapped to black: (3,4,0) -> 9+16 and (0,0,10) -> 100
+	pixels := []byte{3, 4, 0, 0, 0, 10}
+	palette := NewPalette(1)
+	palette.AddColor(Color{})
+
+	total, mse := QuantizationError(pixels, 2, *palette)
+	if total != 125 {
+		t.Errorf("QuantizationError() total = %v, want 125", total)
+	}
+	if want := 125.0 / 6; mse != want {
+		t.Errorf("QuantizationError() mse = %v, want %v", mse, want)
+	}
+}
+
+func TestQuantizationErrorEmptyPalette(t *testing.T) {
+	total, mse := QuantizationError([]byte{1, 2, 3}, 2, Palette{})
+	if total != 0 || mse != 0 {
+		t.Errorf("QuantizationError() = (%v, %v), want (0, 0)", total, mse)
+	}
+}
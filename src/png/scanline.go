@@ -3,6 +3,7 @@ package png
 import (
 	"fmt"
 	"io"
+	"math"
 )
 
 // ScanlineError represents errors for scanline operations.
@@ -70,11 +71,44 @@ func BytesPerPixel(colorType ColorType) int {
 	}
 }
 
+// BytesPerPixelDepth returns the filter stride for colorType at bitDepth: the number
+// of bytes in a complete pixel, rounded up to at least 1. For 16-bit samples this is
+// channels×2 (6 for RGB16, 8 for RGBA16), so the filters still operate byte-wise but
+// compare each byte with the corresponding byte of the neighboring pixel.
+func BytesPerPixelDepth(colorType ColorType, bitDepth int) int {
+	channels := BytesPerPixel(colorType)
+	if colorType == ColorIndexed {
+		channels = 1
+	}
+	bpp := (channels*bitDepth + 7) / 8
+	if bpp < 1 {
+		return 1
+	}
+	return bpp
+}
+
 // ScanlineLength returns the expected length of a scanline for a given width and color type.
+// The product is computed in 64 bits and saturates at math.MaxInt instead of wrapping.
 func ScanlineLength(width int, colorType ColorType) int {
 	bpp := BytesPerPixel(colorType)
 	// Each scanline has 1 filter byte + width * bytes per pixel
-	return 1 + width*bpp
+	return clampToInt(1 + saturatingMul(int64(width), int64(bpp)))
+}
+
+// saturatingMul returns a*b for non-negative a and b, or math.MaxInt64 if the product overflows.
+func saturatingMul(a, b int64) int64 {
+	if a != 0 && b > math.MaxInt64/a {
+		return math.MaxInt64
+	}
+	return a * b
+}
+
+// clampToInt converts v to int, saturating at math.MaxInt on overflow.
+func clampToInt(v int64) int {
+	if v < 0 || v > math.MaxInt {
+		return math.MaxInt
+	}
+	return int(v)
 }
 
 // ValidateScanlineData checks if the pixel data length matches the expected scanline length.
@@ -86,3 +120,78 @@ func ValidateScanlineData(pixels []byte, width int, colorType ColorType) error {
 	}
 	return nil
 }
+
+// BuildScanlines filters every row of pixels with strategy and returns the
+// uncompressed scanline stream (each row's filter byte followed by the filtered
+// row) together with the filter chosen for each row. This is exactly the data
+// the encoder compresses into IDAT, including its narrowing of the adaptive
+// strategy for grayscale.
+func BuildScanlines(pixels []byte, width, height int, colorType ColorType, strategy FilterStrategy) ([]byte, []FilterType, error) {
+	return buildScanlines(pixels, width, height, 0, colorType, strategy, nil)
+}
+
+// BuildScanlinesWithStride is BuildScanlines for rows that start stride bytes
+// apart, of which only the first width*BytesPerPixel bytes are used (see
+// Options.RowStride). The last row may be short. A zero stride means rows are
+// tightly packed.
+func BuildScanlinesWithStride(pixels []byte, width, height, stride int, colorType ColorType, strategy FilterStrategy) ([]byte, []FilterType, error) {
+	return buildScanlines(pixels, width, height, stride, colorType, strategy, nil)
+}
+
+// buildScanlines is BuildScanlinesWithStride with a hook called after each row
+// is filtered, which the encoder uses to report progress.
+func buildScanlines(pixels []byte, width, height, stride int, colorType ColorType, strategy FilterStrategy, onRow func(y int)) ([]byte, []FilterType, error) {
+	if width <= 0 || height <= 0 {
+		return nil, nil, ErrInvalidDimensions
+	}
+
+	bpp := BytesPerPixel(colorType)
+	rowLen := width * bpp
+	if err := checkRowStride(len(pixels), rowLen, height, stride); err != nil {
+		return nil, nil, err
+	}
+	if stride == 0 {
+		stride = rowLen
+	}
+
+	strategy = scanlineStrategy(colorType, strategy)
+	data := make([]byte, 0, (1+rowLen)*height)
+	filters := make([]FilterType, height)
+	var prevRow []byte
+	for y := 0; y < height; y++ {
+		row := pixels[y*stride : y*stride+rowLen]
+		filterType, filteredRow := SelectFilterWithStrategy(row, prevRow, bpp, strategy)
+		data = append(data, byte(filterType))
+		data = append(data, filteredRow...)
+		filters[y] = filterType
+		prevRow = row
+		if onRow != nil {
+			onRow(y)
+		}
+	}
+
+	return data, filters, nil
+}
+
+// checkRowStride reports whether n bytes hold height rows of rowLen bytes
+// that start stride bytes apart. A zero stride, or one equal to rowLen, means
+// tightly packed rows and requires exactly rowLen*height bytes; a wider stride
+// allows the last row to be short.
+func checkRowStride(n, rowLen, height, stride int) error {
+	if stride == 0 || stride == rowLen {
+		if n != rowLen*height {
+			return fmt.Errorf("png: pixel data length %d does not match expected %d for %d rows of %d bytes",
+				n, rowLen*height, height, rowLen)
+		}
+		return nil
+	}
+	if stride < rowLen {
+		return fmt.Errorf("png: row stride %d is less than the row size %d", stride, rowLen)
+	}
+	// Compare by division: stride*(height-1)+rowLen can overflow for a huge stride.
+	if n < rowLen || (height > 1 && stride > (n-rowLen)/(height-1)) {
+		return fmt.Errorf("png: pixel data length %d is too short for %d rows %d bytes apart",
+			n, height, stride)
+	}
+	return nil
+}
@@ -2,7 +2,12 @@ package png
 
 import (
 	"bytes"
+	"compress/zlib"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"reflect"
 	"testing"
 )
 
@@ -146,6 +151,31 @@ func TestBytesPerPixel(t *testing.T) {
 	}
 }
 
+func TestBytesPerPixelDepth(t *testing.T) {
+	tests := []struct {
+		colorType ColorType
+		bitDepth  int
+		expect    int
+	}{
+		{ColorGrayscale, 1, 1},
+		{ColorGrayscale, 8, 1},
+		{ColorGrayscale, 16, 2},
+		{ColorRGB, 8, 3},
+		{ColorRGB, 16, 6},
+		{ColorRGBA, 16, 8},
+		{ColorIndexed, 4, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v/%d", tt.colorType, tt.bitDepth), func(t *testing.T) {
+			got := BytesPerPixelDepth(tt.colorType, tt.bitDepth)
+			if got != tt.expect {
+				t.Errorf("BytesPerPixelDepth(%v, %d) = %d, want %d", tt.colorType, tt.bitDepth, got, tt.expect)
+			}
+		})
+	}
+}
+
 func TestScanlineLength(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -285,3 +315,128 @@ func TestScanlineBytes_consistency(t *testing.T) {
 		})
 	}
 }
+
+func TestScanlineLengthLargeWidth(t *testing.T) {
+	// (2^31-1) * 4 overflows a 32-bit product but fits in 64 bits.
+	width := 1<<31 - 1
+	want := int64(1) + int64(width)*4
+	if got := ScanlineLength(width, ColorRGBA); int64(got) != want {
+		t.Errorf("ScanlineLength(%d, RGBA) = %d, want %d", width, got, want)
+	}
+
+	if got := ScanlineLength(math.MaxInt/2, ColorRGBA); got != math.MaxInt {
+		t.Errorf("ScanlineLength(MaxInt/2, RGBA) = %d, want saturation at %d", got, math.MaxInt)
+	}
+}
+
+func TestBuildScanlines(t *testing.T) {
+	width, height := 16, 8
+	pixels := make([]byte, width*height*3)
+	for i := range pixels {
+		pixels[i] = byte(i*7 + i/48*13)
+	}
+
+	for _, strategy := range []FilterStrategy{FilterStrategyNone, FilterStrategyPaeth, FilterStrategyAdaptive} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			data, filters, err := BuildScanlines(pixels, width, height, ColorRGB, strategy)
+			if err != nil {
+				t.Fatalf("BuildScanlines() error = %v", err)
+			}
+			if len(filters) != height {
+				t.Fatalf("BuildScanlines() returned %d filters, want %d", len(filters), height)
+			}
+			rowLen := 1 + width*3
+			for y, f := range filters {
+				if FilterType(data[y*rowLen]) != f {
+					t.Errorf("row %d filter byte = %v, want %v", y, FilterType(data[y*rowLen]), f)
+				}
+			}
+			if got := unfilterScanlines(t, data, width, height, 3); !bytes.Equal(got, pixels) {
+				t.Error("unfiltered scanlines do not match the input pixels")
+			}
+
+			// IDAT holds exactly these scanlines, zlib-compressed.
+			opts := BalancedOptions(width, height)
+			opts.ColorType = ColorRGB
+			opts.FilterStrategy = strategy
+			idat, err := IDATDataBytesWithOptions(pixels, width, height, ColorRGB, opts)
+			if err != nil {
+				t.Fatalf("IDATDataBytesWithOptions() error = %v", err)
+			}
+			r, err := zlib.NewReader(bytes.NewReader(idat))
+			if err != nil {
+				t.Fatalf("zlib.NewReader() error = %v", err)
+			}
+			inflated, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("inflating IDAT: %v", err)
+			}
+			if !bytes.Equal(inflated, data) {
+				t.Error("BuildScanlines() output differs from the inflated IDAT data")
+			}
+		})
+	}
+}
+
+func TestBuildScanlinesWithStride(t *testing.T) {
+	width, height, stride := 4, 3, 16
+	tight := benchPixels(width, height, 3)
+	padded := make([]byte, stride*(height-1)+width*3)
+	for i := range padded {
+		padded[i] = 0xEE
+	}
+	for y := 0; y < height; y++ {
+		copy(padded[y*stride:], tight[y*width*3:(y+1)*width*3])
+	}
+
+	want, wantFilters, err := BuildScanlines(tight, width, height, ColorRGB, FilterStrategyAdaptive)
+	if err != nil {
+		t.Fatalf("BuildScanlines() error = %v", err)
+	}
+	got, gotFilters, err := BuildScanlinesWithStride(padded, width, height, stride, ColorRGB, FilterStrategyAdaptive)
+	if err != nil {
+		t.Fatalf("BuildScanlinesWithStride() error = %v", err)
+	}
+	if !bytes.Equal(got, want) || !reflect.DeepEqual(gotFilters, wantFilters) {
+		t.Error("BuildScanlinesWithStride() differs from BuildScanlines() on the packed rows")
+	}
+
+	opts := FastOptions(width, height)
+	opts.ColorType = ColorRGB
+	wantIDAT, err := IDATDataBytesWithOptions(tight, width, height, ColorRGB, opts)
+	if err != nil {
+		t.Fatalf("IDATDataBytesWithOptions() error = %v", err)
+	}
+	opts.RowStride = stride
+	gotIDAT, err := IDATDataBytesWithOptions(padded, width, height, ColorRGB, opts)
+	if err != nil {
+		t.Fatalf("IDATDataBytesWithOptions() with RowStride error = %v", err)
+	}
+	if !bytes.Equal(gotIDAT, wantIDAT) {
+		t.Error("IDATDataBytesWithOptions() with RowStride differs from the packed encoding")
+	}
+
+	bad := []struct {
+		name   string
+		pixels []byte
+		stride int
+	}{
+		{"stride shorter than a row", padded, width*3 - 1},
+		{"short last row", padded[:len(padded)-1], stride},
+		{"stride overflows the buffer size", padded, math.MaxInt/2 + 1},
+	}
+	for _, tc := range bad {
+		if _, _, err := BuildScanlinesWithStride(tc.pixels, width, height, tc.stride, ColorRGB, FilterStrategyNone); err == nil {
+			t.Errorf("BuildScanlinesWithStride() with %s error = nil, want error", tc.name)
+		}
+	}
+}
+
+func TestBuildScanlinesErrors(t *testing.T) {
+	if _, _, err := BuildScanlines(nil, 0, 1, ColorRGB, FilterStrategyNone); !errors.Is(err, ErrInvalidDimensions) {
+		t.Errorf("BuildScanlines() with zero width error = %v, want %v", err, ErrInvalidDimensions)
+	}
+	if _, _, err := BuildScanlines(make([]byte, 5), 2, 1, ColorRGB, FilterStrategyNone); err == nil {
+		t.Error("BuildScanlines() with short pixel data error = nil, want error")
+	}
+}
@@ -1,6 +1,9 @@
 package png
 
-import "bytes"
+import (
+	"bytes"
+	"io"
+)
 
 func IsValidSignature(data []byte) bool {
 	if len(data) < 8 {
@@ -12,3 +15,13 @@ func IsValidSignature(data []byte) bool {
 func Signature() []byte {
 	return PNG_SIGNATURE[:]
 }
+
+// readSignature reads the 8-byte PNG signature from r, returning
+// ErrInvalidSignature if it is missing or wrong.
+func readSignature(r io.Reader) error {
+	var sig [8]byte
+	if _, err := io.ReadFull(r, sig[:]); err != nil || !IsValidSignature(sig[:]) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package png
+
+// SwapRB returns a copy of pixels with the first and third channel of every
+// pixel exchanged, converting BGR(A) to RGB(A) and back. Other color types are
+// returned as an unchanged copy.
+func SwapRB(pixels []byte, colorType ColorType) []byte {
+	result := append([]byte(nil), pixels...)
+	if colorType != ColorRGB && colorType != ColorRGBA {
+		return result
+	}
+
+	bpp := BytesPerPixel(colorType)
+	for i := 0; i+bpp <= len(result); i += bpp {
+		result[i], result[i+2] = result[i+2], result[i]
+	}
+	return result
+}
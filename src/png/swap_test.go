@@ -0,0 +1,42 @@
+package png
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSwapRB(t *testing.T) {
+	tests := []struct {
+		name      string
+		pixels    []byte
+		colorType ColorType
+		want      []byte
+	}{
+		{"BGRA", []byte{1, 2, 3, 4, 5, 6, 7, 8}, ColorRGBA, []byte{3, 2, 1, 4, 7, 6, 5, 8}},
+		{"BGR", []byte{1, 2, 3, 4, 5, 6}, ColorRGB, []byte{3, 2, 1, 6, 5, 4}},
+		{"grayscale unchanged", []byte{1, 2, 3}, ColorGrayscale, []byte{1, 2, 3}},
+		{"empty", []byte{}, ColorRGBA, []byte{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SwapRB(tt.pixels, tt.colorType)
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("SwapRB() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeInputIsBGRA(t *testing.T) {
+	// Red then semi-transparent blue, in BGRA order.
+	pixels := []byte{0, 0, 255, 255, 255, 0, 0, 128}
+	opts := FastOptions(2, 1)
+	opts.InputIsBGRA = true
+
+	data, err := EncodeWithOptions(pixels, opts)
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error = %v", err)
+	}
+	assertDecodedPixels(t, data, 2, 1, ColorRGBA, []byte{255, 0, 0, 255, 0, 0, 255, 128})
+}
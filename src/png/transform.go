@@ -0,0 +1,47 @@
+package png
+
+// sharpenRadius is the box blur radius Options.Sharpen uses for its unsharp mask.
+const sharpenRadius = 1
+
+// hasTransforms reports whether applyTransforms would change any pixels.
+func (o Options) hasTransforms() bool {
+	return o.InputIsBGRA || o.FlattenBackground != nil || o.PreBlurRadius > 0 ||
+		o.Sharpen > 0 || o.Equalize || o.Posterize > 0
+}
+
+// applyTransforms runs the optional pixel transforms configured in opts, in order,
+// and returns the resulting pixels and color type. pixels itself is never modified.
+func applyTransforms(pixels []byte, colorType ColorType, opts Options) ([]byte, ColorType) {
+	// Channel Order - BGR(A) input to RGB(A)
+	if opts.InputIsBGRA {
+		pixels = SwapRB(pixels, colorType)
+	}
+
+	// Alpha Flattening - composite over a solid background
+	if opts.FlattenBackground != nil && colorType == ColorRGBA {
+		pixels = FlattenAlpha(pixels, opts.Width, opts.Height, *opts.FlattenBackground)
+		colorType = ColorRGB
+	}
+
+	// Box Blur - smooth sensor noise so quantization picks cleaner colors
+	if opts.PreBlurRadius > 0 {
+		pixels = BoxBlur(pixels, opts.Width, opts.Height, colorType, opts.PreBlurRadius)
+	}
+
+	// Unsharp Mask - restore edge contrast, e.g. for downscaled thumbnails
+	if opts.Sharpen > 0 {
+		pixels = UnsharpMask(pixels, opts.Width, opts.Height, colorType, opts.Sharpen, sharpenRadius)
+	}
+
+	// Histogram Equalization - stretch contrast (luma only for color)
+	if opts.Equalize {
+		pixels = EqualizeLuminance(pixels, colorType)
+	}
+
+	// Posterize - fewer levels per channel
+	if opts.Posterize > 0 {
+		pixels = Posterize(pixels, colorType, opts.Posterize)
+	}
+
+	return pixels, colorType
+}
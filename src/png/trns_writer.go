@@ -3,8 +3,6 @@ package png
 import (
 	"encoding/binary"
 	"io"
-
-	"github.com/mac/go-pixo/src/compress"
 )
 
 // WriteTRNS writes alpha values for palette entries.
@@ -23,27 +21,64 @@ func WriteTRNS(w io.Writer, alphaValues []uint8) error {
 		data[i] = a
 	}
 
-	length := uint32(len(data))
-	if err := binary.Write(w, binary.BigEndian, length); err != nil {
-		return err
-	}
+	chunk := Chunk{chunkType: "tRNS", Data: data}
+	_, err := chunk.WriteTo(w)
+	return err
+}
 
-	if err := binary.Write(w, nil, []byte("tRNS")); err != nil {
-		return err
-	}
+// WriteTRNSGray writes the grayscale form of tRNS: a single 2-byte gray level
+// that decoders treat as fully transparent.
+func WriteTRNSGray(w io.Writer, grayLevel uint16) error {
+	data := make([]byte, 2)
+	binary.BigEndian.PutUint16(data, grayLevel)
+	chunk := Chunk{chunkType: "tRNS", Data: data}
+	_, err := chunk.WriteTo(w)
+	return err
+}
 
-	if _, err := w.Write(data); err != nil {
-		return err
-	}
+// WriteTRNSRGB writes the truecolor form of tRNS: one 2-byte sample each for
+// red, green, and blue, naming the color decoders treat as fully transparent.
+func WriteTRNSRGB(w io.Writer, r, g, b uint16) error {
+	data := make([]byte, 6)
+	binary.BigEndian.PutUint16(data[0:2], r)
+	binary.BigEndian.PutUint16(data[2:4], g)
+	binary.BigEndian.PutUint16(data[4:6], b)
+	chunk := Chunk{chunkType: "tRNS", Data: data}
+	_, err := chunk.WriteTo(w)
+	return err
+}
 
-	crc := compress.CRC32(append([]byte("tRNS"), data...))
-	if err := binary.Write(w, binary.BigEndian, crc); err != nil {
-		return err
+// writeColorKey writes the tRNS form matching colorType for key, a color of
+// the inputType pixels before any color-type reduction. Grayscale input uses
+// key.R as the gray level; RGB input reduced to grayscale keeps the key only
+// when R, G and B are equal, since no other color survives the reduction.
+// RGBA input carries its own alpha and gets no color key.
+func writeColorKey(w io.Writer, inputType, colorType ColorType, key Color) error {
+	switch {
+	case inputType == ColorRGBA:
+		return nil
+	case colorType == ColorRGB:
+		return WriteTRNSRGB(w, uint16(key.R), uint16(key.G), uint16(key.B))
+	case colorType == ColorGrayscale && (inputType == ColorGrayscale || key.R == key.G && key.G == key.B):
+		return WriteTRNSGray(w, uint16(key.R))
 	}
-
 	return nil
 }
 
+// OptimizeTRNS drops the trailing fully opaque entries from palette alpha
+// values, which decoders treat as 255 anyway. It returns nil when every entry
+// is opaque, meaning no tRNS chunk is needed. The result shares alpha's storage.
+func OptimizeTRNS(alpha []uint8) []uint8 {
+	n := len(alpha)
+	for n > 0 && alpha[n-1] == 255 {
+		n--
+	}
+	if n == 0 {
+		return nil
+	}
+	return alpha[:n]
+}
+
 // TRNSChunkData returns the raw tRNS chunk data without chunk wrapper.
 func TRNSChunkData(alphaValues []uint8) []byte {
 	if len(alphaValues) == 0 || len(alphaValues) > 256 {
@@ -60,12 +95,16 @@ func TRNSChunkData(alphaValues []uint8) []byte {
 
 // ExtractAlphaFromPixels extracts alpha values from RGBA pixels for palette quantization.
 // Returns slice of alpha values and whether any transparency exists.
+// Palettes produced by QuantizeWithAlpha carry their own alpha; others are opaque.
 func ExtractAlphaFromPixels(pixels []byte, palette Palette) ([]uint8, bool) {
 	alphaValues := make([]uint8, palette.NumColors)
 	hasTransparency := false
 
 	for i := 0; i < palette.NumColors; i++ {
-		alphaValues[i] = 255 // Default to fully opaque
+		alphaValues[i] = palette.GetAlpha(i)
+		if alphaValues[i] != 255 {
+			hasTransparency = true
+		}
 	}
 
 	return alphaValues, hasTransparency
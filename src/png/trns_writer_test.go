@@ -2,7 +2,10 @@ package png
 
 import (
 	"bytes"
+	"encoding/binary"
 	"testing"
+
+	"github.com/mac/go-pixo/src/compress"
 )
 
 func TestWriteTRNS(t *testing.T) {
@@ -241,3 +244,190 @@ func TestWriteTRNSMixedAlpha(t *testing.T) {
 		t.Errorf("WriteTRNS() mixed alpha values incorrect")
 	}
 }
+
+func TestWriteTRNSColorKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		write    func(w *bytes.Buffer) error
+		wantData []byte
+	}{
+		{"gray", func(w *bytes.Buffer) error { return WriteTRNSGray(w, 0x0102) }, []byte{0x01, 0x02}},
+		{"rgb", func(w *bytes.Buffer) error { return WriteTRNSRGB(w, 1, 0x0203, 0xFF) }, []byte{0, 1, 2, 3, 0, 0xFF}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.write(&buf); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+			data := buf.Bytes()
+			if got := binary.BigEndian.Uint32(data[0:4]); got != uint32(len(tt.wantData)) {
+				t.Errorf("length field = %d, want %d", got, len(tt.wantData))
+			}
+			if string(data[4:8]) != "tRNS" {
+				t.Errorf("type = %q, want tRNS", data[4:8])
+			}
+			if got := data[8 : len(data)-4]; !bytes.Equal(got, tt.wantData) {
+				t.Errorf("data = %v, want %v", got, tt.wantData)
+			}
+			crc := binary.BigEndian.Uint32(data[len(data)-4:])
+			if want := compress.CRC32(append([]byte("tRNS"), tt.wantData...)); crc != want {
+				t.Errorf("CRC field = 0x%08x, want 0x%08x", crc, want)
+			}
+		})
+	}
+}
+
+func TestEncodeTransparentColor(t *testing.T) {
+	key := Color{10, 20, 30}
+	rgb := []byte{10, 20, 30, 1, 2, 3, 10, 20, 30, 40, 50, 60}
+	want := []byte{10, 20, 30, 0, 1, 2, 3, 255, 10, 20, 30, 0, 40, 50, 60, 255}
+
+	opts := FastOptions(2, 2)
+	opts.ColorType = ColorRGB
+	opts.TransparentColor = &key
+	encoder, err := NewEncoderWithOptions(opts)
+	if err != nil {
+		t.Fatalf("NewEncoderWithOptions() error = %v", err)
+	}
+	data, err := encoder.Encode(rgb)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if trns := findFirstChunk(t, parsePNGChunks(t, data), "tRNS"); len(trns.Data) != 6 {
+		t.Errorf("tRNS length = %d, want 6", len(trns.Data))
+	}
+	assertDecodedPixels(t, data, 2, 2, ColorRGBA, want)
+
+	gray := []byte{7, 8, 7, 9}
+	opts.ColorType = ColorGrayscale
+	opts.TransparentColor = &Color{R: 7}
+	if encoder, err = NewEncoderWithOptions(opts); err != nil {
+		t.Fatalf("NewEncoderWithOptions() error = %v", err)
+	}
+	if data, err = encoder.Encode(gray); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if trns := findFirstChunk(t, parsePNGChunks(t, data), "tRNS"); len(trns.Data) != 2 {
+		t.Errorf("tRNS length = %d, want 2", len(trns.Data))
+	}
+	want = []byte{7, 7, 7, 0, 8, 8, 8, 255, 7, 7, 7, 0, 9, 9, 9, 255}
+	assertDecodedPixels(t, data, 2, 2, ColorRGBA, want)
+}
+
+func TestEncodeTransparentColorReduced(t *testing.T) {
+	grayRGB := []byte{10, 10, 10, 20, 20, 20, 10, 10, 10, 30, 30, 30}
+	opaqueRGBA := []byte{10, 20, 30, 255, 1, 2, 3, 255, 10, 20, 30, 255, 4, 5, 6, 255}
+	tests := []struct {
+		name      string
+		colorType ColorType
+		pixels    []byte
+		key       Color
+		want      []byte // decoded RGBA
+		wantTRNS  int    // tRNS data length, 0 for none
+	}{
+		{
+			name: "gray key on RGB reduced to grayscale", colorType: ColorRGB, pixels: grayRGB,
+			key:      Color{10, 10, 10},
+			want:     []byte{10, 10, 10, 0, 20, 20, 20, 255, 10, 10, 10, 0, 30, 30, 30, 255},
+			wantTRNS: 2,
+		},
+		{
+			name: "color key on RGB reduced to grayscale", colorType: ColorRGB, pixels: grayRGB,
+			key:  Color{10, 20, 30},
+			want: []byte{10, 10, 10, 255, 20, 20, 20, 255, 10, 10, 10, 255, 30, 30, 30, 255},
+		},
+		{
+			name: "opaque RGBA reduced to RGB", colorType: ColorRGBA, pixels: opaqueRGBA,
+			key:  Color{10, 20, 30},
+			want: opaqueRGBA,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := BalancedOptions(2, 2)
+			opts.ColorType = tt.colorType
+			opts.TransparentColor = &tt.key
+			data, err := EncodeWithOptions(tt.pixels, opts)
+			if err != nil {
+				t.Fatalf("EncodeWithOptions() error = %v", err)
+			}
+			var trnsLen int
+			for _, c := range parsePNGChunks(t, data) {
+				if c.Type == "tRNS" {
+					trnsLen = len(c.Data)
+				}
+			}
+			if trnsLen != tt.wantTRNS {
+				t.Errorf("tRNS length = %d, want %d", trnsLen, tt.wantTRNS)
+			}
+			assertDecodedPixels(t, data, 2, 2, ColorRGBA, tt.want)
+		})
+	}
+}
+
+func TestOptimizeTRNS(t *testing.T) {
+	tests := []struct {
+		name  string
+		alpha []uint8
+		want  []uint8
+	}{
+		{"empty", nil, nil},
+		{"all opaque", []uint8{255, 255, 255}, nil},
+		{"mixed", []uint8{0, 128, 255, 255}, []uint8{0, 128}},
+		{"opaque between", []uint8{0, 255, 10, 255}, []uint8{0, 255, 10}},
+		{"leading opaque only", []uint8{255, 255, 0}, []uint8{255, 255, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OptimizeTRNS(tt.alpha)
+			if !bytes.Equal(got, tt.want) || (got == nil) != (tt.want == nil) {
+				t.Errorf("OptimizeTRNS() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeIndexedTrimsTRNS(t *testing.T) {
+	tests := []struct {
+		name    string
+		alpha   []uint8
+		wantLen int // -1 for no tRNS chunk
+	}{
+		{"all opaque", []uint8{255, 255, 255, 255}, -1},
+		{"trailing opaque", []uint8{0, 128, 255, 255}, 2},
+		{"leading opaque only", []uint8{255, 255, 255, 0}, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			palette := NewPalette(4)
+			for i, a := range tt.alpha {
+				palette.AddColorWithAlpha(Color{uint8(i * 60), 0, 0}, a)
+			}
+			indexed := []byte{0, 1, 2, 3}
+			data, err := EncodeIndexed(indexed, 2, 2, *palette, FastOptions(2, 2))
+			if err != nil {
+				t.Fatalf("EncodeIndexed() error = %v", err)
+			}
+
+			gotLen := -1
+			for _, c := range parsePNGChunks(t, data) {
+				if c.Type == "tRNS" {
+					gotLen = len(c.Data)
+				}
+			}
+			if gotLen != tt.wantLen {
+				t.Errorf("tRNS length = %d, want %d", gotLen, tt.wantLen)
+			}
+
+			want := make([]byte, 0, 16)
+			for i, a := range tt.alpha {
+				want = append(want, uint8(i*60), 0, 0, a)
+			}
+			assertDecodedPixels(t, data, 2, 2, ColorRGBA, want)
+		})
+	}
+}
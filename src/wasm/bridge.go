@@ -5,8 +5,6 @@ package wasm
 import (
 	"fmt"
 	"syscall/js"
-
-	"github.com/mac/go-pixo/src/png"
 )
 
 /**
@@ -43,6 +41,62 @@ func HandleEncodePng(this js.Value, args []js.Value) any {
 	return dst
 }
 
+/**
+ * HandleEncodePngWithOptions converts JS arguments to Go and calls EncodePngWithOptions.
+ * Expected arguments: (pixels: Uint8Array, width: number, height: number, colorType: number, options?: object)
+ * options may hold level, filterStrategy, optimizeAlpha, reduceColorType and maxColors;
+ * when it is omitted the Balanced preset is used unchanged.
+ */
+func HandleEncodePngWithOptions(this js.Value, args []js.Value) any {
+	if len(args) < 4 {
+		return js.ValueOf("invalid arguments")
+	}
+
+	pixelsJS := args[0]
+	width := args[1].Int()
+	height := args[2].Int()
+	colorType := args[3].Int()
+
+	var values map[string]any
+	if len(args) > 4 && args[4].Type() == js.TypeObject {
+		values = jsObjectToMap(args[4])
+	}
+
+	pixels := make([]byte, pixelsJS.Get("length").Int())
+	js.CopyBytesToGo(pixels, pixelsJS)
+
+	output, err := EncodePngWithOptions(pixels, width, height, colorType, values)
+	if err != nil {
+		return js.ValueOf(fmt.Sprintf("error: %v", err))
+	}
+
+	dst := js.Global().Get("Uint8Array").New(len(output))
+	js.CopyBytesToJS(dst, output)
+
+	return dst
+}
+
+/**
+ * jsObjectToMap copies the number, string and boolean properties of a JS object.
+ */
+func jsObjectToMap(obj js.Value) map[string]any {
+	values := make(map[string]any)
+	keys := js.Global().Get("Object").Call("keys", obj)
+	for i := 0; i < keys.Length(); i++ {
+		key := keys.Index(i).String()
+		v := obj.Get(key)
+		switch v.Type() {
+		case js.TypeNumber:
+			values[key] = v.Float()
+		case js.TypeString:
+			values[key] = v.String()
+		case js.TypeBoolean:
+			values[key] = v.Bool()
+		}
+	}
+	return values
+}
+
 /**
  * HandleBytesPerPixel returns the bytes per pixel for a given color type.
  * Expected arguments: (colorType: number)
@@ -56,85 +110,40 @@ func HandleBytesPerPixel(this js.Value, args []js.Value) any {
 }
 
 /**
- * HandleQuantizeInfo returns quantization capabilities.
- * No arguments required.
- */
-func HandleQuantizeInfo(this js.Value, args []js.Value) any {
-	return js.ValueOf(map[string]interface{}{
-		"maxColors":          256,
-		"ditheringSupported": true,
-		"minColors":          2,
-	})
-}
-
-/**
- * EncodePng encodes pixels as a PNG image using the go-pixo PNG encoder.
- * Returns PNG file bytes ready to be written to a file or used in a browser.
+ * HandleEstimateSize returns {estimate, upperBound} in bytes for encoding an image.
+ * upperBound is safe for sizing a buffer; estimate is only a guide for the UI.
+ * Expected arguments: (width: number, height: number, colorType: number,
+ * level: number, maxIDATChunkSize?: number), where a missing or zero
+ * maxIDATChunkSize means a single IDAT chunk.
  */
-func EncodePng(pixels []byte, width, height int, colorType, preset int, lossy bool, maxColors int) ([]byte, error) {
-	var pngColorType png.ColorType
-	switch colorType {
-	case 0:
-		pngColorType = png.ColorGrayscale
-	case 2:
-		pngColorType = png.ColorRGB
-	case 6:
-		pngColorType = png.ColorRGBA
-	default:
-		return nil, fmt.Errorf("unsupported color type: %d", colorType)
-	}
-
-	// Map ReScript presets to Go presets
-	// ReScript: Smaller=0, Balanced=1, Faster=2
-	// Go: PresetFast=0, PresetBalanced=1, PresetMax=2
-	var opts png.Options
-	switch preset {
-	case 0: // Smaller
-		opts = png.MaxOptions(width, height)
-	case 1: // Balanced
-		opts = png.BalancedOptions(width, height)
-	case 2: // Faster
-		opts = png.FastOptions(width, height)
-	default:
-		opts = png.BalancedOptions(width, height)
-	}
-	opts.ColorType = pngColorType
-
-	// Apply lossy quantization if enabled
-	if lossy && maxColors > 0 && maxColors <= 256 {
-		opts.MaxColors = maxColors
-		opts.Dithering = false
-		opts.ColorType = png.ColorIndexed
+func HandleEstimateSize(this js.Value, args []js.Value) any {
+	if len(args) < 4 {
+		return js.ValueOf("invalid arguments")
 	}
-
-	encoder, err := png.NewEncoderWithOptions(opts)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create encoder: %w", err)
+	maxIDATChunkSize := 0
+	if len(args) > 4 && args[4].Type() == js.TypeNumber {
+		maxIDATChunkSize = args[4].Int()
 	}
 
-	pngBytes, err := encoder.Encode(pixels)
+	estimate, upperBound, err := EstimatePngSize(args[0].Int(), args[1].Int(), args[2].Int(), args[3].Int(), maxIDATChunkSize)
 	if err != nil {
-		return nil, fmt.Errorf("failed to encode PNG: %w", err)
+		return js.ValueOf(fmt.Sprintf("error: %v", err))
 	}
 
-	return pngBytes, nil
+	return js.ValueOf(map[string]interface{}{
+		"estimate":   estimate,
+		"upperBound": upperBound,
+	})
 }
 
 /**
- * BytesPerPixel returns bytes per pixel based on color type.
- * 0 = Grayscale (1), 2 = RGB (3), 6 = RGBA (4), 3 = Indexed (1)
+ * HandleQuantizeInfo returns quantization capabilities.
+ * No arguments required.
  */
-func BytesPerPixel(colorType int) int {
-	switch colorType {
-	case 0: // Grayscale
-		return 1
-	case 2: // RGB
-		return 3
-	case 3: // Indexed
-		return 1
-	case 6: // RGBA
-		return 4
-	default:
-		return 4
-	}
+func HandleQuantizeInfo(this js.Value, args []js.Value) any {
+	return js.ValueOf(map[string]interface{}{
+		"maxColors":          256,
+		"ditheringSupported": true,
+		"minColors":          2,
+	})
 }
@@ -0,0 +1,99 @@
+package wasm
+
+import (
+	"fmt"
+
+	"github.com/mac/go-pixo/src/png"
+)
+
+/**
+ * EncodePng encodes pixels as a PNG image using the go-pixo PNG encoder.
+ * Returns PNG file bytes ready to be written to a file or used in a browser.
+ */
+func EncodePng(pixels []byte, width, height int, colorType, preset int, lossy bool, maxColors int) ([]byte, error) {
+	pngColorType, err := toPNGColorType(colorType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Map ReScript presets to Go presets
+	// ReScript: Smaller=0, Balanced=1, Faster=2
+	// Go: PresetFast=0, PresetBalanced=1, PresetMax=2
+	var opts png.Options
+	switch preset {
+	case 0: // Smaller
+		opts = png.MaxOptions(width, height)
+	case 1: // Balanced
+		opts = png.BalancedOptions(width, height)
+	case 2: // Faster
+		opts = png.FastOptions(width, height)
+	default:
+		opts = png.BalancedOptions(width, height)
+	}
+	opts.ColorType = pngColorType
+
+	// Apply lossy quantization if enabled
+	if lossy && maxColors > 0 && maxColors <= 256 {
+		opts.MaxColors = maxColors
+		opts.Dithering = false
+		opts.ReduceColorType = false
+	}
+
+	encoder, err := png.NewEncoderWithOptions(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create encoder: %w", err)
+	}
+
+	pngBytes, err := encoder.Encode(pixels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+
+	return pngBytes, nil
+}
+
+/**
+ * EncodePngWithOptions encodes pixels starting from the Balanced preset with
+ * values (see ApplyEncodeOptions) applied on top.
+ */
+func EncodePngWithOptions(pixels []byte, width, height, colorType int, values map[string]any) ([]byte, error) {
+	pngColorType, err := toPNGColorType(colorType)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := png.BalancedOptions(width, height)
+	opts.ColorType = pngColorType
+	opts = ApplyEncodeOptions(opts, values)
+
+	encoder, err := png.NewEncoderWithOptions(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create encoder: %w", err)
+	}
+
+	pngBytes, err := encoder.Encode(pixels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+
+	return pngBytes, nil
+}
+
+/**
+ * BytesPerPixel returns bytes per pixel based on color type.
+ * 0 = Grayscale (1), 2 = RGB (3), 6 = RGBA (4), 3 = Indexed (1)
+ */
+func BytesPerPixel(colorType int) int {
+	switch colorType {
+	case 0: // Grayscale
+		return 1
+	case 2: // RGB
+		return 3
+	case 3: // Indexed
+		return 1
+	case 6: // RGBA
+		return 4
+	default:
+		return 4
+	}
+}
@@ -0,0 +1,70 @@
+package wasm
+
+import (
+	"math"
+
+	"github.com/mac/go-pixo/src/png"
+)
+
+const (
+	// pngFixedOverhead is signature (8) + IHDR chunk (25) + IEND chunk (12)
+	// + one IDAT chunk's length, type and CRC (12). Each further IDAT chunk
+	// from splitting the image data adds another 12.
+	pngFixedOverhead = 8 + 25 + 12 + 12
+	// idatChunkOverhead is the length, type and CRC of one IDAT chunk.
+	idatChunkOverhead = 12
+	// paletteOverhead is the largest PLTE (12+768) and tRNS (12+256) chunks,
+	// which palette output from RGB/RGBA input may add.
+	paletteOverhead = 12 + 768 + 12 + 256
+)
+
+/**
+ * EstimatePngSize returns a rough size estimate and an upper bound, in bytes,
+ * for encoding a width x height image of the given PNG color type at the given
+ * compression level (clamped to 1-9 like the level option of
+ * EncodePngWithOptions), with the image data split into IDAT chunks of at most
+ * maxIDATChunkSize bytes (png.Options.MaxIDATChunkSize; 0 for a single IDAT).
+ * The estimate scales png.ExpectedIDATSize, which assumes ~50% compression, by
+ * levelEstimateScale and can be exceeded; upperBound is safe for preallocating a
+ * buffer because the encoder never writes more than stored (uncompressed)
+ * blocks, whatever the level, and it counts 12 bytes of framing for every IDAT
+ * chunk the split can produce.
+ */
+func EstimatePngSize(width, height, colorType, level, maxIDATChunkSize int) (estimate, upperBound int, err error) {
+	ct, err := toPNGColorType(colorType)
+	if err != nil {
+		return 0, 0, err
+	}
+	if width <= 0 || height <= 0 {
+		return 0, 0, png.ErrInvalidDimensions
+	}
+
+	idatEstimate := levelEstimateScale(png.ExpectedIDATSize(width, height, ct), clampInt(level, 1, 9))
+	idatBound := png.MaxIDATSize(width, height, ct)
+	estimate = pngFixedOverhead + idatEstimate + extraIDATOverhead(idatEstimate, maxIDATChunkSize)
+	upperBound = pngFixedOverhead + idatBound + extraIDATOverhead(idatBound, maxIDATChunkSize)
+	if ct != png.ColorGrayscale {
+		upperBound += paletteOverhead
+	}
+	return estimate, upperBound, nil
+}
+
+// levelEstimateScale adjusts an estimate for level 6 to level by 5% per level,
+// from 125% at level 1 to 85% at level 9, roughly how the encoder's output size
+// moves with the level on the sample images in images/.
+func levelEstimateScale(n, level int) int {
+	if n > math.MaxInt/26 {
+		return n // saturated; far beyond any image that can be encoded
+	}
+	return n * (26 - level) / 20
+}
+
+// extraIDATOverhead returns the framing of the IDAT chunks beyond the first
+// when n bytes of image data are split into chunks of at most maxSize bytes.
+func extraIDATOverhead(n, maxSize int) int {
+	if maxSize <= 0 || n <= maxSize {
+		return 0
+	}
+	chunks := (n + maxSize - 1) / maxSize
+	return (chunks - 1) * idatChunkOverhead
+}
@@ -0,0 +1,115 @@
+package wasm
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/mac/go-pixo/src/png"
+)
+
+func TestEstimatePngSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		width     int
+		height    int
+		colorType int
+	}{
+		{"1x1 RGBA", 1, 1, 6},
+		{"32x32 RGB", 32, 32, 2},
+		{"64x16 grayscale", 64, 16, 0},
+	}
+
+	rng := rand.New(rand.NewSource(3))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			estimate, upperBound, err := EstimatePngSize(tt.width, tt.height, tt.colorType, 6, 0)
+			if err != nil {
+				t.Fatalf("EstimatePngSize() error = %v", err)
+			}
+			if estimate <= pngFixedOverhead || estimate > upperBound {
+				t.Errorf("estimate = %d, want in (%d, %d]", estimate, pngFixedOverhead, upperBound)
+			}
+
+			pixels := make([]byte, tt.width*tt.height*BytesPerPixel(tt.colorType))
+			rng.Read(pixels)
+			for _, preset := range []int{0, 1, 2} {
+				data, err := EncodePng(pixels, tt.width, tt.height, tt.colorType, preset, false, 0)
+				if err != nil {
+					t.Fatalf("EncodePng() error = %v", err)
+				}
+				if len(data) > upperBound {
+					t.Errorf("preset %d: encoded %d bytes, exceeds upper bound %d", preset, len(data), upperBound)
+				}
+			}
+		})
+	}
+}
+
+func TestEstimatePngSizeSplitIDAT(t *testing.T) {
+	width, height, chunkSize := 32, 32, 64
+	pixels := make([]byte, width*height*3)
+	rand.New(rand.NewSource(5)).Read(pixels)
+
+	opts := png.FastOptions(width, height)
+	opts.ColorType = png.ColorRGB
+	opts.MaxIDATChunkSize = chunkSize
+	encoder, err := png.NewEncoderWithOptions(opts)
+	if err != nil {
+		t.Fatalf("NewEncoderWithOptions() error = %v", err)
+	}
+	data, err := encoder.Encode(pixels)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	_, single, err := EstimatePngSize(width, height, 2, 6, 0)
+	if err != nil {
+		t.Fatalf("EstimatePngSize() error = %v", err)
+	}
+	_, split, err := EstimatePngSize(width, height, 2, 6, chunkSize)
+	if err != nil {
+		t.Fatalf("EstimatePngSize() error = %v", err)
+	}
+	idatChunks := (png.MaxIDATSize(width, height, png.ColorRGB) + chunkSize - 1) / chunkSize
+	if want := single + 12*(idatChunks-1); split != want {
+		t.Errorf("split upper bound = %d, want %d (12 bytes for each of %d extra IDATs)", split, want, idatChunks-1)
+	}
+	if len(data) > split {
+		t.Errorf("encoded %d bytes in %d-byte IDATs, exceeds upper bound %d", len(data), chunkSize, split)
+	}
+}
+
+func TestEstimatePngSizeLevel(t *testing.T) {
+	estimates := make(map[int]int)
+	var bound int
+	for _, level := range []int{-3, 1, 6, 9, 42} {
+		estimate, upperBound, err := EstimatePngSize(64, 64, 2, level, 0)
+		if err != nil {
+			t.Fatalf("EstimatePngSize(level %d) error = %v", level, err)
+		}
+		if bound != 0 && upperBound != bound {
+			t.Errorf("level %d: upper bound = %d, want %d at every level", level, upperBound, bound)
+		}
+		bound = upperBound
+		estimates[level] = estimate
+	}
+
+	if want := pngFixedOverhead + png.ExpectedIDATSize(64, 64, png.ColorRGB); estimates[6] != want {
+		t.Errorf("level 6 estimate = %d, want the unscaled %d", estimates[6], want)
+	}
+	if !(estimates[1] > estimates[6] && estimates[6] > estimates[9]) {
+		t.Errorf("estimates at levels 1, 6, 9 = %d, %d, %d, want decreasing", estimates[1], estimates[6], estimates[9])
+	}
+	if estimates[-3] != estimates[1] || estimates[42] != estimates[9] {
+		t.Errorf("out-of-range levels = %d, %d, want clamped to %d, %d", estimates[-3], estimates[42], estimates[1], estimates[9])
+	}
+}
+
+func TestEstimatePngSizeInvalid(t *testing.T) {
+	if _, _, err := EstimatePngSize(10, 10, 3, 6, 0); err == nil {
+		t.Error("EstimatePngSize() with indexed color type: error = nil, want error")
+	}
+	if _, _, err := EstimatePngSize(0, 10, 6, 6, 0); err != png.ErrInvalidDimensions {
+		t.Errorf("EstimatePngSize() with zero width: error = %v, want %v", err, png.ErrInvalidDimensions)
+	}
+}
@@ -0,0 +1,84 @@
+package wasm
+
+import (
+	"fmt"
+
+	"github.com/mac/go-pixo/src/png"
+)
+
+/**
+ * ApplyEncodeOptions maps a JS options object, decoded into a map, onto opts.
+ * Recognized keys: level (number), filterStrategy (string, e.g. "paeth"),
+ * optimizeAlpha (boolean), reduceColorType (boolean), maxColors (number).
+ * Unknown keys and values of the wrong type are ignored; out-of-range numbers
+ * are clamped like png.OptionsBuilder does (level 1-9, maxColors 0-256).
+ * A nonzero maxColors selects a palette, so it turns off ReduceColorType and AutoPalette.
+ */
+func ApplyEncodeOptions(opts png.Options, values map[string]any) png.Options {
+	if level, ok := numberValue(values["level"]); ok {
+		opts.CompressionLevel = clampInt(level, 1, 9)
+	}
+
+	if name, ok := values["filterStrategy"].(string); ok {
+		var strategy png.FilterStrategy
+		if err := strategy.UnmarshalText([]byte(name)); err == nil {
+			opts.FilterStrategy = strategy
+		}
+	}
+
+	if enabled, ok := values["optimizeAlpha"].(bool); ok {
+		opts.OptimizeAlpha = enabled
+	}
+
+	if enabled, ok := values["reduceColorType"].(bool); ok {
+		opts.ReduceColorType = enabled
+	}
+
+	if maxColors, ok := numberValue(values["maxColors"]); ok {
+		opts.MaxColors = clampInt(maxColors, 0, 256)
+		if opts.MaxColors > 0 {
+			opts.ReduceColorType = false
+			opts.AutoPalette = false
+		}
+	}
+
+	return opts
+}
+
+/**
+ * toPNGColorType maps a PNG color type number to a supported input color type.
+ */
+func toPNGColorType(colorType int) (png.ColorType, error) {
+	switch colorType {
+	case 0:
+		return png.ColorGrayscale, nil
+	case 2:
+		return png.ColorRGB, nil
+	case 6:
+		return png.ColorRGBA, nil
+	default:
+		return 0, fmt.Errorf("unsupported color type: %d", colorType)
+	}
+}
+
+// numberValue converts a JS number (float64 from syscall/js) or Go int to int.
+func numberValue(v any) (int, bool) {
+	switch n := v.(type) {
+	case float64:
+		return int(n), true
+	case int:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
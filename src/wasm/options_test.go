@@ -0,0 +1,54 @@
+package wasm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mac/go-pixo/src/png"
+)
+
+func TestApplyEncodeOptions(t *testing.T) {
+	base := png.BalancedOptions(4, 4)
+
+	tests := []struct {
+		name   string
+		values map[string]any
+		want   func(o *png.Options)
+	}{
+		{"nil map", nil, func(o *png.Options) {}},
+		{"unknown keys ignored", map[string]any{"quality": 80.0, "dither": true}, func(o *png.Options) {}},
+		{"level", map[string]any{"level": 9.0}, func(o *png.Options) { o.CompressionLevel = 9 }},
+		{"level clamped high", map[string]any{"level": 42.0}, func(o *png.Options) { o.CompressionLevel = 9 }},
+		{"level clamped low", map[string]any{"level": -3.0}, func(o *png.Options) { o.CompressionLevel = 1 }},
+		{"level wrong type", map[string]any{"level": "9"}, func(o *png.Options) {}},
+		{"filter strategy", map[string]any{"filterStrategy": "Paeth"}, func(o *png.Options) { o.FilterStrategy = png.FilterStrategyPaeth }},
+		{"unknown filter strategy", map[string]any{"filterStrategy": "best"}, func(o *png.Options) {}},
+		{"flags", map[string]any{"optimizeAlpha": false, "reduceColorType": false}, func(o *png.Options) {
+			o.OptimizeAlpha = false
+			o.ReduceColorType = false
+		}},
+		{"maxColors quantizes", map[string]any{"maxColors": 16.0}, func(o *png.Options) {
+			o.MaxColors = 16
+			o.ReduceColorType = false
+		}},
+		{"maxColors clamped", map[string]any{"maxColors": 1000.0}, func(o *png.Options) {
+			o.MaxColors = 256
+			o.ReduceColorType = false
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := base
+			tt.want(&want)
+
+			got := ApplyEncodeOptions(base, tt.values)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ApplyEncodeOptions() = %+v, want %+v", got, want)
+			}
+			if err := got.Validate(); err != nil {
+				t.Errorf("mapped options are invalid: %v", err)
+			}
+		})
+	}
+}
@@ -1,15 +1,27 @@
 // WASM Bridge for Go-Pixo
 
+export interface EncodeOptions {
+  level?: number;
+  filterStrategy?: string;
+  optimizeAlpha?: boolean;
+  reduceColorType?: boolean;
+  maxColors?: number;
+}
+
 export interface GoWasmInstance {
   encodePng(pixels: Uint8Array, width: number, height: number, colorType: number, preset: number, lossy: boolean): Uint8Array | string;
+  encodePngWithOptions(pixels: Uint8Array, width: number, height: number, colorType: number, options?: EncodeOptions): Uint8Array | string;
   bytesPerPixel(colorType: number): number;
+  estimatePngSize(width: number, height: number, colorType: number, level: number, maxIDATChunkSize?: number): { estimate: number; upperBound: number } | string;
 }
 
 declare global {
   interface Window {
     Go: any;
     encodePng: GoWasmInstance['encodePng'];
+    encodePngWithOptions: GoWasmInstance['encodePngWithOptions'];
     bytesPerPixel: GoWasmInstance['bytesPerPixel'];
+    estimatePngSize: GoWasmInstance['estimatePngSize'];
     goWasmInit: () => void;
   }
 }